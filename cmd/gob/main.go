@@ -6,6 +6,7 @@ import (
 	"slices"
 
 	"github.com/kcmvp/xql/cmd/gob/dev"
+	"github.com/kcmvp/xql/cmd/gob/openapi"
 	"github.com/kcmvp/xql/cmd/gob/sca"
 	"github.com/kcmvp/xql/cmd/gob/xql"
 	"github.com/kcmvp/xql/cmd/internal"
@@ -44,6 +45,7 @@ func init() {
 	rootCmd.AddCommand(xql.XqlCmd)
 	rootCmd.AddCommand(sca.ScaCmd)
 	rootCmd.AddCommand(dev.DevCmd)
+	rootCmd.AddCommand(openapi.OpenapiCmd)
 }
 
 func main() {
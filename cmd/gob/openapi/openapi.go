@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kcmvp/xql/view/openapi"
+	"github.com/spf13/cobra"
+	"github.com/tidwall/gjson"
+)
+
+// OpenapiCmd represents the openapi command group
+var OpenapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Commands for OpenAPI documents of the routes bound with vom.Bind.",
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export [package]",
+	Short: "Export the OpenAPI document of a program (default: the main package in the current directory).",
+	Long: `Export builds and starts the main package with ` + openapi.ExportEnv + ` set, waits until
+openapi.Handler() writes the document, then stops the program. The program must register
+the document route with openapi.Handler() after its other routes.
+
+With --url the document is fetched from a running server instead.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out, _ := cmd.Flags().GetString("output")
+		url, _ := cmd.Flags().GetString("url")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+		defer cancel()
+		var data []byte
+		var err error
+		if url != "" {
+			data, err = fetch(ctx, url)
+		} else {
+			data, err = capture(ctx, append(args, ".")[0])
+		}
+		if err != nil {
+			return err
+		}
+		if err = write(out, data); err != nil {
+			return err
+		}
+		color.Green("OpenAPI document written to %s", out)
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringP("output", "o", "openapi.json", "output file")
+	exportCmd.Flags().String("url", "", "fetch the document from a running server, e.g. http://localhost:8080/openapi.json")
+	exportCmd.Flags().Duration("timeout", time.Minute, "time to wait for the document")
+	OpenapiCmd.AddCommand(exportCmd)
+}
+
+// fetch downloads the document served by openapi.Handler().
+func fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", url, res.Status)
+	}
+	return io.ReadAll(res.Body)
+}
+
+// capture builds and runs the main package pkg until it exports the document.
+func capture(ctx context.Context, pkg string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "gob-openapi")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	bin := filepath.Join(dir, "app")
+	build := exec.CommandContext(ctx, "go", "build", "-o", bin, pkg)
+	if output, err := build.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("build %s: %w\n%s", pkg, err, output)
+	}
+
+	doc := filepath.Join(dir, "openapi.json")
+	var output bytes.Buffer
+	run := exec.Command(bin)
+	run.Env = append(os.Environ(), openapi.ExportEnv+"="+doc)
+	run.Stdout, run.Stderr = &output, &output
+	if err := run.Start(); err != nil {
+		return nil, fmt.Errorf("start %s: %w", pkg, err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- run.Wait() }()
+	running := true
+	defer func() {
+		if running {
+			_ = run.Process.Kill()
+			<-exited
+		}
+	}()
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		if data, err := os.ReadFile(doc); err == nil {
+			return data, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		select {
+		case err := <-exited:
+			running = false
+			if data, rerr := os.ReadFile(doc); rerr == nil {
+				return data, nil
+			}
+			return nil, fmt.Errorf("%s exited before exporting the document (%v); does it call openapi.Handler()?\n%s", pkg, err, output.String())
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%s did not export the document in time; does it call openapi.Handler()?", pkg)
+		case <-ticker.C:
+		}
+	}
+}
+
+// write checks that data is an OpenAPI document and writes it to out.
+func write(out string, data []byte) error {
+	if !gjson.ValidBytes(data) || !gjson.GetBytes(data, "openapi").Exists() {
+		return fmt.Errorf("response is not an OpenAPI document")
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, data, "", "  "); err != nil {
+		return err
+	}
+	if dir := filepath.Dir(out); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(out, pretty.Bytes(), 0o644)
+}
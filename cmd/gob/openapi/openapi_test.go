@@ -0,0 +1,58 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openapi.json":
+			_, _ = w.Write([]byte(`{"openapi":"3.1.0","paths":{}}`))
+		case "/other":
+			_, _ = w.Write([]byte(`{"name":"x"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	ctx := context.Background()
+	out := filepath.Join(t.TempDir(), "api", "openapi.json")
+
+	data, err := fetch(ctx, srv.URL+"/openapi.json")
+	require.NoError(t, err)
+	require.NoError(t, write(out, data))
+	written, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"openapi":"3.1.0","paths":{}}`, string(written))
+
+	data, err = fetch(ctx, srv.URL+"/other")
+	require.NoError(t, err)
+	require.Error(t, write(out, data))
+
+	_, err = fetch(ctx, srv.URL+"/missing")
+	require.ErrorContains(t, err, "404")
+}
+
+func TestCapture(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a program")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	data, err := capture(ctx, "./testdata/app")
+	require.NoError(t, err)
+	require.True(t, gjson.GetBytes(data, `paths./orders/{id}.post.requestBody`).Exists())
+
+	_, err = capture(ctx, "./testdata/missing")
+	require.ErrorContains(t, err, "build")
+}
@@ -0,0 +1,16 @@
+// Command app registers a route and blocks like a server, for the export tests.
+package main
+
+import (
+	"net/http"
+
+	"github.com/kcmvp/xql/view"
+	"github.com/kcmvp/xql/view/openapi"
+)
+
+func main() {
+	openapi.Route(http.MethodPost, "/orders/:id", view.WithFields(view.Field[string]("customer")))
+	mux := http.NewServeMux()
+	mux.Handle("/openapi.json", openapi.Handler())
+	select {}
+}
@@ -3,11 +3,18 @@ package xql
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	_ "embed"
 
+	"github.com/fatih/color"
+	_ "github.com/go-sql-driver/mysql"
 	"github.com/kcmvp/xql/cmd/internal"
+	"github.com/kcmvp/xql/sqlx"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 	"github.com/tidwall/gjson"
@@ -74,7 +81,11 @@ var XqlCmd = &cobra.Command{
 var schemaCmd = &cobra.Command{
 	Use:   "schema [entities...]",
 	Short: "Generate schemas for all entities, or for a subset by passing space-separated entity names (e.g. `xql schema Account Order`).",
-	Args:  cobra.ArbitraryArgs,
+	Long: `Schema regenerates the field helpers of the entities under gen/field and writes the CREATE TABLE
+script of each new table to gen/schemas/<db>, recording it in gen/schemas/<db>/snapshot.json.
+The scripts of the tables in the snapshot are not rewritten, since they may be applied already;
+run ` + "`xql diff`" + ` to migrate the changes of those tables.`,
+	Args: cobra.ArbitraryArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		// Cobra already splits args by spaces. We keep it simple and treat each arg as an entity name.
@@ -89,24 +100,168 @@ var schemaCmd = &cobra.Command{
 	},
 }
 
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Apply the pending schema scripts to a datasource of application.yml.",
+	Long: `Migrate applies the scripts written by ` + "`xql schema`" + ` under gen/schemas/<db>, or the
+` + "`scripts`" + ` of the datasource configuration, that are not recorded in the xql_migrations
+table yet. It refuses to run when an applied script was changed since.
+Relative scripts of the configuration are resolved against the project root.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ds, _ := cmd.Flags().GetString("ds")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		scripts, _ := cmd.Flags().GetStringSlice("scripts")
+		opts := lo.Ternary(dryRun, []sqlx.MigrateOption{sqlx.DryRun()}, nil)
+		if len(scripts) > 0 {
+			// --scripts are relative to the working directory, not the project root
+			for i, script := range scripts {
+				abs, err := filepath.Abs(script)
+				if err != nil {
+					return err
+				}
+				scripts[i] = abs
+			}
+			opts = append(opts, sqlx.WithScripts(scripts...))
+		}
+		if err := os.Chdir(internal.Current.Root); err != nil {
+			return err
+		}
+		migrations, err := sqlx.Migrate(cmd.Context(), ds, opts...)
+		if err != nil {
+			return err
+		}
+		if len(migrations) == 0 {
+			color.Green("Database is up to date")
+			return nil
+		}
+		for _, m := range migrations {
+			color.Green("%s %s (ver: %s)", lo.Ternary(dryRun, "pending", "applied"), m.Name, m.Version)
+		}
+		return nil
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [entities...]",
+	Short: "Generate an ALTER TABLE migration from the entity changes since the last schema snapshot.",
+	Long: `Diff compares the entities, or a subset by name, with the snapshot recorded by ` + "`xql schema`" + `
+under gen/schemas/<db>/snapshot.json and writes the statements adding, dropping or altering
+columns, constraints and indexes to gen/schemas/<db>/<timestamp>_alter_<tables>.sql, then
+moves the snapshot forward. The generated CREATE TABLE scripts are left untouched so the
+applied migrations keep their checksums.
+With --ds the entities are compared with the live tables of that datasource instead.
+Review the migration before applying it with ` + "`xql migrate`" + `.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		names := lo.Uniq(lo.FilterMap(args, func(a string, _ int) (string, bool) {
+			a = strings.TrimSpace(a)
+			return a, a != ""
+		}))
+		if len(names) > 0 {
+			ctx = context.WithValue(ctx, entityFilterKey, names)
+		}
+		var (
+			q       sqlx.DB
+			dialect string
+		)
+		if cmd.Flags().Changed("ds") {
+			ds, _ := cmd.Flags().GetString("ds")
+			// application.yml is resolved against the project root
+			if err := os.Chdir(internal.Current.Root); err != nil {
+				return err
+			}
+			db, ok := sqlx.GetDS(ds)
+			if !ok {
+				return fmt.Errorf("datasource %q is not registered", ds)
+			}
+			q, dialect = db, sqlx.DialectOf(db).Name()
+		}
+		written, err := generateDiff(ctx, q, dialect)
+		if err != nil {
+			return err
+		}
+		if len(written) == 0 {
+			color.Green("Schema is up to date")
+		}
+		for _, file := range written {
+			color.Green("generated %s", file)
+		}
+		return nil
+	},
+}
+
 var validateCmd = &cobra.Command{
 	Use:   "validate",
 	Short: "Validate entity and schema definitions.",
-	Run: func(cmd *cobra.Command, args []string) {
-		// TODO: Implement validation logic
+	Long: `Validate lints all the entities of the project and reports, with their file:line positions,
+malformed or unknown xql tag directives, duplicate column names, foreign keys to unknown
+tables or columns, discouraged primary key types, struct fields skipped without ` + "`xql:\"-\"`" + `
+and generated field helpers or schemas older than their entity.
+It exits with a non-zero status when a problem is found.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		issues, err := validate(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(issues) == 0 {
+			color.Green("No problems found")
+			return nil
+		}
+		for _, issue := range issues {
+			color.Red("%s", issue)
+		}
+		// the problems are the output; don't print the usage after them
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d problem(s) found", len(issues))
 	},
 }
 
 var indexCmd = &cobra.Command{
-	Use:   "index",
-	Short: "Generate or update index files.",
-	Run: func(cmd *cobra.Command, args []string) {
-		// TODO: Implement index generation logic
+	Use:   "index [entities...]",
+	Short: "Generate index scripts from the entity index tags and the query usage.",
+	Long: `Index scans the packages of the project for the sqlx predicates (Eq, In, Gt, ...) and joins on
+generated fields, and counts the queries filtering or joining on each column. It writes an index
+on every column used by at least --min-uses queries that no index leads, and commented drops of
+the tagged indexes no query uses, to gen/indexes/<db>/indexes.sql, and reports both.
+Tag the suggested columns with ` + "`xql:\"index\"`" + `, or untag the unused indexes, and run ` + "`xql diff`" + `
+to migrate them.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		names := lo.Uniq(lo.FilterMap(args, func(a string, _ int) (string, bool) {
+			a = strings.TrimSpace(a)
+			return a, a != ""
+		}))
+		if len(names) > 0 {
+			ctx = context.WithValue(ctx, entityFilterKey, names)
+		}
+		minUses, _ := cmd.Flags().GetInt("min-uses")
+		written, issues, err := generateIndexes(ctx, max(minUses, 1))
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			color.Yellow("%s", issue)
+		}
+		for _, file := range written {
+			color.Green("generated %s", file)
+		}
+		return nil
 	},
 }
 
 func init() {
+	migrateCmd.Flags().String("ds", "", "datasource name in application.yml (default: the default datasource)")
+	migrateCmd.Flags().Bool("dry-run", false, "list the pending scripts without applying them")
+	migrateCmd.Flags().StringSlice("scripts", nil, "script files or directories (default: the datasource scripts or gen/schemas/<db>)")
+	diffCmd.Flags().String("ds", "", "compare with the live tables of this datasource in application.yml instead of the snapshot")
+	indexCmd.Flags().Int("min-uses", 2, "suggest an index on the columns used by at least this many queries")
 	XqlCmd.AddCommand(schemaCmd)
+	XqlCmd.AddCommand(migrateCmd)
+	XqlCmd.AddCommand(diffCmd)
 	XqlCmd.AddCommand(validateCmd)
 	XqlCmd.AddCommand(indexCmd)
 }
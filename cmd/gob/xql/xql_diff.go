@@ -0,0 +1,208 @@
+package xql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kcmvp/xql/cmd/internal"
+	"github.com/kcmvp/xql/cmd/internal/ddl"
+	"github.com/samber/lo"
+)
+
+// snapshotFile records, per adapter, the table definitions of the generated
+// scripts; `xql diff` compares the entities against it.
+const snapshotFile = "snapshot.json"
+
+// tableFor builds the table definition of an entity for the adapter. Composite
+// index and unique groups are named idx_<table>_<group> and uk_<table>_<group>.
+func tableFor(meta EntityMeta, adapter string) ddl.Table {
+	t := ddl.Table{Name: meta.TableName}
+	var groups []ddl.Index
+	group := func(name string, unique bool, column string) {
+		name = fmt.Sprintf("%s_%s_%s", lo.Ternary(unique, "uk", "idx"), meta.TableName, name)
+		if i := slices.IndexFunc(groups, func(idx ddl.Index) bool { return idx.Name == name }); i >= 0 {
+			groups[i].Columns = append(groups[i].Columns, column)
+		} else {
+			groups = append(groups, ddl.Index{Name: name, Columns: []string{column}, Unique: unique})
+		}
+	}
+	for _, f := range enrichFieldsForAdapter(meta.Fields, adapter) {
+		t.Columns = append(t.Columns, ddl.Column{
+			Name:    f.Name,
+			Type:    f.DBType,
+			PK:      f.IsPK,
+			NotNull: f.IsNotNull,
+			Unique:  f.IsUnique,
+			Default: f.Default,
+		})
+		if f.IsIndexed {
+			t.Indexes = append(t.Indexes, ddl.Index{
+				Name:    fmt.Sprintf("idx_%s_%s", meta.TableName, f.Name),
+				Columns: []string{f.Name},
+			})
+		}
+		for _, name := range f.IndexGroups {
+			group(name, false, f.Name)
+		}
+		for _, name := range f.UniqueGroups {
+			group(name, true, f.Name)
+		}
+		if f.FKTable != "" {
+			t.ForeignKeys = append(t.ForeignKeys, ddl.ForeignKey{
+				Name:      ddl.ForeignKeyName(meta.TableName, f.Name),
+				Column:    f.Name,
+				RefTable:  f.FKTable,
+				RefColumn: f.FKColumn,
+				OnDelete:  f.OnDelete,
+				OnUpdate:  f.OnUpdate,
+			})
+		}
+	}
+	t.Indexes = append(t.Indexes, groups...)
+	return t
+}
+
+// schemaDir returns the directory of the generated scripts of the adapter.
+func schemaDir(project *internal.Project, adapter string) string {
+	return filepath.Join(project.GenPath(), "schemas", adapter)
+}
+
+// recordSnapshot adds the tables of the entities to the snapshot of the adapter.
+// Tables recorded already are kept unless overwrite is set: they describe the
+// scripts applied so far, which only `xql diff` moves forward.
+func recordSnapshot(project *internal.Project, adapter string, metas []EntityMeta, overwrite bool) error {
+	path := filepath.Join(schemaDir(project, adapter), snapshotFile)
+	tables, err := ddl.ReadSnapshot(path)
+	if err != nil {
+		return err
+	}
+	for _, meta := range metas {
+		if _, ok := tables[meta.TableName]; !ok || overwrite {
+			tables[meta.TableName] = tableFor(meta, adapter)
+		}
+	}
+	return ddl.WriteSnapshot(path, tables)
+}
+
+// deferForeignKeys removes the deferred foreign keys of the entity from its
+// table and returns them. SQLite accepts foreign keys to tables created later,
+// so nothing is deferred there.
+func deferForeignKeys(adapter string, meta EntityMeta, t ddl.Table) (ddl.Table, []ddl.ForeignKey) {
+	if adapter == ddl.SQLite || len(meta.DeferredFKs) == 0 {
+		return t, nil
+	}
+	deferred, kept := lo.FilterReject(t.ForeignKeys, func(fk ddl.ForeignKey, _ int) bool {
+		return slices.Contains(meta.DeferredFKs, fk.Column)
+	})
+	t.ForeignKeys = kept
+	return t, deferred
+}
+
+// diffStatements renders the statements moving the previous table definitions
+// into the ones of the entities, and returns the changed tables; tables missing
+// from previous are created. The deferred foreign keys come last.
+func diffStatements(adapter string, metas []EntityMeta, previous map[string]ddl.Table) ([]string, []string) {
+	var stmts, trailing, changed []string
+	for _, meta := range metas {
+		table := tableFor(meta, adapter)
+		// the foreign keys closing a cycle are added or changed once all the
+		// tables exist; until then the table keeps its previous ones
+		stripped, deferred := deferForeignKeys(adapter, meta, table)
+		var diff, late []string
+		if old, ok := previous[meta.TableName]; ok {
+			stripped.ForeignKeys = append(stripped.ForeignKeys, lo.Filter(old.ForeignKeys, func(fk ddl.ForeignKey, _ int) bool {
+				return slices.Contains(meta.DeferredFKs, fk.Column)
+			})...)
+			diff = ddl.Diff(adapter, old, stripped)
+			if len(deferred) > 0 {
+				late = ddl.Diff(adapter, stripped, table)
+			}
+		} else {
+			diff = ddl.CreateTable(adapter, stripped)
+			late = lo.Map(deferred, func(fk ddl.ForeignKey, _ int) string { return ddl.AddForeignKey(table.Name, fk) })
+		}
+		if len(diff) > 0 || len(late) > 0 {
+			stmts = append(stmts, diff...)
+			trailing = append(trailing, late...)
+			changed = append(changed, meta.TableName)
+		}
+	}
+	return append(stmts, trailing...), changed
+}
+
+// generateDiff writes the migration moving the database of each adapter to the
+// current entities, and returns the written files. The previous definitions
+// come from the adapter snapshot, or from the live database when q is set, in
+// which case dialect names the only adapter to compare.
+func generateDiff(ctx context.Context, q ddl.Querier, dialect string) ([]string, error) {
+	metas, err := generateMeta(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return generateDiffFromMeta(ctx, metas, q, dialect)
+}
+
+// generateDiffFromMeta writes the migrations of generateDiff for the entities.
+func generateDiffFromMeta(ctx context.Context, metas []EntityMeta, q ddl.Querier, dialect string) ([]string, error) {
+	project := internal.Current
+	if project == nil {
+		return nil, fmt.Errorf("project context not initialized")
+	}
+	adapters, ok := ctx.Value(dbaAdapterKey).([]string)
+	if !ok || len(adapters) == 0 {
+		return nil, fmt.Errorf("no database adapters are configured or detected")
+	}
+	if q != nil {
+		adapters = []string{dialect}
+	}
+	now := time.Now()
+	var written []string
+	for _, adapter := range adapters {
+		dir := schemaDir(project, adapter)
+		previous, err := ddl.ReadSnapshot(filepath.Join(dir, snapshotFile))
+		if err != nil {
+			return nil, err
+		}
+		if q != nil {
+			previous = map[string]ddl.Table{}
+			for _, meta := range metas {
+				table, exists, err := ddl.Introspect(ctx, q, adapter, meta.TableName)
+				if err != nil {
+					return nil, err
+				}
+				if exists {
+					previous[meta.TableName] = table
+				}
+			}
+		}
+		stmts, changed := diffStatements(adapter, metas, previous)
+		if len(stmts) == 0 {
+			continue
+		}
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "-- Generated by dvo xql diff. Review before applying.\n-- Generated at: %s\n", now.Format("2006-01-02 15:04:05"))
+		for _, stmt := range stmts {
+			fmt.Fprintf(&buf, "\n%s;\n", stmt)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create output directory %s: %w", dir, err)
+		}
+		// name the migration after the changed tables, or after the schema when many change
+		name := fmt.Sprintf("%s_alter_%s.sql", now.Format("20060102150405"), lo.Ternary(len(changed) > 3, "schema", strings.Join(changed, "_")))
+		output := filepath.Join(dir, name)
+		if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write migration %s: %w", output, err)
+		}
+		if err := recordSnapshot(project, adapter, metas, true); err != nil {
+			return nil, err
+		}
+		written = append(written, output)
+	}
+	return written, nil
+}
@@ -0,0 +1,208 @@
+package xql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kcmvp/xql/cmd/internal"
+	"github.com/kcmvp/xql/cmd/internal/ddl"
+	"github.com/kcmvp/xql/sqlx"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffStatements(t *testing.T) {
+	meta := EntityMeta{
+		StructName: "Account",
+		TableName:  "accounts",
+		Fields: []Field{
+			{Name: "id", GoName: "ID", GoType: "int64", IsPK: true},
+			{Name: "email", GoName: "Email", GoType: "string", IsUnique: true},
+			{Name: "age", GoName: "Age", GoType: "int32", IsIndexed: true},
+		},
+	}
+	table := tableFor(meta, "sqlite")
+	require.Equal(t, ddl.Table{
+		Name: "accounts",
+		Columns: []ddl.Column{
+			{Name: "id", Type: "INTEGER", PK: true},
+			{Name: "email", Type: "TEXT", Unique: true},
+			{Name: "age", Type: "INTEGER"},
+		},
+		Indexes: []ddl.Index{{Name: "idx_accounts_age", Columns: []string{"age"}}},
+	}, table)
+
+	// unknown tables are created
+	stmts, changed := diffStatements("sqlite", []EntityMeta{meta}, map[string]ddl.Table{})
+	require.Equal(t, []string{"accounts"}, changed)
+	require.Equal(t, ddl.CreateTable("sqlite", table), stmts)
+
+	// unchanged tables produce no statements
+	stmts, changed = diffStatements("sqlite", []EntityMeta{meta}, map[string]ddl.Table{"accounts": table})
+	require.Empty(t, stmts)
+	require.Empty(t, changed)
+
+	previous := tableFor(meta, "postgres")
+	previous.Indexes = nil
+	stmts, changed = diffStatements("postgres", []EntityMeta{meta}, map[string]ddl.Table{"accounts": previous})
+	require.Equal(t, []string{"accounts"}, changed)
+	require.Equal(t, []string{"CREATE INDEX IF NOT EXISTS idx_accounts_age ON accounts (age)"}, stmts)
+}
+
+func TestTableFor_Constraints(t *testing.T) {
+	var accountID, roleID Field
+	parseDirectives("fk:accounts.id;on delete:cascade;unique:account_role", &accountID)
+	parseDirectives("fk:roles.id;on update: set null ;unique:account_role;index:role", &roleID)
+	require.Equal(t, "CASCADE", accountID.OnDelete)
+	require.Equal(t, "SET NULL", roleID.OnUpdate)
+	require.Equal(t, []string{"account_role"}, roleID.UniqueGroups)
+	require.False(t, roleID.IsUnique)
+
+	accountID.Name, accountID.GoName, accountID.GoType = "account_id", "AccountID", "int64"
+	roleID.Name, roleID.GoName, roleID.GoType = "role_id", "RoleID", "int64"
+	meta := EntityMeta{StructName: "AccountRole", TableName: "account_roles", Fields: []Field{accountID, roleID}}
+	table := tableFor(meta, "postgres")
+	require.Equal(t, []ddl.Index{
+		{Name: "uk_account_roles_account_role", Columns: []string{"account_id", "role_id"}, Unique: true},
+		{Name: "idx_account_roles_role", Columns: []string{"role_id"}},
+	}, table.Indexes)
+	require.Equal(t, []ddl.ForeignKey{
+		{Name: "fk_account_roles_account_id", Column: "account_id", RefTable: "accounts", RefColumn: "id", OnDelete: "CASCADE"},
+		{Name: "fk_account_roles_role_id", Column: "role_id", RefTable: "roles", RefColumn: "id", OnUpdate: "SET NULL"},
+	}, table.ForeignKeys)
+
+	accounts := EntityMeta{StructName: "Account", TableName: "accounts", Fields: []Field{{Name: "id", GoType: "int64", IsPK: true}}}
+	roles := EntityMeta{StructName: "Role", TableName: "roles", Fields: []Field{{Name: "id", GoType: "int64", IsPK: true}}}
+	ordered, warnings := orderByReferences([]EntityMeta{meta, roles, accounts})
+	require.Empty(t, warnings)
+	require.Equal(t, []string{"roles", "accounts", "account_roles"}, []string{ordered[0].TableName, ordered[1].TableName, ordered[2].TableName})
+
+	// the cycle is broken at the foreign key closing it
+	roles.Fields = append(roles.Fields, Field{Name: "owner_id", GoType: "int64", FKTable: "account_roles", FKColumn: "account_id"})
+	ordered, warnings = orderByReferences([]EntityMeta{meta, roles, accounts})
+	require.Equal(t, []string{"cyclic foreign keys between tables account_roles, roles: fk_roles_owner_id is added once the tables exist"}, warnings)
+	require.Equal(t, []string{"accounts", "roles", "account_roles"}, []string{ordered[0].TableName, ordered[1].TableName, ordered[2].TableName})
+	require.Equal(t, []string{"owner_id"}, ordered[1].DeferredFKs)
+	require.Empty(t, roles.DeferredFKs)
+
+	table, deferred := deferForeignKeys("postgres", ordered[1], tableFor(ordered[1], "postgres"))
+	require.Empty(t, table.ForeignKeys)
+	require.Equal(t, []string{"ALTER TABLE roles ADD CONSTRAINT fk_roles_owner_id FOREIGN KEY (owner_id) REFERENCES account_roles (account_id)"},
+		lo.Map(deferred, func(fk ddl.ForeignKey, _ int) string { return ddl.AddForeignKey(table.Name, fk) }))
+	stmts, changed := diffStatements("postgres", ordered, nil)
+	require.Equal(t, []string{"accounts", "roles", "account_roles"}, changed)
+	require.Len(t, stmts, 6)
+	require.Equal(t, ddl.AddForeignKey("roles", deferred[0]), stmts[5])
+	// an existing table gains the deferred foreign key after the other changes
+	previous := lo.SliceToMap(ordered, func(m EntityMeta) (string, ddl.Table) { return m.TableName, tableFor(m, "postgres") })
+	previous["roles"] = table
+	stmts, changed = diffStatements("postgres", ordered, previous)
+	require.Equal(t, []string{"roles"}, changed)
+	require.Len(t, stmts, 1)
+	require.True(t, strings.HasSuffix(stmts[0], "\n"+ddl.AddForeignKey("roles", deferred[0])), stmts[0])
+	// sqlite accepts references to tables created later
+	table, deferred = deferForeignKeys("sqlite", ordered[1], tableFor(ordered[1], "sqlite"))
+	require.Len(t, table.ForeignKeys, 1)
+	require.Empty(t, deferred)
+}
+
+func TestSchemaDiffMigrate(t *testing.T) {
+	current := internal.Current
+	internal.Current = &internal.Project{Root: t.TempDir()}
+	t.Cleanup(func() { internal.Current = current })
+	db, ok := sqlx.DefaultDS()
+	require.True(t, ok)
+	ctx := context.WithValue(context.Background(), dbaAdapterKey, []string{"sqlite"})
+	dir := schemaDir(internal.Current, "sqlite")
+	migrate := func() []string {
+		applied, err := sqlx.Migrate(ctx, "", sqlx.WithScripts(dir))
+		require.NoError(t, err)
+		return lo.Map(applied, func(m sqlx.Migration, _ int) string { return m.Name })
+	}
+	reset := func() {
+		for _, table := range []string{"ledgers", "xql_migrations"} {
+			_, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS "+table)
+			require.NoError(t, err)
+		}
+	}
+	reset()
+	t.Cleanup(reset)
+
+	ledger := EntityMeta{StructName: "Ledger", TableName: "ledgers", Fields: []Field{
+		{Name: "id", GoName: "ID", GoType: "int64", IsPK: true},
+		{Name: "name", GoName: "Name", GoType: "string"},
+	}}
+	require.NoError(t, generateSchemaFromMeta(ctx, []EntityMeta{ledger}))
+	require.Equal(t, []string{"ledger_schema.sql"}, migrate())
+	script := filepath.Join(dir, "ledger_schema.sql")
+	created, err := os.ReadFile(script)
+	require.NoError(t, err)
+
+	// the entity changes: schema keeps the applied script and diff migrates the table
+	ledger.Fields = append(ledger.Fields, Field{Name: "balance", GoName: "Balance", GoType: "float64"})
+	require.NoError(t, generateSchemaFromMeta(ctx, []EntityMeta{ledger}))
+	regenerated, err := os.ReadFile(script)
+	require.NoError(t, err)
+	require.Equal(t, string(created), string(regenerated))
+	written, err := generateDiffFromMeta(ctx, []EntityMeta{ledger}, nil, "")
+	require.NoError(t, err)
+	require.Len(t, written, 1)
+	require.Equal(t, []string{filepath.Base(written[0])}, migrate())
+	_, err = db.ExecContext(ctx, "INSERT INTO ledgers (name, balance) VALUES ('cash', 1.5)")
+	require.NoError(t, err)
+	require.Empty(t, migrate())
+
+	// a fresh database runs the CREATE script, then the ALTER one
+	reset()
+	require.Equal(t, []string{"ledger_schema.sql", filepath.Base(written[0])}, migrate())
+	_, err = db.ExecContext(ctx, "INSERT INTO ledgers (name, balance) VALUES ('cash', 1.5)")
+	require.NoError(t, err)
+}
+
+func TestSchemaCyclicForeignKeys(t *testing.T) {
+	current := internal.Current
+	internal.Current = &internal.Project{Root: t.TempDir()}
+	t.Cleanup(func() { internal.Current = current })
+	db, ok := sqlx.DefaultDS()
+	require.True(t, ok)
+	ctx := context.WithValue(context.Background(), dbaAdapterKey, []string{"sqlite", "postgres"})
+	reset := func() {
+		for _, table := range []string{"employees", "departments", "xql_migrations"} {
+			_, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS "+table)
+			require.NoError(t, err)
+		}
+	}
+	reset()
+	t.Cleanup(reset)
+
+	departments := EntityMeta{StructName: "Department", TableName: "departments", Fields: []Field{
+		{Name: "id", GoName: "ID", GoType: "int64", IsPK: true},
+		{Name: "head_id", GoName: "HeadID", GoType: "int64", FKTable: "employees", FKColumn: "id"},
+	}}
+	employees := EntityMeta{StructName: "Employee", TableName: "employees", Fields: []Field{
+		{Name: "id", GoName: "ID", GoType: "int64", IsPK: true},
+		{Name: "department_id", GoName: "DepartmentID", GoType: "int64", FKTable: "departments", FKColumn: "id"},
+	}}
+	metas, warnings := orderByReferences([]EntityMeta{departments, employees})
+	require.Len(t, warnings, 1)
+	require.NoError(t, generateSchemaFromMeta(ctx, metas))
+
+	// postgres creates the tables first and adds the cyclic foreign key last
+	pg := schemaDir(internal.Current, "postgres")
+	constraints, err := os.ReadFile(filepath.Join(pg, "employee_constraints.sql"))
+	require.NoError(t, err)
+	require.Contains(t, string(constraints), "ALTER TABLE employees ADD CONSTRAINT fk_employees_department_id FOREIGN KEY (department_id) REFERENCES departments (id);")
+	schema, err := os.ReadFile(filepath.Join(pg, "employee_schema.sql"))
+	require.NoError(t, err)
+	require.NotContains(t, string(schema), "REFERENCES")
+
+	// sqlite keeps the foreign keys in the tables and migrates the cycle
+	sqlite := schemaDir(internal.Current, "sqlite")
+	require.NoFileExists(t, filepath.Join(sqlite, "employee_constraints.sql"))
+	applied, err := sqlx.Migrate(ctx, "", sqlx.WithScripts(sqlite))
+	require.NoError(t, err)
+	require.Len(t, applied, 2)
+}
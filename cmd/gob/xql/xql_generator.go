@@ -17,6 +17,7 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"sort"
 	"strings"
 	"text/template"
@@ -24,7 +25,9 @@ import (
 
 	_ "embed"
 
+	"github.com/fatih/color"
 	"github.com/kcmvp/xql/cmd/internal"
+	"github.com/kcmvp/xql/cmd/internal/ddl"
 	"github.com/samber/lo"
 	"github.com/tidwall/gjson"
 	"golang.org/x/tools/go/packages"
@@ -40,6 +43,7 @@ var schemaTmpl string
 type SchemaTemplateData struct {
 	TableName   string
 	Fields      []Field
+	Statements  []string // the CREATE TABLE and CREATE INDEX statements
 	GeneratedAt time.Time
 	Version     string
 }
@@ -59,19 +63,27 @@ type TemplateData struct {
 
 // Field represents a single column in a database table, derived from a Go struct field.
 type Field struct {
-	Name       string // The database column name (e.g., "creation_time").
-	GoName     string // The original Go field name (e.g., "CreatedAt").
-	GoType     string // The Go type of the field (e.g., "time.Time").
-	DBType     string // The specific SQL type for the column (e.g., "TIMESTAMP WITH TIME ZONE").
-	IsPK       bool   // True if this field is the primary key.
-	IsNotNull  bool   // True if the column has a NOT NULL constraint.
-	IsUnique   bool   // True if the column has a UNIQUE constraint.
-	IsIndexed  bool   // True if an index should be created on this column.
-	Default    string // The default value for the column, as a string.
-	FKTable    string // The table referenced by a foreign key.
-	FKColumn   string // The column referenced by a foreign key.
-	Warning    string // A warning message associated with this field, e.g., for discouraged PK types.
-	IsEmbedded bool
+	Name      string // The database column name (e.g., "creation_time").
+	GoName    string // The original Go field name (e.g., "CreatedAt").
+	GoType    string // The Go type of the field (e.g., "time.Time").
+	DBType    string // The specific SQL type for the column (e.g., "TIMESTAMP WITH TIME ZONE").
+	IsPK      bool   // True if this field is the primary key.
+	IsNotNull bool   // True if the column has a NOT NULL constraint.
+	IsUnique  bool   // True if the column has a UNIQUE constraint.
+	IsIndexed bool   // True if an index should be created on this column.
+	Default   string // The default value for the column, as a string.
+	FKTable   string // The table referenced by a foreign key.
+	FKColumn  string // The column referenced by a foreign key.
+	OnDelete  string // The ON DELETE action of the foreign key, e.g. "CASCADE".
+	OnUpdate  string // The ON UPDATE action of the foreign key.
+	// IndexGroups and UniqueGroups name the composite indexes the column belongs
+	// to; the columns of a group keep the field order.
+	IndexGroups  []string
+	UniqueGroups []string
+	Warning      string // A warning message associated with this field, e.g., for discouraged PK types.
+	IsEmbedded   bool
+	Tag          string    // The raw xql struct tag.
+	Pos          token.Pos // The position of the field declaration.
 }
 
 // isSupportedType checks if a field type is valid.
@@ -143,6 +155,9 @@ type EntityMeta struct {
 	TypeSpec   *ast.TypeSpec
 	TableName  string
 	Fields     []Field // adapter-agnostic field info (no DBType)
+	// DeferredFKs are the columns whose foreign key closes a cycle between
+	// tables; it is added once all the tables of the cycle exist.
+	DeferredFKs []string
 }
 
 // generate is the single entrypoint for this package's generation workflow.
@@ -232,7 +247,60 @@ func generateMeta(ctx context.Context) ([]EntityMeta, error) {
 	if len(metas) == 0 {
 		return nil, fmt.Errorf("no entity structs found")
 	}
-	return metas, nil
+	ordered, warnings := orderByReferences(metas)
+	for _, warning := range warnings {
+		color.Yellow("warning: %s", warning)
+	}
+	return ordered, nil
+}
+
+// orderByReferences orders the entities so that a table comes after the tables
+// its foreign keys reference; independent entities keep their order. Schemas
+// and migrations are generated in this order.
+//
+// A cycle of foreign keys, e.g. employees.department_id and departments.head_id,
+// is broken at the foreign key closing it: the column is recorded in the
+// DeferredFKs of its entity and a warning is returned.
+func orderByReferences(metas []EntityMeta) ([]EntityMeta, []string) {
+	tables := lo.SliceToMap(metas, func(m EntityMeta) (string, bool) { return m.TableName, true })
+	done := map[string]bool{}
+	ordered := make([]EntityMeta, 0, len(metas))
+	pending := slices.Clone(metas)
+	// blocking returns the fields of m referencing a table not ordered yet
+	blocking := func(m EntityMeta) []Field {
+		return lo.Filter(m.Fields, func(f Field, _ int) bool {
+			return f.FKTable != "" && f.FKTable != m.TableName && tables[f.FKTable] && !done[f.FKTable] && !slices.Contains(m.DeferredFKs, f.Name)
+		})
+	}
+	var warnings []string
+	for len(pending) > 0 {
+		i := slices.IndexFunc(pending, func(m EntityMeta) bool { return len(blocking(m)) == 0 })
+		if i < 0 {
+			// follow the blocking references until a table repeats: the last
+			// reference of the walk closes a cycle
+			path := []int{0}
+			for {
+				next := slices.IndexFunc(pending, func(m EntityMeta) bool {
+					return m.TableName == blocking(pending[path[len(path)-1]])[0].FKTable
+				})
+				if at := slices.Index(path, next); at >= 0 {
+					cycle := lo.Map(path[at:], func(j int, _ int) string { return pending[j].TableName })
+					m := &pending[path[len(path)-1]]
+					f := blocking(*m)[0]
+					m.DeferredFKs = append(slices.Clone(m.DeferredFKs), f.Name)
+					warnings = append(warnings, fmt.Sprintf("cyclic foreign keys between tables %s: %s is added once the tables exist",
+						strings.Join(cycle, ", "), ddl.ForeignKeyName(m.TableName, f.Name)))
+					break
+				}
+				path = append(path, next)
+			}
+			continue
+		}
+		done[pending[i].TableName] = true
+		ordered = append(ordered, pending[i])
+		pending = slices.Delete(pending, i, i+1)
+	}
+	return ordered, warnings
 }
 
 func resolveTableName(project *internal.Project, pkgPath, structName string) (string, error) {
@@ -467,25 +535,32 @@ func generateSchemaFromMeta(ctx context.Context, metas []EntityMeta) error {
 		return fmt.Errorf("no database adapters are configured or detected")
 	}
 
-	funcMap := template.FuncMap{
-		"plus1": func(i int) int { return i + 1 },
-	}
-
-	tmpl, err := template.New("schema").Funcs(funcMap).Parse(schemaTmpl)
+	tmpl, err := template.New("schema").Parse(schemaTmpl)
 	if err != nil {
 		return fmt.Errorf("failed to parse schema template: %w", err)
 	}
 
 	for _, adapter := range adapters {
+		snapshot, err := ddl.ReadSnapshot(filepath.Join(schemaDir(project, adapter), snapshotFile))
+		if err != nil {
+			return err
+		}
 		for _, meta := range metas {
 			fields := enrichFieldsForAdapter(meta.Fields, adapter)
 			if len(fields) == 0 {
 				continue
 			}
+			// the script of a table in the snapshot may be applied already: rewriting
+			// it would break its checksum and clash with the `xql diff` migrations
+			if _, ok := snapshot[meta.TableName]; ok {
+				continue
+			}
 
+			table, deferred := deferForeignKeys(adapter, meta, tableFor(meta, adapter))
 			data := SchemaTemplateData{
 				TableName:   meta.TableName,
 				Fields:      fields,
+				Statements:  ddl.CreateTable(adapter, table),
 				GeneratedAt: time.Now(),
 				Version:     computeEntityVersion(meta),
 			}
@@ -503,8 +578,24 @@ func generateSchemaFromMeta(ctx context.Context, metas []EntityMeta) error {
 			if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
 				return fmt.Errorf("failed to write generated schema for %s: %w", meta.StructName, err)
 			}
+			// the foreign keys closing a cycle go to a script of their own, which
+			// migrations run once the referenced tables exist
+			if len(deferred) > 0 {
+				data.Statements = lo.Map(deferred, func(fk ddl.ForeignKey, _ int) string { return ddl.AddForeignKey(table.Name, fk) })
+				buf.Reset()
+				if err := tmpl.Execute(&buf, data); err != nil {
+					return fmt.Errorf("failed to execute schema template for %s: %w", meta.StructName, err)
+				}
+				outputPath = filepath.Join(outputDir, fmt.Sprintf("%s_constraints.sql", lo.SnakeCase(meta.StructName)))
+				if err := os.WriteFile(outputPath, buf.Bytes(), 0644); err != nil {
+					return fmt.Errorf("failed to write generated constraints for %s: %w", meta.StructName, err)
+				}
+			}
 			// generation info suppressed in non-verbose mode
 		}
+		if err := recordSnapshot(project, adapter, metas, false); err != nil {
+			return fmt.Errorf("failed to record schema snapshot for %s: %w", adapter, err)
+		}
 	}
 
 	return nil
@@ -602,6 +693,8 @@ func parseFields(pkg *packages.Package, spec *ast.TypeSpec, adapter string) ([]F
 			GoName: field.Names[0].Name,
 			GoType: goType,
 			Name:   lo.SnakeCase(field.Names[0].Name),
+			Tag:    xqlTag,
+			Pos:    field.Pos(),
 		}
 
 		parseDirectives(xqlTag, &entityField)
@@ -632,9 +725,17 @@ func parseDirectives(tag string, field *Field) {
 		case "not null":
 			field.IsNotNull = true
 		case "unique":
-			field.IsUnique = true
+			if value = strings.TrimSpace(value); value != "" {
+				field.UniqueGroups = append(field.UniqueGroups, value)
+			} else {
+				field.IsUnique = true
+			}
 		case "index":
-			field.IsIndexed = true
+			if value = strings.TrimSpace(value); value != "" {
+				field.IndexGroups = append(field.IndexGroups, value)
+			} else {
+				field.IsIndexed = true
+			}
 		case "name":
 			field.Name = value
 		case "type":
@@ -647,6 +748,10 @@ func parseDirectives(tag string, field *Field) {
 				field.FKTable = fkParts[0]
 				field.FKColumn = fkParts[1]
 			}
+		case "on delete":
+			field.OnDelete = strings.ToUpper(strings.TrimSpace(value))
+		case "on update":
+			field.OnUpdate = strings.ToUpper(strings.TrimSpace(value))
 		}
 	}
 }
@@ -750,23 +855,32 @@ func computeEntityVersion(meta EntityMeta) string {
 		FKTable    string `json:"fkTable"`
 		FKColumn   string `json:"fkColumn"`
 		IsEmbedded bool   `json:"isEmbedded"`
+		// omitted when empty so that the versions of entities without them do not change
+		OnDelete     string   `json:"onDelete,omitempty"`
+		OnUpdate     string   `json:"onUpdate,omitempty"`
+		IndexGroups  []string `json:"indexGroups,omitempty"`
+		UniqueGroups []string `json:"uniqueGroups,omitempty"`
 	}
 
 	vfs := make([]vf, 0, len(meta.Fields))
 	for _, f := range meta.Fields {
 		vfs = append(vfs, vf{
-			GoName:     f.GoName,
-			GoType:     f.GoType,
-			Name:       f.Name,
-			DBType:     f.DBType,
-			IsPK:       f.IsPK,
-			IsNotNull:  f.IsNotNull,
-			IsUnique:   f.IsUnique,
-			IsIndexed:  f.IsIndexed,
-			Default:    f.Default,
-			FKTable:    f.FKTable,
-			FKColumn:   f.FKColumn,
-			IsEmbedded: f.IsEmbedded,
+			GoName:       f.GoName,
+			GoType:       f.GoType,
+			Name:         f.Name,
+			DBType:       f.DBType,
+			IsPK:         f.IsPK,
+			IsNotNull:    f.IsNotNull,
+			IsUnique:     f.IsUnique,
+			IsIndexed:    f.IsIndexed,
+			Default:      f.Default,
+			FKTable:      f.FKTable,
+			FKColumn:     f.FKColumn,
+			IsEmbedded:   f.IsEmbedded,
+			OnDelete:     f.OnDelete,
+			OnUpdate:     f.OnUpdate,
+			IndexGroups:  f.IndexGroups,
+			UniqueGroups: f.UniqueGroups,
 		})
 	}
 
@@ -0,0 +1,259 @@
+package xql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kcmvp/xql/cmd/internal"
+	"github.com/kcmvp/xql/cmd/internal/ddl"
+	"github.com/samber/lo"
+	"golang.org/x/tools/go/packages"
+)
+
+// queryFuncs are the sqlx functions and Join methods whose field arguments a
+// query filters or joins on, mapped to whether they join: predicates take the
+// field first, joins and EqField compare both of their arguments.
+var queryFuncs = map[string]bool{
+	"Eq": false, "Ne": false, "Gt": false, "Gte": false, "Lt": false, "Lte": false,
+	"Like": false, "ILike": false, "In": false, "NotIn": false, "Between": false,
+	"IsNull": false, "IsNotNull": false,
+	"InnerJoin": true, "LeftJoin": true, "RightJoin": true, "EqField": true,
+}
+
+// columnRef names a column of a table.
+type columnRef struct {
+	Table  string
+	Column string
+}
+
+// columnUsage counts the queries filtering or joining on a column.
+type columnUsage struct {
+	Filters int
+	Joins   int
+	Pos     []token.Position
+}
+
+func (u columnUsage) uses() int {
+	return u.Filters + u.Joins
+}
+
+func (u columnUsage) String() string {
+	return fmt.Sprintf("used by %d filter(s) and %d join(s)", u.Filters, u.Joins)
+}
+
+// fieldPackages maps the import paths of the generated field helpers to their entity.
+func fieldPackages(project *internal.Project, metas []EntityMeta) map[string]EntityMeta {
+	dirs := lo.SliceToMap(metas, func(m EntityMeta) (string, EntityMeta) {
+		return filepath.Join(project.GenPath(), "field", strings.ToLower(m.StructName)), m
+	})
+	fields := map[string]EntityMeta{}
+	for _, pkg := range project.Pkgs {
+		if len(pkg.GoFiles) == 0 {
+			continue
+		}
+		if meta, ok := dirs[filepath.Dir(pkg.GoFiles[0])]; ok {
+			fields[pkg.PkgPath] = meta
+		}
+	}
+	return fields
+}
+
+// scanUsage counts, per column, the calls of the sqlx package at sqlxPath that
+// filter or join on a generated field; fields maps the field helper packages
+// to their entity. Fields passed through variables are not followed.
+func scanUsage(pkgs []*packages.Package, sqlxPath string, fields map[string]EntityMeta) map[columnRef]*columnUsage {
+	usage := map[columnRef]*columnUsage{}
+	ident := func(expr ast.Expr) *ast.Ident {
+		switch e := ast.Unparen(expr).(type) {
+		case *ast.SelectorExpr:
+			return e.Sel
+		case *ast.Ident:
+			return e
+		}
+		return nil
+	}
+	column := func(info *types.Info, arg ast.Expr) (columnRef, bool) {
+		id := ident(arg)
+		if id == nil {
+			return columnRef{}, false
+		}
+		v, ok := info.Uses[id].(*types.Var)
+		if !ok || v.Pkg() == nil || v.Parent() != v.Pkg().Scope() {
+			return columnRef{}, false
+		}
+		meta, ok := fields[v.Pkg().Path()]
+		if !ok {
+			return columnRef{}, false
+		}
+		f, ok := lo.Find(meta.Fields, func(f Field) bool { return f.GoName == v.Name() })
+		return columnRef{Table: meta.TableName, Column: f.Name}, ok
+	}
+	for _, pkg := range pkgs {
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok || len(call.Args) == 0 {
+					return true
+				}
+				id := ident(call.Fun)
+				if id == nil {
+					return true
+				}
+				fn, ok := pkg.TypesInfo.Uses[id].(*types.Func)
+				if !ok || fn.Pkg() == nil || fn.Pkg().Path() != sqlxPath {
+					return true
+				}
+				join, ok := queryFuncs[fn.Name()]
+				if !ok {
+					return true
+				}
+				args := call.Args[:lo.Ternary(join && len(call.Args) > 1, 2, 1)]
+				for _, arg := range args {
+					ref, ok := column(pkg.TypesInfo, arg)
+					if !ok {
+						continue
+					}
+					u := usage[ref]
+					if u == nil {
+						u = &columnUsage{}
+						usage[ref] = u
+					}
+					if join {
+						u.Joins++
+					} else {
+						u.Filters++
+					}
+					u.Pos = append(u.Pos, pkg.Fset.Position(arg.Pos()))
+				}
+				return true
+			})
+		}
+	}
+	return usage
+}
+
+// indexAdvice is an index of the `xql index` script, declared by the entity
+// tags or suggested by the queries, with the usage of its leading column.
+type indexAdvice struct {
+	Index  ddl.Index
+	Tagged bool
+	Usage  columnUsage
+}
+
+// unused reports a tagged index no query filters or joins on. Unique indexes
+// enforce a constraint and are never reported.
+func (a indexAdvice) unused() bool {
+	return a.Tagged && !a.Index.Unique && a.Usage.uses() == 0
+}
+
+// adviseIndexes returns the indexes of the table declared by the entity tags,
+// followed by an index on every column used by at least minUses queries which
+// does not lead the primary key, a unique constraint or a tagged index.
+func adviseIndexes(table ddl.Table, usage map[columnRef]*columnUsage, minUses int) []indexAdvice {
+	used := func(column string) columnUsage {
+		if u := usage[columnRef{Table: table.Name, Column: column}]; u != nil {
+			return *u
+		}
+		return columnUsage{}
+	}
+	covered := map[string]bool{}
+	if pk, ok := lo.Find(table.Columns, func(c ddl.Column) bool { return c.PK }); ok {
+		covered[pk.Name] = true
+	}
+	var advice []indexAdvice
+	for _, idx := range table.Indexes {
+		covered[idx.Columns[0]] = true
+		advice = append(advice, indexAdvice{Index: idx, Tagged: true, Usage: used(idx.Columns[0])})
+	}
+	for _, c := range table.Columns {
+		if u := used(c.Name); !covered[c.Name] && !c.Unique && u.uses() > 0 && u.uses() >= minUses {
+			advice = append(advice, indexAdvice{
+				Index: ddl.Index{Name: fmt.Sprintf("idx_%s_%s", table.Name, c.Name), Columns: []string{c.Name}},
+				Usage: u,
+			})
+		}
+	}
+	return advice
+}
+
+// writeIndexScript writes the suggested indexes of the table as CREATE INDEX
+// statements, and the unused ones as commented DROP INDEX suggestions. The
+// other tagged indexes are created by the schema scripts already.
+func writeIndexScript(buf *bytes.Buffer, adapter, table string, advice []indexAdvice) {
+	for _, a := range advice {
+		column := a.Index.Columns[0]
+		switch {
+		case !a.Tagged:
+			fmt.Fprintf(buf, "\n-- SUGGESTED: %s.%s is %s but not indexed\n%s;\n", table, column, a.Usage, ddl.CreateIndex(adapter, table, a.Index))
+		case a.unused():
+			fmt.Fprintf(buf, "\n-- UNUSED: no query filters or joins on %s.%s; drop it with\n-- %s;\n", table, column, ddl.DropIndex(adapter, table, a.Index))
+		}
+	}
+}
+
+// generateIndexes writes the index script of each adapter and returns the
+// written files with the columns filtered but not indexed and the tagged
+// indexes no query uses.
+func generateIndexes(ctx context.Context, minUses int) ([]string, []Issue, error) {
+	project := internal.Current
+	if project == nil {
+		return nil, nil, fmt.Errorf("project context not initialized")
+	}
+	metas, err := generateMeta(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	adapters, ok := ctx.Value(dbaAdapterKey).([]string)
+	if !ok || len(adapters) == 0 {
+		return nil, nil, fmt.Errorf("no database adapters are configured or detected")
+	}
+	usage := scanUsage(project.Pkgs, internal.ToolModulePath()+"/sqlx", fieldPackages(project, metas))
+	now := time.Now()
+	var (
+		written []string
+		issues  []Issue
+	)
+	for i, adapter := range adapters {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "-- Code generated by dvo xql index. DO NOT EDIT.\n-- Generated at: %s\n", now.Format("2006-01-02 15:04:05"))
+		for _, meta := range metas {
+			table := tableFor(meta, adapter)
+			advice := adviseIndexes(table, usage, minUses)
+			writeIndexScript(&buf, adapter, table.Name, advice)
+			for _, a := range advice {
+				column := a.Index.Columns[0]
+				// the indexes are the same for all adapters; report them once
+				if i > 0 {
+					continue
+				}
+				if !a.Tagged {
+					issues = append(issues, Issue{a.Usage.Pos[0], fmt.Sprintf("%s.%s is %s but not indexed; tag it `xql:\"index\"`", table.Name, column, a.Usage)})
+				} else if a.unused() {
+					f, _ := lo.Find(meta.Fields, func(f Field) bool { return f.Name == column })
+					issues = append(issues, Issue{meta.position(f.Pos), fmt.Sprintf("index %s on %s (%s) is not used by any query", a.Index.Name, table.Name, strings.Join(a.Index.Columns, ", "))})
+				}
+			}
+		}
+		dir := filepath.Join(project.GenPath(), "indexes", adapter)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create output directory %s: %w", dir, err)
+		}
+		output := filepath.Join(dir, "indexes.sql")
+		if err := os.WriteFile(output, buf.Bytes(), 0644); err != nil {
+			return nil, nil, fmt.Errorf("failed to write index script %s: %w", output, err)
+		}
+		written = append(written, output)
+	}
+	return written, issues, nil
+}
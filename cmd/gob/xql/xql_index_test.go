@@ -0,0 +1,139 @@
+package xql
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/kcmvp/xql/cmd/internal/ddl"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+func TestScanUsage(t *testing.T) {
+	sources := map[string]string{
+		"example.com/xql/sqlx": `package sqlx
+type Where func()
+type Join struct{}
+func Eq(field any, value any) Where { return nil }
+func In(field any, values ...any) Where { return nil }
+func Not(where Where) Where { return nil }
+func InnerJoin(left, right any) Join { return Join{} }
+func (j Join) LeftJoin(left, right any) Join { return j }`,
+		"example.com/app/gen/field/account": `package account
+var ID, Email, Name any`,
+		"example.com/app/gen/field/order": `package order
+var ID, AccountID any`,
+		"example.com/app/service": `package service
+import (
+	"example.com/xql/sqlx"
+	"example.com/app/gen/field/account"
+	. "example.com/app/gen/field/order"
+)
+var name = account.Name
+func queries() {
+	sqlx.Eq(account.Email, 1)
+	sqlx.In((account.Email), 1, 2)
+	sqlx.Not(sqlx.Eq(AccountID, 1))
+	sqlx.InnerJoin(AccountID, account.ID).LeftJoin(ID, account.ID)
+	sqlx.Eq(name, 1)
+}`,
+	}
+	fset := token.NewFileSet()
+	checked := map[string]*types.Package{}
+	var service *packages.Package
+	var check importerFunc
+	check = func(path string) (*types.Package, error) {
+		if pkg, ok := checked[path]; ok {
+			return pkg, nil
+		}
+		file, err := parser.ParseFile(fset, path+".go", sources[path], 0)
+		require.NoError(t, err)
+		info := &types.Info{Uses: map[*ast.Ident]types.Object{}}
+		pkg, err := (&types.Config{Importer: check}).Check(path, fset, []*ast.File{file}, info)
+		require.NoError(t, err)
+		checked[path] = pkg
+		if path == "example.com/app/service" {
+			service = &packages.Package{PkgPath: path, Fset: fset, Syntax: []*ast.File{file}, TypesInfo: info}
+		}
+		return pkg, nil
+	}
+	_, err := check("example.com/app/service")
+	require.NoError(t, err)
+
+	accounts := EntityMeta{StructName: "Account", TableName: "accounts", Fields: []Field{
+		{Name: "id", GoName: "ID"}, {Name: "email", GoName: "Email"}, {Name: "name", GoName: "Name"},
+	}}
+	orders := EntityMeta{StructName: "Order", TableName: "orders", Fields: []Field{
+		{Name: "id", GoName: "ID"}, {Name: "account_id", GoName: "AccountID"},
+	}}
+	usage := scanUsage([]*packages.Package{service}, "example.com/xql/sqlx", map[string]EntityMeta{
+		"example.com/app/gen/field/account": accounts,
+		"example.com/app/gen/field/order":   orders,
+	})
+	require.Len(t, usage, 4)
+	email := usage[columnRef{"accounts", "email"}]
+	require.Equal(t, 2, email.Filters)
+	require.Equal(t, []int{9, 10}, []int{email.Pos[0].Line, email.Pos[1].Line})
+	require.Equal(t, 2, usage[columnRef{"accounts", "id"}].Joins)
+	accountID := usage[columnRef{"orders", "account_id"}]
+	require.Equal(t, []int{1, 1}, []int{accountID.Filters, accountID.Joins})
+	require.Equal(t, 1, usage[columnRef{"orders", "id"}].Joins)
+	// fields passed through variables are not followed
+	require.Nil(t, usage[columnRef{"accounts", "name"}])
+}
+
+func TestAdviseIndexes(t *testing.T) {
+	table := ddl.Table{
+		Name: "orders",
+		Columns: []ddl.Column{
+			{Name: "id", PK: true},
+			{Name: "number", Unique: true},
+			{Name: "account_id"},
+			{Name: "status"},
+			{Name: "created_at"},
+			{Name: "note"},
+		},
+		Indexes: []ddl.Index{
+			{Name: "idx_orders_status", Columns: []string{"status"}},
+			{Name: "idx_orders_created", Columns: []string{"created_at", "status"}},
+			{Name: "uk_orders_note", Columns: []string{"note"}, Unique: true},
+		},
+	}
+	usage := map[columnRef]*columnUsage{
+		{"orders", "id"}:         {Filters: 5},
+		{"orders", "number"}:     {Filters: 3},
+		{"orders", "account_id"}: {Filters: 1, Joins: 2},
+		{"orders", "status"}:     {Filters: 4},
+		{"accounts", "note"}:     {Filters: 4},
+	}
+	advice := adviseIndexes(table, usage, 2)
+	require.Equal(t, []indexAdvice{
+		{Index: table.Indexes[0], Tagged: true, Usage: columnUsage{Filters: 4}},
+		{Index: table.Indexes[1], Tagged: true},
+		{Index: table.Indexes[2], Tagged: true},
+		{Index: ddl.Index{Name: "idx_orders_account_id", Columns: []string{"account_id"}}, Usage: columnUsage{Filters: 1, Joins: 2}},
+	}, advice)
+	require.Equal(t, []bool{false, true, false, false}, []bool{advice[0].unused(), advice[1].unused(), advice[2].unused(), advice[3].unused()})
+
+	// account_id is below the threshold
+	require.Len(t, adviseIndexes(table, usage, 4), 3)
+
+	// only the suggested index is created; the unused one is suggested for dropping
+	var buf bytes.Buffer
+	writeIndexScript(&buf, "mysql", table.Name, advice)
+	require.Equal(t, `
+-- UNUSED: no query filters or joins on orders.created_at; drop it with
+-- DROP INDEX idx_orders_created ON orders;
+
+-- SUGGESTED: orders.account_id is used by 1 filter(s) and 2 join(s) but not indexed
+CREATE INDEX idx_orders_account_id ON orders (account_id);
+`, buf.String())
+}
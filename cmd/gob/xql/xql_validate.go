@@ -0,0 +1,279 @@
+package xql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kcmvp/xql/cmd/internal"
+	"github.com/kcmvp/xql/cmd/internal/ddl"
+	"github.com/samber/lo"
+	"golang.org/x/tools/go/packages"
+)
+
+// Issue is a problem reported by `xql validate` at the declaration it concerns.
+type Issue struct {
+	Pos     token.Position
+	Message string
+}
+
+func (i Issue) String() string {
+	if !i.Pos.IsValid() {
+		return i.Message
+	}
+	return fmt.Sprintf("%s: %s", i.Pos, i.Message)
+}
+
+var (
+	directiveValues = map[string]bool{
+		"pk": false, "not null": false, "unique": false, "index": false,
+		"name": true, "type": true, "default": true, "fk": true, "on delete": true, "on update": true,
+	}
+	referentialActions = []string{"CASCADE", "SET NULL", "SET DEFAULT", "RESTRICT", "NO ACTION"}
+	fkTarget           = regexp.MustCompile(`^\w+\.\w+$`)
+	versionHeader      = regexp.MustCompile(`\(ver: ([0-9a-zA-Z]+)\)`)
+)
+
+// lintTag reports the malformed directives of an xql struct tag.
+func lintTag(tag string) []string {
+	if strings.TrimSpace(tag) == "-" {
+		return nil
+	}
+	var problems []string
+	keys := map[string]bool{}
+	for _, d := range strings.Split(tag, ";") {
+		if d = strings.TrimSpace(d); d == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(d, ":")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		required, ok := directiveValues[key]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("unknown directive %q", d))
+			continue
+		case required && value == "":
+			problems = append(problems, fmt.Sprintf("directive %q requires a value", key))
+			continue
+		case key == "fk" && !fkTarget.MatchString(value):
+			problems = append(problems, fmt.Sprintf("fk %q must be <table>.<column>", value))
+		case (key == "on delete" || key == "on update") && !slices.Contains(referentialActions, strings.ToUpper(strings.Join(strings.Fields(value), " "))):
+			problems = append(problems, fmt.Sprintf("unknown %s action %q", key, value))
+		}
+		keys[key] = true
+	}
+	for _, key := range []string{"on delete", "on update"} {
+		if keys[key] && !keys["fk"] {
+			problems = append(problems, fmt.Sprintf("directive %q without fk", key))
+		}
+	}
+	return problems
+}
+
+// position resolves a position of the entity's package.
+func (meta EntityMeta) position(pos token.Pos) token.Position {
+	if meta.Pkg == nil || meta.Pkg.Fset == nil {
+		return token.Position{}
+	}
+	return meta.Pkg.Fset.Position(pos)
+}
+
+// lintFields reports the fields parseFields rejects or skips silently: fields
+// of an unsupported type, and struct fields other than time.Time which are not
+// ignored with `xql:"-"`.
+func lintFields(pkg *packages.Package, spec *ast.TypeSpec) []Issue {
+	structType, ok := spec.Type.(*ast.StructType)
+	if !ok || pkg.TypesInfo == nil {
+		return nil
+	}
+	var issues []Issue
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			if ident, ok := field.Type.(*ast.Ident); ok && ident.Obj != nil {
+				if embedded, ok := ident.Obj.Decl.(*ast.TypeSpec); ok {
+					issues = append(issues, lintFields(pkg, embedded)...)
+				}
+			}
+			continue
+		}
+		if !field.Names[0].IsExported() {
+			continue
+		}
+		if field.Tag != nil && reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("xql") == "-" {
+			continue
+		}
+		tv, ok := pkg.TypesInfo.Types[field.Type]
+		if !ok {
+			continue
+		}
+		_, isStruct := tv.Type.Underlying().(*types.Struct)
+		switch {
+		case isStruct && tv.Type.String() != "time.Time":
+			issues = append(issues, Issue{pkg.Fset.Position(field.Pos()), fmt.Sprintf("field %s of struct type %s is not mapped to a column; tag it `xql:\"-\"`", field.Names[0].Name, tv.Type)})
+		case !isStruct && !isSupportedType(tv.Type):
+			issues = append(issues, Issue{pkg.Fset.Position(field.Pos()), fmt.Sprintf("unsupported field type %s for field %s", tv.Type, field.Names[0].Name)})
+		}
+	}
+	return issues
+}
+
+// lintMetas reports the problems of the entity definitions: malformed tags,
+// duplicate column names, foreign keys to unknown tables or columns and the
+// primary key warnings of the adapters.
+func lintMetas(metas []EntityMeta, adapters []string) []Issue {
+	tables := lo.SliceToMap(metas, func(m EntityMeta) (string, EntityMeta) { return m.TableName, m })
+	var issues []Issue
+	for _, meta := range metas {
+		columns := map[string]Field{}
+		for _, f := range meta.Fields {
+			pos := meta.position(f.Pos)
+			for _, problem := range lintTag(f.Tag) {
+				issues = append(issues, Issue{pos, fmt.Sprintf("%s.%s: %s", meta.StructName, f.GoName, problem)})
+			}
+			if other, ok := columns[f.Name]; ok {
+				issues = append(issues, Issue{pos, fmt.Sprintf("%s.%s: column %q is also mapped by %s", meta.StructName, f.GoName, f.Name, other.GoName)})
+			} else {
+				columns[f.Name] = f
+			}
+			if f.FKTable != "" {
+				if target, ok := tables[f.FKTable]; !ok {
+					issues = append(issues, Issue{pos, fmt.Sprintf("%s.%s: fk table %q is not an entity table", meta.StructName, f.GoName, f.FKTable)})
+				} else if !slices.ContainsFunc(target.Fields, func(tf Field) bool { return tf.Name == f.FKColumn }) {
+					issues = append(issues, Issue{pos, fmt.Sprintf("%s.%s: fk column %s.%s does not exist", meta.StructName, f.GoName, f.FKTable, f.FKColumn)})
+				}
+			}
+			if f.OnDelete == "SET NULL" && (f.IsNotNull || f.IsPK) {
+				issues = append(issues, Issue{pos, fmt.Sprintf("%s.%s: on delete set null on a not null column", meta.StructName, f.GoName)})
+			}
+		}
+		var warnings []string
+		for _, adapter := range adapters {
+			for _, f := range enrichFieldsForAdapter(meta.Fields, adapter) {
+				if f.Warning != "" && !slices.Contains(warnings, f.GoName+f.Warning) {
+					warnings = append(warnings, f.GoName+f.Warning)
+					issues = append(issues, Issue{meta.position(f.Pos), fmt.Sprintf("%s.%s: %s", meta.StructName, f.GoName, f.Warning)})
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// fileVersion returns the version stamped in the header of a generated file.
+func fileVersion(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if match := versionHeader.FindSubmatch(data); match != nil {
+		return string(match[1]), nil
+	}
+	return "", nil
+}
+
+// lintGenerated reports the entities whose generated field helpers or schemas
+// are missing or older than the entity version. A schema recorded in the
+// snapshot is compared with the entity instead, since `xql diff` moves the
+// snapshot forward and leaves the CREATE TABLE script untouched.
+func lintGenerated(project *internal.Project, metas []EntityMeta, adapters []string) []Issue {
+	var issues []Issue
+	stale := func(meta EntityMeta, format string, args ...any) {
+		issues = append(issues, Issue{meta.position(meta.TypeSpec.Pos()), meta.StructName + ": " + fmt.Sprintf(format, args...)})
+	}
+	snapshots := map[string]map[string]ddl.Table{}
+	for _, adapter := range adapters {
+		tables, err := ddl.ReadSnapshot(filepath.Join(schemaDir(project, adapter), snapshotFile))
+		if err != nil {
+			issues = append(issues, Issue{Message: err.Error()})
+		}
+		snapshots[adapter] = tables
+	}
+	for _, meta := range metas {
+		version := computeEntityVersion(meta)
+		pkg := strings.ToLower(meta.StructName)
+		helpers := filepath.Join(project.GenPath(), "field", pkg, pkg+"_gen.go")
+		if v, err := fileVersion(helpers); errors.Is(err, os.ErrNotExist) {
+			stale(meta, "field helpers are not generated; run `gob xql schema`")
+		} else if v != version {
+			stale(meta, "field helpers %s are stale (ver: %s, entity ver: %s); run `gob xql schema`", helpers, v, version)
+		}
+		for _, adapter := range adapters {
+			if previous, ok := snapshots[adapter][meta.TableName]; ok {
+				if len(ddl.Diff(adapter, previous, tableFor(meta, adapter))) > 0 {
+					stale(meta, "%s schema of table %s has changed since the last migration; run `gob xql diff`", adapter, meta.TableName)
+				}
+				continue
+			}
+			script := filepath.Join(schemaDir(project, adapter), lo.SnakeCase(meta.StructName)+"_schema.sql")
+			if v, err := fileVersion(script); errors.Is(err, os.ErrNotExist) {
+				stale(meta, "%s schema is not generated; run `gob xql schema`", adapter)
+			} else if v != version {
+				stale(meta, "%s schema %s is stale (ver: %s, entity ver: %s); run `gob xql schema`", adapter, script, v, version)
+			}
+		}
+	}
+	return issues
+}
+
+// validate lints all the entities of the project and their generated files,
+// returning the issues sorted by position.
+func validate(ctx context.Context) ([]Issue, error) {
+	project := internal.Current
+	if project == nil {
+		return nil, fmt.Errorf("project context not initialized")
+	}
+	adapters, _ := ctx.Value(dbaAdapterKey).([]string)
+	var (
+		issues []Issue
+		metas  []EntityMeta
+	)
+	for _, info := range project.StructsImplementEntity() {
+		structName := info.TypeSpec.Name.Name
+		fieldIssues := lintFields(info.Pkg, info.TypeSpec)
+		issues = append(issues, fieldIssues...)
+		fields, err := parseFields(info.Pkg, info.TypeSpec, "")
+		if err != nil {
+			if len(fieldIssues) == 0 {
+				issues = append(issues, Issue{info.Pkg.Fset.Position(info.TypeSpec.Pos()), fmt.Sprintf("%s: %s", structName, err)})
+			}
+			continue
+		}
+		tableName, err := resolveTableName(project, info.PkgPath, structName)
+		if err != nil {
+			issues = append(issues, Issue{info.Pkg.Fset.Position(info.TypeSpec.Pos()), fmt.Sprintf("%s: %s", structName, err)})
+			continue
+		}
+		metas = append(metas, EntityMeta{
+			StructName: structName,
+			PkgPath:    info.PkgPath,
+			Pkg:        info.Pkg,
+			TypeSpec:   info.TypeSpec,
+			TableName:  tableName,
+			Fields:     applyOrderPolicy(fields),
+		})
+	}
+	issues = append(issues, lintMetas(metas, adapters)...)
+	// cyclic foreign keys are valid; they are deferred by the generated schemas
+	_, warnings := orderByReferences(metas)
+	for _, warning := range warnings {
+		color.Yellow("warning: %s", warning)
+	}
+	issues = append(issues, lintGenerated(project, metas, adapters)...)
+	slices.SortStableFunc(issues, func(a, b Issue) int {
+		if c := strings.Compare(a.Pos.Filename, b.Pos.Filename); c != 0 {
+			return c
+		}
+		return a.Pos.Line - b.Pos.Line
+	})
+	return issues, nil
+}
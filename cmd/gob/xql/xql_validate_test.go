@@ -0,0 +1,60 @@
+package xql
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want []string
+	}{
+		{tag: "-"},
+		{tag: "pk"},
+		{tag: "name:nick_name;type:varchar(100);unique;not null;default:'anonymous'"},
+		{tag: "index;unique:account_role;fk:accounts.id;on delete:set null;on update:cascade"},
+		{tag: "primary;idx", want: []string{`unknown directive "primary"`, `unknown directive "idx"`}},
+		{tag: "name:;type", want: []string{`directive "name" requires a value`, `directive "type" requires a value`}},
+		{tag: "fk:accounts", want: []string{`fk "accounts" must be <table>.<column>`}},
+		{tag: "fk:accounts.id;on delete:drop", want: []string{`unknown on delete action "drop"`}},
+		{tag: "on update:cascade", want: []string{`directive "on update" without fk`}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.tag, func(t *testing.T) {
+			require.Equal(t, tc.want, lintTag(tc.tag))
+		})
+	}
+}
+
+func TestLintMetas(t *testing.T) {
+	field := func(goName, goType, tag string) Field {
+		f := Field{GoName: goName, GoType: goType, Name: lo.SnakeCase(goName), Tag: tag}
+		parseDirectives(tag, &f)
+		return f
+	}
+	accounts := EntityMeta{StructName: "Account", TableName: "accounts", Fields: []Field{
+		field("ID", "int8", "pk"),
+		field("Email", "string", "unique"),
+		field("Mail", "string", "name:email;uniq"),
+	}}
+	orders := EntityMeta{StructName: "Order", TableName: "orders", Fields: []Field{
+		field("ID", "int64", "pk"),
+		field("AccountID", "int64", "not null;fk:accounts.id;on delete:set null"),
+		field("OwnerID", "int64", "fk:accounts.uid"),
+		field("ShopID", "int64", "fk:shops.id"),
+	}}
+	messages := lo.Map(lintMetas([]EntityMeta{accounts, orders}, []string{"sqlite", "postgres"}), func(i Issue, _ int) string {
+		return i.String()
+	})
+	require.Equal(t, []string{
+		`Account.Mail: unknown directive "uniq"`,
+		`Account.Mail: column "email" is also mapped by Email`,
+		"Account.ID: primary key defined on int8: small integer PKs are discouraged",
+		"Order.AccountID: on delete set null on a not null column",
+		"Order.OwnerID: fk column accounts.uid does not exist",
+		`Order.ShopID: fk table "shops" is not an entity table`,
+	}, messages)
+}
@@ -0,0 +1,488 @@
+// Package ddl models table definitions and renders the per-dialect DDL that
+// creates a table or moves it from one definition to another.
+//
+// Definitions come from the entity metadata of the generator, from a snapshot
+// of the previous generation or from a live database (see Introspect). The
+// dialects are the adapter names of drivers.json: sqlite, mysql and postgres.
+package ddl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+const (
+	SQLite   = "sqlite"
+	MySQL    = "mysql"
+	Postgres = "postgres"
+)
+
+// Column is a table column. Default is the SQL literal or expression of the
+// column default, e.g. 'anonymous' or 0.
+type Column struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	PK      bool   `json:"pk,omitempty"`
+	NotNull bool   `json:"notNull,omitempty"`
+	Unique  bool   `json:"unique,omitempty"`
+	Default string `json:"default,omitempty"`
+}
+
+// Index is a secondary index; single column UNIQUE constraints are recorded
+// on the Column instead.
+type Index struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+}
+
+// ForeignKey is a FOREIGN KEY constraint of a column. OnDelete and OnUpdate
+// hold the referential actions, e.g. CASCADE or SET NULL; empty means the
+// database default (NO ACTION).
+type ForeignKey struct {
+	Name      string `json:"name"`
+	Column    string `json:"column"`
+	RefTable  string `json:"refTable"`
+	RefColumn string `json:"refColumn"`
+	OnDelete  string `json:"onDelete,omitempty"`
+	OnUpdate  string `json:"onUpdate,omitempty"`
+}
+
+// Table is a table definition. A primary key spanning several columns is
+// rendered as a table constraint.
+type Table struct {
+	Name        string       `json:"name"`
+	Columns     []Column     `json:"columns"`
+	Indexes     []Index      `json:"indexes,omitempty"`
+	ForeignKeys []ForeignKey `json:"foreignKeys,omitempty"`
+}
+
+// Column returns the column with the given name.
+func (t Table) Column(name string) (Column, bool) {
+	return lo.Find(t.Columns, func(c Column) bool { return c.Name == name })
+}
+
+// Index returns the index with the given name.
+func (t Table) Index(name string) (Index, bool) {
+	return lo.Find(t.Indexes, func(i Index) bool { return i.Name == name })
+}
+
+// ForeignKey returns the foreign key with the given name.
+func (t Table) ForeignKey(name string) (ForeignKey, bool) {
+	return lo.Find(t.ForeignKeys, func(fk ForeignKey) bool { return fk.Name == name })
+}
+
+// ForeignKeyName returns the name of the foreign key constraint of a column.
+func ForeignKeyName(table, column string) string {
+	return fmt.Sprintf("fk_%s_%s", table, column)
+}
+
+// References returns the tables referenced by the foreign keys of t, other than t itself.
+func (t Table) References() []string {
+	return lo.Without(lo.Uniq(lo.Map(t.ForeignKeys, func(fk ForeignKey, _ int) string { return fk.RefTable })), t.Name)
+}
+
+// pk returns the primary key columns.
+func (t Table) pk() []string {
+	return lo.FilterMap(t.Columns, func(c Column, _ int) (string, bool) { return c.Name, c.PK })
+}
+
+// CreateTable renders the CREATE TABLE statement of t followed by its indexes.
+func CreateTable(dialect string, t Table) []string {
+	return append([]string{createTable(t.Name, t)}, lo.Map(t.Indexes, func(idx Index, _ int) string {
+		return CreateIndex(dialect, t.Name, idx)
+	})...)
+}
+
+func createTable(name string, t Table) string {
+	pk := t.pk()
+	defs := lo.Map(t.Columns, func(c Column, _ int) string {
+		c.PK = c.PK && len(pk) == 1
+		return "    " + columnDef(c, true)
+	})
+	if len(pk) > 1 {
+		defs = append(defs, fmt.Sprintf("    PRIMARY KEY (%s)", strings.Join(pk, ", ")))
+	}
+	for _, fk := range t.ForeignKeys {
+		defs = append(defs, "    "+foreignKeyDef(fk))
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n)", name, strings.Join(defs, ",\n"))
+}
+
+func foreignKeyDef(fk ForeignKey) string {
+	def := fmt.Sprintf("CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)", fk.Name, fk.Column, fk.RefTable, fk.RefColumn)
+	if fk.OnDelete != "" {
+		def += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		def += " ON UPDATE " + fk.OnUpdate
+	}
+	return def
+}
+
+// AddForeignKey renders the ALTER TABLE statement adding fk to table, e.g. a
+// foreign key deferred until the table it references exists.
+func AddForeignKey(table string, fk ForeignKey) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD %s", table, foreignKeyDef(fk))
+}
+
+func dropForeignKey(dialect, table string, fk ForeignKey) string {
+	if dialect == MySQL {
+		return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", table, fk.Name)
+	}
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, fk.Name)
+}
+
+// columnDef renders a column definition; the PRIMARY KEY and UNIQUE
+// constraints are rendered only when constraints is true.
+func columnDef(c Column, constraints bool) string {
+	var b strings.Builder
+	b.WriteString(c.Name + " " + c.Type)
+	if constraints && c.PK {
+		b.WriteString(" PRIMARY KEY")
+	}
+	if c.NotNull {
+		b.WriteString(" NOT NULL")
+	}
+	if constraints && c.Unique {
+		b.WriteString(" UNIQUE")
+	}
+	if c.Default != "" {
+		b.WriteString(" DEFAULT " + c.Default)
+	}
+	return b.String()
+}
+
+// CreateIndex renders the CREATE INDEX statement of idx on table.
+func CreateIndex(dialect, table string, idx Index) string {
+	unique := lo.Ternary(idx.Unique, "UNIQUE ", "")
+	// MySQL does not support IF NOT EXISTS on CREATE INDEX.
+	ifNotExists := lo.Ternary(dialect == MySQL, "", "IF NOT EXISTS ")
+	return fmt.Sprintf("CREATE %sINDEX %s%s ON %s (%s)", unique, ifNotExists, idx.Name, table, strings.Join(idx.Columns, ", "))
+}
+
+// DropIndex renders the DROP INDEX statement of idx on table.
+func DropIndex(dialect, table string, idx Index) string {
+	if dialect == MySQL {
+		return fmt.Sprintf("DROP INDEX %s ON %s", idx.Name, table)
+	}
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s", idx.Name)
+}
+
+// Diff renders the statements that change table from into table to; both must
+// have the same name. Statements that lose data or may fail on existing rows
+// are preceded by a "-- WARNING:" comment so the migration can be reviewed.
+//
+// SQLite can only add and drop plain columns in place; any other column change
+// rebuilds the table: the new table is created, the common columns are copied
+// and the old table is replaced, with foreign keys turned off so that dropping
+// it neither cascades to nor fails on the referencing rows.
+func Diff(dialect string, from, to Table) []string {
+	if dialect == SQLite && !sqliteInPlace(from, to) {
+		return rebuild(from, to)
+	}
+	t := to.Name
+	var stmts []string
+	add := func(stmt string, warning ...string) {
+		if len(warning) > 0 {
+			stmt = fmt.Sprintf("-- WARNING: %s\n%s", warning[0], stmt)
+		}
+		stmts = append(stmts, stmt)
+	}
+
+	// 1. foreign keys and indexes that were removed or changed
+	for _, fk := range from.ForeignKeys {
+		if other, ok := to.ForeignKey(fk.Name); !ok || !sameForeignKey(dialect, fk, other) {
+			add(dropForeignKey(dialect, t, fk))
+		}
+	}
+	for _, idx := range from.Indexes {
+		if other, ok := to.Index(idx.Name); !ok || !sameIndex(idx, other) {
+			add(DropIndex(dialect, t, idx))
+		}
+	}
+	// 2. unique constraints that were removed
+	for _, c := range from.Columns {
+		if other, ok := to.Column(c.Name); ok && c.Unique && !other.Unique {
+			add(dropUnique(dialect, t, c.Name))
+		}
+	}
+	// 3. the primary key, when it changes
+	pkChanged := !slices.Equal(from.pk(), to.pk())
+	if pkChanged && len(from.pk()) > 0 {
+		add(dropPK(dialect, t))
+	}
+	// 4. dropped columns
+	for _, c := range from.Columns {
+		if _, ok := to.Column(c.Name); !ok {
+			add(fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", t, c.Name), fmt.Sprintf("drops the data of %s.%s", t, c.Name))
+		}
+	}
+	// 5. added columns
+	for _, c := range to.Columns {
+		if _, ok := from.Column(c.Name); ok {
+			continue
+		}
+		stmt := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", t, columnDef(c, dialect == SQLite))
+		if c.NotNull && c.Default == "" {
+			add(stmt, fmt.Sprintf("%s.%s is NOT NULL without a default and fails on a non-empty table", t, c.Name))
+		} else {
+			add(stmt)
+		}
+	}
+	// 6. changed columns
+	for _, c := range to.Columns {
+		if old, ok := from.Column(c.Name); ok {
+			stmts = append(stmts, alterColumn(dialect, t, old, c)...)
+		}
+	}
+	// 7. the new primary key
+	if pkChanged && len(to.pk()) > 0 {
+		add(fmt.Sprintf("ALTER TABLE %s ADD PRIMARY KEY (%s)", t, strings.Join(to.pk(), ", ")))
+	}
+	// 8. unique constraints that were added
+	for _, c := range to.Columns {
+		old, ok := from.Column(c.Name)
+		if c.Unique && (!ok || !old.Unique) && !(dialect == SQLite && !ok) {
+			add(addUnique(dialect, t, c.Name), fmt.Sprintf("fails when %s.%s holds duplicates", t, c.Name))
+		}
+	}
+	// 9. indexes and foreign keys that were added or changed
+	for _, idx := range to.Indexes {
+		if other, ok := from.Index(idx.Name); !ok || !sameIndex(idx, other) {
+			add(CreateIndex(dialect, t, idx), lo.Ternary(idx.Unique, []string{fmt.Sprintf("fails when %s (%s) holds duplicates", t, strings.Join(idx.Columns, ", "))}, nil)...)
+		}
+	}
+	for _, fk := range to.ForeignKeys {
+		if other, ok := from.ForeignKey(fk.Name); !ok || !sameForeignKey(dialect, fk, other) {
+			add(AddForeignKey(t, fk), fmt.Sprintf("fails when %s.%s holds values missing from %s.%s", t, fk.Column, fk.RefTable, fk.RefColumn))
+		}
+	}
+	return stmts
+}
+
+// alterColumn renders the changes of a column that exists in both tables.
+func alterColumn(dialect, t string, old, c Column) []string {
+	typeChanged := !SameType(dialect, old.Type, c.Type)
+	nullChanged := !c.PK && old.NotNull != c.NotNull
+	defaultChanged := !SameDefault(old.Default, c.Default)
+	if !typeChanged && !nullChanged && !defaultChanged {
+		return nil
+	}
+	warning := func(stmt string) string {
+		if typeChanged {
+			return fmt.Sprintf("-- WARNING: changes the type of %s.%s from %s to %s\n%s", t, c.Name, old.Type, c.Type, stmt)
+		}
+		return stmt
+	}
+	if dialect == MySQL {
+		if !typeChanged && !nullChanged {
+			return []string{setDefault(t, c)}
+		}
+		return []string{warning(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", t, columnDef(c, false)))}
+	}
+	var stmts []string
+	if typeChanged {
+		stmts = append(stmts, warning(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s", t, c.Name, c.Type, c.Name, c.Type)))
+	}
+	if nullChanged {
+		stmts = append(stmts, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s NOT NULL", t, c.Name, lo.Ternary(c.NotNull, "SET", "DROP")))
+	}
+	if defaultChanged {
+		stmts = append(stmts, setDefault(t, c))
+	}
+	return stmts
+}
+
+func setDefault(t string, c Column) string {
+	if c.Default == "" {
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT", t, c.Name)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s", t, c.Name, c.Default)
+}
+
+// dropUnique drops the UNIQUE constraint declared inline on a column, under the
+// name the database gave it: <table>_<column>_key on postgres, <column> on mysql.
+func dropUnique(dialect, t, column string) string {
+	if dialect == MySQL {
+		return fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", t, column)
+	}
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s_%s_key", t, t, column)
+}
+
+func addUnique(dialect, t, column string) string {
+	if dialect == MySQL {
+		return fmt.Sprintf("ALTER TABLE %s ADD UNIQUE (%s)", t, column)
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s_%s_key UNIQUE (%s)", t, t, column, column)
+}
+
+func dropPK(dialect, t string) string {
+	if dialect == MySQL {
+		return fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY", t)
+	}
+	return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s_pkey", t, t)
+}
+
+// sqliteInPlace reports whether SQLite can apply the change with ALTER TABLE:
+// only plain columns are added or dropped, and indexes change.
+func sqliteInPlace(from, to Table) bool {
+	if !slices.Equal(from.pk(), to.pk()) || len(from.ForeignKeys) != len(to.ForeignKeys) {
+		return false
+	}
+	for _, fk := range from.ForeignKeys {
+		if other, ok := to.ForeignKey(fk.Name); !ok || !sameForeignKey(SQLite, fk, other) {
+			return false
+		}
+	}
+	indexed := lo.FlatMap(from.Indexes, func(idx Index, _ int) []string { return idx.Columns })
+	for _, c := range from.Columns {
+		other, ok := to.Column(c.Name)
+		switch {
+		case !ok && (c.PK || c.Unique || slices.Contains(indexed, c.Name)):
+			return false
+		case ok && (!SameType(SQLite, c.Type, other.Type) || (c.NotNull != other.NotNull && !other.PK) || c.Unique != other.Unique || !SameDefault(c.Default, other.Default)):
+			return false
+		}
+	}
+	for _, c := range to.Columns {
+		if _, ok := from.Column(c.Name); !ok && (c.PK || c.Unique || (c.NotNull && c.Default == "")) {
+			return false
+		}
+	}
+	return true
+}
+
+// rebuild renders the SQLite table rebuild from the table from into to,
+// following the procedure of https://www.sqlite.org/lang_altertable.html:
+// foreign keys are off during the rebuild and checked before they are turned
+// back on. The pragma has no effect inside a transaction, so sqlx.Migrate runs
+// such a script in a transaction of its own between the pragmas.
+func rebuild(from, to Table) []string {
+	t := to.Name
+	tmp := t + "__new"
+	common := lo.FilterMap(to.Columns, func(c Column, _ int) (string, bool) {
+		_, ok := from.Column(c.Name)
+		return c.Name, ok
+	})
+	stmts := []string{
+		"PRAGMA foreign_keys=OFF",
+		fmt.Sprintf("-- WARNING: rebuilds %s to change its columns; dropped columns lose their data\n%s", t, createTable(tmp, to)),
+	}
+	if len(common) > 0 {
+		cols := strings.Join(common, ", ")
+		stmts = append(stmts, fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s", tmp, cols, cols, t))
+	}
+	stmts = append(stmts,
+		fmt.Sprintf("DROP TABLE %s", t),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tmp, t),
+	)
+	stmts = append(stmts, lo.Map(to.Indexes, func(idx Index, _ int) string {
+		return CreateIndex(SQLite, t, idx)
+	})...)
+	return append(stmts, "PRAGMA foreign_key_check", "PRAGMA foreign_keys=ON")
+}
+
+func sameIndex(a, b Index) bool {
+	return a.Unique == b.Unique && slices.Equal(a.Columns, b.Columns)
+}
+
+func sameForeignKey(dialect string, a, b ForeignKey) bool {
+	return a.Column == b.Column && a.RefTable == b.RefTable && a.RefColumn == b.RefColumn &&
+		SameAction(dialect, a.OnDelete, b.OnDelete) && SameAction(dialect, a.OnUpdate, b.OnUpdate)
+}
+
+// SameAction reports whether two referential actions are the same for the
+// dialect; the default NO ACTION equals an empty action, and so does RESTRICT
+// on MySQL where both behave alike.
+func SameAction(dialect, a, b string) bool {
+	norm := func(s string) string {
+		s = strings.ToUpper(strings.Join(strings.Fields(s), " "))
+		if s == "NO ACTION" || (dialect == MySQL && s == "RESTRICT") {
+			return ""
+		}
+		return s
+	}
+	return norm(a) == norm(b)
+}
+
+var (
+	spaces     = regexp.MustCompile(`\s+`)
+	parenSpace = regexp.MustCompile(`\s*([(),])\s*`)
+	intWidth   = regexp.MustCompile(`^((?:big|small|medium)?int)\(\d+\)`)
+	typeAlias  = map[string]map[string]string{
+		Postgres: {
+			"int": "integer", "int4": "integer", "int8": "bigint", "int2": "smallint",
+			"bool": "boolean", "float8": "double precision", "float4": "real",
+			"timestamptz": "timestamp with time zone", "character varying": "varchar",
+		},
+		MySQL: {"integer": "int", "bool": "tinyint(1)", "boolean": "tinyint(1)"},
+	}
+)
+
+// normalizeType lower-cases a column type, collapses blanks and resolves the
+// aliases of the dialect, e.g. int4 and integer on postgres.
+func normalizeType(dialect, typ string) string {
+	typ = strings.ToLower(strings.TrimSpace(spaces.ReplaceAllString(typ, " ")))
+	typ = parenSpace.ReplaceAllString(typ, "$1")
+	if dialect == MySQL && !strings.HasPrefix(typ, "tinyint(1)") {
+		typ = intWidth.ReplaceAllString(typ, "$1")
+	}
+	if alias, ok := typeAlias[dialect][typ]; ok {
+		return alias
+	}
+	return typ
+}
+
+// SameType reports whether two column types are the same for the dialect.
+func SameType(dialect, a, b string) bool {
+	return normalizeType(dialect, a) == normalizeType(dialect, b)
+}
+
+// SameDefault reports whether two column defaults are the same, ignoring the
+// quotes of literals and postgres casts such as 'a'::text.
+func SameDefault(a, b string) bool {
+	norm := func(s string) string {
+		if i := strings.Index(s, "::"); i > 0 {
+			s = s[:i]
+		}
+		return strings.ToLower(strings.Trim(strings.TrimSpace(s), "'"))
+	}
+	return norm(a) == norm(b)
+}
+
+// ReadSnapshot reads the tables recorded in a snapshot file; a missing file is
+// an empty snapshot.
+func ReadSnapshot(path string) (map[string]Table, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Table{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	tables := map[string]Table{}
+	if err := json.Unmarshal(data, &tables); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", path, err)
+	}
+	return tables, nil
+}
+
+// WriteSnapshot records the tables in a snapshot file.
+func WriteSnapshot(path string, tables map[string]Table) error {
+	data, err := json.MarshalIndent(tables, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,246 @@
+package ddl
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+var accounts = Table{
+	Name: "accounts",
+	Columns: []Column{
+		{Name: "id", Type: "INTEGER", PK: true},
+		{Name: "email", Type: "TEXT", NotNull: true, Unique: true},
+		{Name: "nickname", Type: "TEXT", Default: "'anonymous'"},
+		{Name: "bio", Type: "TEXT"},
+	},
+	Indexes: []Index{{Name: "idx_accounts_nickname", Columns: []string{"nickname"}}},
+}
+
+// evolved adds age, drops bio, changes nickname and replaces its index with one on age.
+func evolved(ageType string) Table {
+	return Table{
+		Name: "accounts",
+		Columns: []Column{
+			{Name: "id", Type: "INTEGER", PK: true},
+			{Name: "email", Type: "TEXT", NotNull: true, Unique: true},
+			{Name: "nickname", Type: "VARCHAR(64)", NotNull: true},
+			{Name: "age", Type: ageType, Default: "0"},
+		},
+		Indexes: []Index{{Name: "idx_accounts_age", Columns: []string{"age"}}},
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		dialect string
+		to      Table
+		want    []string
+	}{
+		{
+			dialect: Postgres,
+			to:      evolved("INTEGER"),
+			want: []string{
+				"DROP INDEX IF EXISTS idx_accounts_nickname",
+				"-- WARNING: drops the data of accounts.bio\nALTER TABLE accounts DROP COLUMN bio",
+				"ALTER TABLE accounts ADD COLUMN age INTEGER DEFAULT 0",
+				"-- WARNING: changes the type of accounts.nickname from TEXT to VARCHAR(64)\nALTER TABLE accounts ALTER COLUMN nickname TYPE VARCHAR(64) USING nickname::VARCHAR(64)",
+				"ALTER TABLE accounts ALTER COLUMN nickname SET NOT NULL",
+				"ALTER TABLE accounts ALTER COLUMN nickname DROP DEFAULT",
+				"CREATE INDEX IF NOT EXISTS idx_accounts_age ON accounts (age)",
+			},
+		},
+		{
+			dialect: MySQL,
+			to:      evolved("INT"),
+			want: []string{
+				"DROP INDEX idx_accounts_nickname ON accounts",
+				"-- WARNING: drops the data of accounts.bio\nALTER TABLE accounts DROP COLUMN bio",
+				"ALTER TABLE accounts ADD COLUMN age INT DEFAULT 0",
+				"-- WARNING: changes the type of accounts.nickname from TEXT to VARCHAR(64)\nALTER TABLE accounts MODIFY COLUMN nickname VARCHAR(64) NOT NULL",
+				"CREATE INDEX idx_accounts_age ON accounts (age)",
+			},
+		},
+		{
+			dialect: SQLite,
+			to:      evolved("INTEGER"),
+			want: []string{
+				"PRAGMA foreign_keys=OFF",
+				"-- WARNING: rebuilds accounts to change its columns; dropped columns lose their data\n" +
+					"CREATE TABLE IF NOT EXISTS accounts__new (\n" +
+					"    id INTEGER PRIMARY KEY,\n" +
+					"    email TEXT NOT NULL UNIQUE,\n" +
+					"    nickname VARCHAR(64) NOT NULL,\n" +
+					"    age INTEGER DEFAULT 0\n)",
+				"INSERT INTO accounts__new (id, email, nickname) SELECT id, email, nickname FROM accounts",
+				"DROP TABLE accounts",
+				"ALTER TABLE accounts__new RENAME TO accounts",
+				"CREATE INDEX IF NOT EXISTS idx_accounts_age ON accounts (age)",
+				"PRAGMA foreign_key_check",
+				"PRAGMA foreign_keys=ON",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.dialect, func(t *testing.T) {
+			require.Equal(t, tc.want, Diff(tc.dialect, accounts, tc.to))
+			require.Empty(t, Diff(tc.dialect, tc.to, tc.to))
+		})
+	}
+}
+
+func TestDiff_Constraints(t *testing.T) {
+	to := Table{
+		Name: "accounts",
+		Columns: []Column{
+			{Name: "id", Type: "BIGINT"},
+			{Name: "email", Type: "TEXT", NotNull: true},
+			{Name: "nickname", Type: "TEXT", Unique: true, Default: "'anonymous'"},
+			{Name: "bio", Type: "TEXT"},
+			{Name: "code", Type: "TEXT", PK: true},
+		},
+		Indexes: accounts.Indexes,
+	}
+	require.Equal(t, []string{
+		"ALTER TABLE accounts DROP CONSTRAINT accounts_email_key",
+		"ALTER TABLE accounts DROP CONSTRAINT accounts_pkey",
+		"ALTER TABLE accounts ADD COLUMN code TEXT",
+		"-- WARNING: changes the type of accounts.id from INTEGER to BIGINT\nALTER TABLE accounts ALTER COLUMN id TYPE BIGINT USING id::BIGINT",
+		"ALTER TABLE accounts ADD PRIMARY KEY (code)",
+		"-- WARNING: fails when accounts.nickname holds duplicates\nALTER TABLE accounts ADD CONSTRAINT accounts_nickname_key UNIQUE (nickname)",
+	}, Diff(Postgres, accounts, to))
+	require.Equal(t, []string{
+		"ALTER TABLE accounts DROP INDEX email",
+		"ALTER TABLE accounts DROP PRIMARY KEY",
+		"ALTER TABLE accounts ADD COLUMN code TEXT",
+		"-- WARNING: changes the type of accounts.id from INTEGER to BIGINT\nALTER TABLE accounts MODIFY COLUMN id BIGINT",
+		"ALTER TABLE accounts ADD PRIMARY KEY (code)",
+		"-- WARNING: fails when accounts.nickname holds duplicates\nALTER TABLE accounts ADD UNIQUE (nickname)",
+	}, Diff(MySQL, accounts, to))
+}
+
+var accountRoles = Table{
+	Name: "account_roles",
+	Columns: []Column{
+		{Name: "account_id", Type: "INTEGER", PK: true},
+		{Name: "role", Type: "TEXT", PK: true},
+		{Name: "granted_by", Type: "INTEGER"},
+	},
+	ForeignKeys: []ForeignKey{
+		{Name: "fk_account_roles_account_id", Column: "account_id", RefTable: "accounts", RefColumn: "id", OnDelete: "CASCADE"},
+		{Name: "fk_account_roles_granted_by", Column: "granted_by", RefTable: "accounts", RefColumn: "id", OnDelete: "SET NULL", OnUpdate: "CASCADE"},
+	},
+	Indexes: []Index{{Name: "uk_account_roles_role_granted_by", Columns: []string{"role", "granted_by"}, Unique: true}},
+}
+
+func TestCreateTable_Constraints(t *testing.T) {
+	require.Equal(t, []string{
+		"CREATE TABLE IF NOT EXISTS account_roles (\n" +
+			"    account_id INTEGER,\n" +
+			"    role TEXT,\n" +
+			"    granted_by INTEGER,\n" +
+			"    PRIMARY KEY (account_id, role),\n" +
+			"    CONSTRAINT fk_account_roles_account_id FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE,\n" +
+			"    CONSTRAINT fk_account_roles_granted_by FOREIGN KEY (granted_by) REFERENCES accounts (id) ON DELETE SET NULL ON UPDATE CASCADE\n)",
+		"CREATE UNIQUE INDEX uk_account_roles_role_granted_by ON account_roles (role, granted_by)",
+	}, CreateTable(MySQL, accountRoles))
+	require.Equal(t, []string{"accounts"}, accountRoles.References())
+}
+
+func TestDiff_ForeignKeys(t *testing.T) {
+	from := accountRoles
+	from.ForeignKeys = []ForeignKey{
+		{Name: "fk_account_roles_account_id", Column: "account_id", RefTable: "accounts", RefColumn: "id", OnDelete: "no action"},
+		{Name: "fk_account_roles_granted_by", Column: "granted_by", RefTable: "accounts", RefColumn: "id", OnUpdate: "CASCADE"},
+	}
+	require.Equal(t, []string{
+		"ALTER TABLE account_roles DROP CONSTRAINT fk_account_roles_account_id",
+		"ALTER TABLE account_roles DROP CONSTRAINT fk_account_roles_granted_by",
+		"-- WARNING: fails when account_roles.account_id holds values missing from accounts.id\n" +
+			"ALTER TABLE account_roles ADD CONSTRAINT fk_account_roles_account_id FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE",
+		"-- WARNING: fails when account_roles.granted_by holds values missing from accounts.id\n" +
+			"ALTER TABLE account_roles ADD CONSTRAINT fk_account_roles_granted_by FOREIGN KEY (granted_by) REFERENCES accounts (id) ON DELETE SET NULL ON UPDATE CASCADE",
+	}, Diff(Postgres, from, accountRoles))
+	// RESTRICT is the MySQL default
+	from.ForeignKeys = accountRoles.ForeignKeys[:1]
+	to := accountRoles
+	to.ForeignKeys = []ForeignKey{{Name: "fk_account_roles_account_id", Column: "account_id", RefTable: "accounts", RefColumn: "id", OnDelete: "CASCADE", OnUpdate: "RESTRICT"}}
+	require.Empty(t, Diff(MySQL, from, to))
+	require.Equal(t, []string{"ALTER TABLE account_roles DROP FOREIGN KEY fk_account_roles_account_id"}, Diff(MySQL, from, Table{Name: "account_roles", Columns: from.Columns, Indexes: from.Indexes}))
+	require.Contains(t, Diff(SQLite, Table{Name: "account_roles", Columns: from.Columns, Indexes: from.Indexes}, from)[1], "CREATE TABLE IF NOT EXISTS account_roles__new")
+}
+
+func TestSameType(t *testing.T) {
+	require.True(t, SameType(Postgres, "INT", "integer"))
+	require.True(t, SameType(Postgres, "character varying", "VARCHAR"))
+	require.True(t, SameType(Postgres, "NUMERIC(10, 2)", "numeric(10,2)"))
+	require.True(t, SameType(MySQL, "BIGINT", "bigint(20)"))
+	require.True(t, SameType(MySQL, "BOOLEAN", "tinyint(1)"))
+	require.False(t, SameType(MySQL, "TINYINT", "tinyint(1)"))
+	require.False(t, SameType(SQLite, "TEXT", "VARCHAR(64)"))
+	require.True(t, SameDefault("'anonymous'::text", "'anonymous'"))
+	require.False(t, SameDefault("0", ""))
+}
+
+func TestIntrospect_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:ddl_test?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	ctx := context.Background()
+	exec := func(stmts []string) {
+		for _, stmt := range stmts {
+			_, err := db.ExecContext(ctx, stmt)
+			require.NoError(t, err, stmt)
+		}
+	}
+
+	_, exists, err := Introspect(ctx, db, SQLite, "accounts")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	exec(CreateTable(SQLite, accounts))
+	_, err = db.ExecContext(ctx, "INSERT INTO accounts (id, email, bio) VALUES (1, 'a@b.c', 'x')")
+	require.NoError(t, err)
+	live, exists, err := Introspect(ctx, db, SQLite, "accounts")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, accounts, live)
+
+	// plain columns are added in place
+	added := live
+	added.Columns = append(append([]Column{}, live.Columns...), Column{Name: "score", Type: "REAL", NotNull: true, Default: "1.5"})
+	require.Equal(t, []string{"ALTER TABLE accounts ADD COLUMN score REAL NOT NULL DEFAULT 1.5"}, Diff(SQLite, live, added))
+	exec(Diff(SQLite, live, added))
+
+	live, _, err = Introspect(ctx, db, SQLite, "accounts")
+	require.NoError(t, err)
+	exec(Diff(SQLite, live, evolved("INTEGER")))
+	live, _, err = Introspect(ctx, db, SQLite, "accounts")
+	require.NoError(t, err)
+	require.Equal(t, evolved("INTEGER"), live)
+	var nickname string
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT nickname FROM accounts WHERE id = 1").Scan(&nickname))
+	require.Equal(t, "anonymous", nickname)
+
+	exec(CreateTable(SQLite, accountRoles))
+	live, exists, err = Introspect(ctx, db, SQLite, "account_roles")
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Empty(t, Diff(SQLite, live, accountRoles))
+	require.Equal(t, []string{"account_id", "role"}, live.pk())
+}
+
+func TestSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sqlite", "snapshot.json")
+	tables, err := ReadSnapshot(path)
+	require.NoError(t, err)
+	require.Empty(t, tables)
+	require.NoError(t, WriteSnapshot(path, map[string]Table{"accounts": accounts}))
+	tables, err = ReadSnapshot(path)
+	require.NoError(t, err)
+	require.Equal(t, map[string]Table{"accounts": accounts}, tables)
+}
@@ -0,0 +1,315 @@
+package ddl
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// Querier is the subset of *sql.DB used by Introspect.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// Introspect reads the definition of a table from a live database. The returned
+// flag is false when the table does not exist.
+func Introspect(ctx context.Context, q Querier, dialect, table string) (Table, bool, error) {
+	var (
+		t   Table
+		err error
+	)
+	switch dialect {
+	case SQLite:
+		t, err = introspectSQLite(ctx, q, table)
+	case MySQL:
+		t, err = introspectMySQL(ctx, q, table)
+	case Postgres:
+		t, err = introspectPostgres(ctx, q, table)
+	default:
+		return Table{}, false, fmt.Errorf("unsupported dialect %q", dialect)
+	}
+	if err != nil {
+		return Table{}, false, fmt.Errorf("introspect %s: %w", table, err)
+	}
+	return t, len(t.Columns) > 0, nil
+}
+
+// query runs a query and scans each row with scan.
+func query(ctx context.Context, q Querier, scan func(*sql.Rows) error, stmt string, args ...any) error {
+	rows, err := q.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// foreignKeys reads the foreign keys of t with a query returning the constraint
+// name, column, referenced table and column, and the delete and update rules.
+func (t *Table) foreignKeys(ctx context.Context, q Querier, stmt string) error {
+	return query(ctx, q, func(rows *sql.Rows) error {
+		var fk ForeignKey
+		if err := rows.Scan(&fk.Name, &fk.Column, &fk.RefTable, &fk.RefColumn, &fk.OnDelete, &fk.OnUpdate); err != nil {
+			return err
+		}
+		if fk.Name == "" {
+			fk.Name = ForeignKeyName(t.Name, fk.Column)
+		}
+		t.ForeignKeys = append(t.ForeignKeys, fk)
+		return nil
+	}, stmt, t.Name)
+}
+
+// setUnique marks the column of a single column unique index, or records a
+// multi-column one as an index.
+func (t *Table) setUnique(name string, columns []string) {
+	if len(columns) != 1 {
+		t.Indexes = append(t.Indexes, Index{Name: name, Columns: columns, Unique: true})
+		return
+	}
+	for i := range t.Columns {
+		if t.Columns[i].Name == columns[0] {
+			t.Columns[i].Unique = true
+		}
+	}
+}
+
+func introspectSQLite(ctx context.Context, q Querier, table string) (Table, error) {
+	t := Table{Name: table}
+	err := query(ctx, q, func(rows *sql.Rows) error {
+		var (
+			c   Column
+			dv  sql.NullString
+			pk  int
+			nnl int
+		)
+		if err := rows.Scan(&c.Name, &c.Type, &nnl, &dv, &pk); err != nil {
+			return err
+		}
+		c.PK, c.NotNull, c.Default = pk > 0, nnl == 1, dv.String
+		t.Columns = append(t.Columns, c)
+		return nil
+	}, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, table)
+	if err != nil || len(t.Columns) == 0 {
+		return t, err
+	}
+	type index struct {
+		name, origin string
+		unique       bool
+	}
+	var indexes []index
+	err = query(ctx, q, func(rows *sql.Rows) error {
+		var idx index
+		if err := rows.Scan(&idx.name, &idx.unique, &idx.origin); err != nil {
+			return err
+		}
+		indexes = append(indexes, idx)
+		return nil
+	}, `SELECT name, "unique", origin FROM pragma_index_list(?) ORDER BY name`, table)
+	if err != nil {
+		return t, err
+	}
+	for _, idx := range indexes {
+		if idx.origin == "pk" {
+			continue
+		}
+		var columns []string
+		err = query(ctx, q, func(rows *sql.Rows) error {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				return err
+			}
+			columns = append(columns, column)
+			return nil
+		}, `SELECT name FROM pragma_index_info(?) ORDER BY seqno`, idx.name)
+		if err != nil {
+			return t, err
+		}
+		if idx.origin == "u" {
+			t.setUnique(idx.name, columns)
+		} else {
+			t.Indexes = append(t.Indexes, Index{Name: idx.name, Columns: columns, Unique: idx.unique})
+		}
+	}
+	// SQLite does not keep constraint names
+	return t, t.foreignKeys(ctx, q, `SELECT '', "from", "table", "to", on_delete, on_update FROM pragma_foreign_key_list(?) ORDER BY id, seq`)
+}
+
+var mysqlFunction = regexp.MustCompile(`(?i)^(current_timestamp|now|null)\b|\(`)
+
+func introspectMySQL(ctx context.Context, q Querier, table string) (Table, error) {
+	t := Table{Name: table}
+	err := query(ctx, q, func(rows *sql.Rows) error {
+		var (
+			c        Column
+			nullable string
+			key      string
+			dv       sql.NullString
+		)
+		if err := rows.Scan(&c.Name, &c.Type, &nullable, &dv, &key); err != nil {
+			return err
+		}
+		c.PK, c.NotNull = key == "PRI", nullable == "NO"
+		if dv.Valid {
+			// COLUMN_DEFAULT holds literals unquoted
+			_, numeric := strconv.ParseFloat(dv.String, 64)
+			c.Default = lo.Ternary(numeric == nil || mysqlFunction.MatchString(dv.String), dv.String, "'"+dv.String+"'")
+		}
+		t.Columns = append(t.Columns, c)
+		return nil
+	}, `SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE, COLUMN_DEFAULT, COLUMN_KEY FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION`, table)
+	if err != nil || len(t.Columns) == 0 {
+		return t, err
+	}
+	err = t.foreignKeys(ctx, q, `SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME, r.DELETE_RULE, r.UPDATE_RULE
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS r ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.TABLE_SCHEMA = DATABASE() AND k.TABLE_NAME = ? ORDER BY k.CONSTRAINT_NAME`)
+	if err != nil {
+		return t, err
+	}
+	var names []string
+	columns := map[string][]string{}
+	unique := map[string]bool{}
+	err = query(ctx, q, func(rows *sql.Rows) error {
+		var (
+			name, column string
+			nonUnique    int
+		)
+		if err := rows.Scan(&name, &column, &nonUnique); err != nil {
+			return err
+		}
+		if _, ok := columns[name]; !ok {
+			names = append(names, name)
+		}
+		columns[name] = append(columns[name], column)
+		unique[name] = nonUnique == 0
+		return nil
+	}, `SELECT INDEX_NAME, COLUMN_NAME, NON_UNIQUE FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME <> 'PRIMARY' ORDER BY INDEX_NAME, SEQ_IN_INDEX`, table)
+	if err != nil {
+		return t, err
+	}
+	for _, name := range names {
+		if _, ok := t.ForeignKey(name); ok {
+			// the index MySQL creates for a foreign key
+			continue
+		}
+		if unique[name] {
+			t.setUnique(name, columns[name])
+		} else {
+			t.Indexes = append(t.Indexes, Index{Name: name, Columns: columns[name]})
+		}
+	}
+	return t, nil
+}
+
+var indexColumns = regexp.MustCompile(`\(([^()]+)\)\s*$`)
+
+func introspectPostgres(ctx context.Context, q Querier, table string) (Table, error) {
+	t := Table{Name: table}
+	err := query(ctx, q, func(rows *sql.Rows) error {
+		var (
+			c         Column
+			dataType  string
+			nullable  string
+			dv        sql.NullString
+			length    sql.NullInt64
+			precision sql.NullInt64
+			scale     sql.NullInt64
+		)
+		if err := rows.Scan(&c.Name, &dataType, &nullable, &dv, &length, &precision, &scale); err != nil {
+			return err
+		}
+		c.NotNull, c.Default = nullable == "NO", dv.String
+		switch {
+		case dataType == "character varying" && length.Valid:
+			c.Type = fmt.Sprintf("varchar(%d)", length.Int64)
+		case dataType == "character" && length.Valid:
+			c.Type = fmt.Sprintf("char(%d)", length.Int64)
+		case dataType == "numeric" && precision.Valid:
+			c.Type = fmt.Sprintf("numeric(%d,%d)", precision.Int64, scale.Int64)
+		default:
+			c.Type = dataType
+		}
+		t.Columns = append(t.Columns, c)
+		return nil
+	}, `SELECT column_name, data_type, is_nullable, column_default, character_maximum_length, numeric_precision, numeric_scale
+FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position`, table)
+	if err != nil || len(t.Columns) == 0 {
+		return t, err
+	}
+	var constraints []string
+	uniques := map[string][]string{}
+	err = query(ctx, q, func(rows *sql.Rows) error {
+		var name, kind, column string
+		if err := rows.Scan(&name, &kind, &column); err != nil {
+			return err
+		}
+		if kind == "PRIMARY KEY" {
+			for i := range t.Columns {
+				t.Columns[i].PK = t.Columns[i].PK || t.Columns[i].Name == column
+			}
+			return nil
+		}
+		if _, ok := uniques[name]; !ok {
+			constraints = append(constraints, name)
+		}
+		uniques[name] = append(uniques[name], column)
+		return nil
+	}, `SELECT tc.constraint_name, tc.constraint_type, kcu.column_name
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+  ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+WHERE tc.table_schema = current_schema() AND tc.table_name = $1 AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+ORDER BY tc.constraint_name, kcu.ordinal_position`, table)
+	if err != nil {
+		return t, err
+	}
+	for _, name := range constraints {
+		t.setUnique(name, uniques[name])
+	}
+	err = t.foreignKeys(ctx, q, `SELECT tc.constraint_name, kcu.column_name, ccu.table_name, ccu.column_name, rc.delete_rule, rc.update_rule
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu
+  ON kcu.constraint_schema = tc.constraint_schema AND kcu.constraint_name = tc.constraint_name
+JOIN information_schema.constraint_column_usage ccu
+  ON ccu.constraint_schema = tc.constraint_schema AND ccu.constraint_name = tc.constraint_name
+JOIN information_schema.referential_constraints rc
+  ON rc.constraint_schema = tc.constraint_schema AND rc.constraint_name = tc.constraint_name
+WHERE tc.table_schema = current_schema() AND tc.table_name = $1 AND tc.constraint_type = 'FOREIGN KEY'
+ORDER BY tc.constraint_name`)
+	if err != nil {
+		return t, err
+	}
+	// indexes backing the constraints above are not secondary indexes
+	err = query(ctx, q, func(rows *sql.Rows) error {
+		var name, def string
+		if err := rows.Scan(&name, &def); err != nil {
+			return err
+		}
+		idx := Index{Name: name, Unique: strings.HasPrefix(strings.ToUpper(def), "CREATE UNIQUE")}
+		if match := indexColumns.FindStringSubmatch(def); match != nil {
+			idx.Columns = lo.Map(strings.Split(match[1], ","), func(c string, _ int) string {
+				return strings.Trim(strings.TrimSpace(c), `"`)
+			})
+		}
+		t.Indexes = append(t.Indexes, idx)
+		return nil
+	}, `SELECT indexname, indexdef FROM pg_indexes
+WHERE schemaname = current_schema() AND tablename = $1 AND indexname NOT IN (
+  SELECT constraint_name FROM information_schema.table_constraints WHERE table_schema = current_schema() AND table_name = $1)
+ORDER BY indexname`, table)
+	return t, err
+}
@@ -0,0 +1,262 @@
+// Package decimal provides Decimal, an arbitrary-precision decimal number for
+// values such as money that must not be rounded by a binary float64.
+//
+// A Decimal is an integer coefficient scaled by a power of ten: 12.50 is the
+// coefficient 1250 with the scale 2. The scale is kept, so "12.50" prints as
+// "12.50", while Cmp and Equal compare numeric values. The zero value is 0.
+//
+// Usage example:
+//
+//	price := decimal.MustParse("19.99")
+//	total := price.Mul(decimal.New(3, 0)).Round(2) // 59.97
+package decimal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// ErrInvalidDecimal is returned when a string is not a decimal number.
+var ErrInvalidDecimal = errors.New("invalid decimal")
+
+// maxExponent bounds the exponent and the scale Parse accepts: the input often
+// comes from requests, and 1e2000000000 would build a two billion digit number.
+const maxExponent = 1000
+
+// Decimal is an immutable decimal number: coefficient * 10^-scale.
+type Decimal struct {
+	coef  *big.Int // nil stands for 0
+	scale int32
+}
+
+// New returns coef * 10^-scale, e.g. New(1250, 2) is 12.50.
+func New(coef int64, scale int32) Decimal {
+	return Decimal{coef: big.NewInt(coef), scale: scale}
+}
+
+// Parse parses a decimal number such as "12.50", "-0.001", "+3" or "1.5e3".
+// Exponents and scales beyond ±1000 are rejected with ErrInvalidDecimal.
+func Parse(s string) (Decimal, error) {
+	mantissa, exp := s, int64(0)
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+		}
+		if e > maxExponent || e < -maxExponent {
+			return Decimal{}, fmt.Errorf("%w: %q is out of range", ErrInvalidDecimal, s)
+		}
+		mantissa, exp = s[:i], e
+	}
+	neg := strings.HasPrefix(mantissa, "-")
+	if neg || strings.HasPrefix(mantissa, "+") {
+		mantissa = mantissa[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	digits := intPart + fracPart
+	if digits == "" || strings.Trim(digits, "0123456789") != "" {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrInvalidDecimal, s)
+	}
+	coef, _ := new(big.Int).SetString(digits, 10)
+	if neg {
+		coef.Neg(coef)
+	}
+	scale := int64(len(fracPart)) - exp
+	if scale > maxExponent || scale < -maxExponent {
+		return Decimal{}, fmt.Errorf("%w: %q is out of range", ErrInvalidDecimal, s)
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(-scale))
+		scale = 0
+	}
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+// MustParse is like Parse but panics if s is not a decimal number.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+	return d.coef
+}
+
+// Scale returns the number of digits after the decimal point.
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of d.
+func (d Decimal) Sign() int {
+	return d.coefficient().Sign()
+}
+
+// IsZero reports whether d is 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Cmp compares the values of d and o: -1 if d < o, 0 if d == o and +1 if d > o.
+func (d Decimal) Cmp(o Decimal) int {
+	a, b := align(d, o)
+	return a.Cmp(b)
+}
+
+// Equal reports whether d and o have the same value; 1.5 equals 1.50.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+// Add returns d + o.
+func (d Decimal) Add(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{coef: new(big.Int).Add(a, b), scale: max(d.scale, o.scale)}
+}
+
+// Sub returns d - o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	a, b := align(d, o)
+	return Decimal{coef: new(big.Int).Sub(a, b), scale: max(d.scale, o.scale)}
+}
+
+// Mul returns d * o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.coefficient(), o.coefficient()), scale: d.scale + o.scale}
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.coefficient()), scale: d.scale}
+}
+
+// Round rounds d to places decimal places, half away from zero.
+func (d Decimal) Round(places int32) Decimal {
+	if places >= d.scale {
+		return d.rescale(places)
+	}
+	q, r := new(big.Int).QuoRem(d.coefficient(), pow10(int64(d.scale-places)), new(big.Int))
+	// |r| * 2 >= 10^(scale-places) rounds away from zero
+	if r.Abs(r).Lsh(r, 1).Cmp(pow10(int64(d.scale-places))) >= 0 {
+		q.Add(q, big.NewInt(int64(d.Sign())))
+	}
+	return Decimal{coef: q, scale: places}
+}
+
+// Float64 returns the nearest float64 of d.
+func (d Decimal) Float64() float64 {
+	f, _ := new(big.Rat).SetFrac(d.coefficient(), pow10(int64(d.scale))).Float64()
+	return f
+}
+
+// String formats d in plain notation with its scale, e.g. "12.50".
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.coefficient()).String()
+	sign := lo.Ternary(d.Sign() < 0, "-", "")
+	if d.scale <= 0 {
+		if d.IsZero() {
+			return "0"
+		}
+		return sign + digits + strings.Repeat("0", int(-d.scale))
+	}
+	if pad := int(d.scale) - len(digits) + 1; pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+	point := len(digits) - int(d.scale)
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// MarshalJSON encodes d as a JSON string, so clients decoding JSON numbers as
+// doubles do not lose precision.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a JSON string.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		s = unquoted
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Value implements driver.Valuer: d is stored as its string, which DECIMAL and
+// NUMERIC columns accept without rounding.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner for string, []byte, int64 and float64 values.
+func (d *Decimal) Scan(src any) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case int64:
+		*d = New(v, 0)
+		return nil
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidDecimal, src)
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// rescale returns d with a greater scale.
+func (d Decimal) rescale(scale int32) Decimal {
+	if scale == d.scale {
+		return d
+	}
+	return Decimal{coef: new(big.Int).Mul(d.coefficient(), pow10(int64(scale-d.scale))), scale: scale}
+}
+
+// align returns the coefficients of a and b at their common scale.
+func align(a, b Decimal) (*big.Int, *big.Int) {
+	scale := max(a.scale, b.scale)
+	return a.rescale(scale).coefficient(), b.rescale(scale).coefficient()
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
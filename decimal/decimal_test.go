@@ -0,0 +1,118 @@
+package decimal
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]string{
+		"0":        "0",
+		"12.50":    "12.50",
+		"-0.001":   "-0.001",
+		"+3":       "3",
+		".5":       "0.5",
+		"1.":       "1",
+		"1.5e3":    "1500",
+		"15e-3":    "0.015",
+		"-1.25E+1": "-12.5",
+		"0.00":     "0.00",
+		"123456789012345678901234567890.123456789": "123456789012345678901234567890.123456789",
+	}
+	for in, want := range tests {
+		d, err := Parse(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, d.String(), in)
+	}
+	for _, in := range []string{"", "-", ".", "1.2.3", "1e", "a1", "1-2", "--1", "+-1", " 1", "0x10"} {
+		_, err := Parse(in)
+		require.ErrorIs(t, err, ErrInvalidDecimal, in)
+	}
+	require.Panics(t, func() { MustParse("x") })
+}
+
+func TestParse_HostileExponent(t *testing.T) {
+	for _, in := range []string{"1e5000000", "1e-50000000", "1e2000000000", "1e1001", "0." + strings.Repeat("0", 1000) + "1"} {
+		start := time.Now()
+		_, err := Parse(in)
+		require.ErrorIs(t, err, ErrInvalidDecimal, in)
+		require.Less(t, time.Since(start), 100*time.Millisecond, in)
+	}
+	d, err := Parse("1e-1000")
+	require.NoError(t, err)
+	require.Equal(t, 1, d.Cmp(New(0, 0)))
+	d, err = Parse("1e1000")
+	require.NoError(t, err)
+	require.Equal(t, 1001, len(d.String()))
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+	a, b := MustParse("0.1"), MustParse("0.2")
+	require.Equal(t, "0.3", a.Add(b).String())
+	require.Equal(t, "-0.1", a.Sub(b).String())
+	require.Equal(t, "0.02", a.Mul(b).String())
+	require.Equal(t, "-0.1", a.Neg().String())
+	require.Equal(t, "59.97", MustParse("19.99").Mul(New(3, 0)).Round(2).String())
+
+	require.Equal(t, 0, MustParse("1.5").Cmp(MustParse("1.50")))
+	require.True(t, MustParse("1.5").Equal(MustParse("1.50")))
+	require.Equal(t, -1, MustParse("-2").Cmp(MustParse("1")))
+	require.Equal(t, 1, MustParse("0.0001").Cmp(Decimal{}))
+	require.True(t, Decimal{}.IsZero())
+	require.Equal(t, "0", Decimal{}.String())
+	require.Equal(t, "0.00", New(0, 2).String())
+	require.Equal(t, "500", New(5, -2).String())
+	require.Equal(t, int32(2), MustParse("1.25").Scale())
+	require.Equal(t, 1.25, MustParse("1.25").Float64())
+}
+
+func TestDecimal_Round(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int32
+		want   string
+	}{
+		{"1.235", 2, "1.24"},
+		{"1.234", 2, "1.23"},
+		{"-1.235", 2, "-1.24"},
+		{"-0.5", 0, "-1"},
+		{"0.49", 0, "0"},
+		{"1.5", 3, "1.500"},
+		{"1250", -2, "1300"},
+	}
+	for _, tc := range tests {
+		require.Equal(t, tc.want, MustParse(tc.in).Round(tc.places).String(), tc.in)
+	}
+}
+
+func TestDecimal_Encoding(t *testing.T) {
+	var v struct {
+		A Decimal `json:"a"`
+		B Decimal `json:"b"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"a":12.50,"b":"0.1"}`), &v))
+	require.Equal(t, "12.50", v.A.String())
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a":"12.50","b":"0.1"}`, string(data))
+	require.Error(t, json.Unmarshal([]byte(`{"a":true}`), &v))
+
+	var d Decimal
+	for src, want := range map[any]string{"1.10": "1.10", int64(7): "7", 0.25: "0.25"} {
+		require.NoError(t, d.Scan(src))
+		require.Equal(t, want, d.String())
+	}
+	require.NoError(t, d.Scan([]byte("3.14")))
+	require.Equal(t, "3.14", d.String())
+	require.ErrorIs(t, d.Scan(true), ErrInvalidDecimal)
+	value, err := MustParse("9.90").Value()
+	require.NoError(t, err)
+	require.Equal(t, "9.90", value)
+	text, _ := d.MarshalText()
+	require.NoError(t, d.UnmarshalText(text))
+	require.Equal(t, "3.14", d.String())
+}
@@ -2,6 +2,7 @@ package xql
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/kcmvp/xql/entity"
@@ -33,9 +34,10 @@ import (
 
 // Field is a sealed interface describing a single field's metadata.
 //
-// Implementations provide three read-only accessors:
+// Implementations provide read-only accessors:
 //   - Name(): the canonical provider name (usually the exported Go field name)
 //   - QualifiedName(): the DB-qualified column name in the form "table.column"
+//   - Type(): the Go type hint of the field (int64, string, time.Time, ...)
 //   - ViewName(): the JSON/view facing name (the key used in validated objects)
 //
 // The unexported seal() method prevents external packages from
@@ -50,6 +52,9 @@ type Field interface {
 	// QualifiedName returns a DB-qualified column reference in the form
 	// "table.column". Consumers (SQL builders) rely on this format.
 	QualifiedName() string
+	// Type returns the Go type hint the field was generated with, e.g.
+	// int64 or time.Time. SQL result mappers convert column values to it.
+	Type() reflect.Type
 	// seal prevents external implementations of Field.
 	seal()
 }
@@ -90,6 +95,9 @@ func (f persistentField[E]) Scope() string {
 // Name returns column name associated with this field.
 func (f persistentField[E]) Name() string { return f.column }
 
+// Type returns the Go type hint E.
+func (f persistentField[E]) Type() reflect.Type { return reflect.TypeFor[E]() }
+
 // seal implements the package-only sealing marker.
 func (f persistentField[E]) seal() {}
 
@@ -44,6 +44,9 @@ type ValueObject interface {
 	Time(name string) mo.Option[time.Time]
 	MstTime(name string) time.Time
 	Get(string) mo.Option[any]
+	// IsNull reports whether the value object holds an explicit null for name,
+	// as opposed to no value at all. Getters report both as absent.
+	IsNull(name string) bool
 	Add(name string, value any)
 	Update(name string, value any)
 	// Fields returns the list of field names of the value object.
@@ -58,6 +61,29 @@ func (vo Data) Get(s string) mo.Option[any] {
 	return Get[any](vo, s)
 }
 
+// IsNull reports whether name holds an explicit null. It supports the same
+// dot notation as Get.
+func (vo Data) IsNull(name string) bool {
+	var parent any = vo
+	key := name
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		opt := Get[any](vo, name[:i])
+		if opt.IsAbsent() {
+			return false
+		}
+		parent, key = opt.MustGet(), name[i+1:]
+	}
+	switch p := parent.(type) {
+	case Data:
+		v, ok := p[key]
+		return ok && v == nil
+	case ValueObject:
+		return p.IsNull(key)
+	default:
+		return false
+	}
+}
+
 // Keys returns all top-level keys present in the value object. The list is
 // sorted to ensure a deterministic order for callers and tests.
 func (vo Data) Fields() []string {
@@ -93,9 +119,10 @@ var _ ValueObject = (*Data)(nil)
 func (vo Data) seal() {}
 
 // Get is a generic helper to retrieve a value and assert its type.
-// It returns an Option, which will be empty if the key was not present.
+// It returns an Option, which will be empty if the key was not present or
+// holds an explicit null.
 // It panics if the key exists but the type is incorrect. This function
-// supports dot notation for embedded objects and array indexing (e.g., "field.0.nestedField").
+// supports dot notation for embedded objects, map keys and array indexing (e.g., "field.0.nestedField").
 func Get[T any](data Data, name string) mo.Option[T] {
 	parts := strings.Split(name, ".")
 	var currentValue any = data
@@ -123,8 +150,17 @@ func Get[T any](data Data, name string) mo.Option[T] {
 			currentValue = opt.MustGet()
 			continue
 		}
-		// If it's a slice, look up the index.
 		val := reflect.ValueOf(currentValue)
+		// If it's a map of values, e.g. of a view.MapField, look up the key.
+		if val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String {
+			next := val.MapIndex(reflect.ValueOf(part).Convert(val.Type().Key()))
+			if !next.IsValid() {
+				return mo.None[T]()
+			}
+			currentValue = next.Interface()
+			continue
+		}
+		// If it's a slice, look up the index.
 		if val.Kind() == reflect.Slice {
 			index, err := strconv.Atoi(part)
 			lo.Assertf(err == nil, "dvo: path part '%s' in '%s' is not a valid integer index for a slice", part, name)
@@ -135,6 +171,9 @@ func Get[T any](data Data, name string) mo.Option[T] {
 		// If we are here, we are trying to traverse into a primitive from a non-final path segment.
 		return mo.None[T]()
 	}
+	if currentValue == nil {
+		return mo.None[T]()
+	}
 
 	typedValue, ok := currentValue.(T)
 	lo.Assertf(ok, "dvo: field '%s' has wrong type: expected %T, got %T", name, *new(T), currentValue)
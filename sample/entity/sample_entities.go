@@ -8,8 +8,8 @@ type Dummy struct {
 
 // BaseEntity defines common fields for database entities.
 //
-// NOTE: We don't model relationships in structs (no slices/pointers for relations),
-// and we don't rely on DB foreign keys. Joins are built purely via fields.
+// NOTE: We don't model relationships in structs (no slices/pointers for relations);
+// joins are built purely via fields. Foreign keys are declared with the `fk` directive.
 type BaseEntity struct {
 	ID        int64 `xql:"pk"`
 	CreatedAt time.Time
@@ -25,7 +25,7 @@ type BaseEntity struct {
 //   - N:N with Role via AccountRole join-table
 type Account struct {
 	BaseEntity
-	Dummy    Dummy
+	Dummy    Dummy  `xql:"-"`
 	Email    string `xql:"unique;index"`
 	Nickname string `xql:"name:nick_name;type:varchar(100);unique;not null;default:'anonymous'"`
 	Category int64  `xql:"type:integer;default:0"`
@@ -40,7 +40,7 @@ func (a Account) Table() string { return "accounts" }
 //   - 1:1 with Account via Profile.AccountID
 type Profile struct {
 	BaseEntity
-	Dummy     Dummy
+	Dummy     Dummy `xql:"-"`
 	AccountID int64
 	Bio       string
 	Birthday  time.Time
@@ -55,7 +55,7 @@ func (p Profile) Table() string { return "profiles" }
 //   - 1:N with OrderItem via OrderItem.OrderID
 type Order struct {
 	BaseEntity
-	Dummy         Dummy
+	Dummy         Dummy `xql:"-"`
 	AccountID     int64
 	Amount        float64
 	internalNotes string
@@ -70,9 +70,9 @@ func (o Order) Table() string { return "orders" }
 //   - N:1 with Product via OrderItem.ProductID
 type OrderItem struct {
 	BaseEntity
-	Dummy     Dummy
-	OrderID   int64
-	ProductID int64
+	Dummy     Dummy `xql:"-"`
+	OrderID   int64 `xql:"index;fk:orders.id;on delete:cascade"`
+	ProductID int64 `xql:"fk:products.id"`
 	Quantity  int64
 	UnitPrice float64
 }
@@ -85,7 +85,7 @@ func (oi OrderItem) Table() string { return "order_items" }
 //   - N:N with Order via OrderItem
 type Product struct {
 	BaseEntity
-	Dummy Dummy
+	Dummy Dummy  `xql:"-"`
 	SKU   string `xql:"unique;index"`
 	Name  string
 	Price float64
@@ -99,7 +99,7 @@ func (p Product) Table() string { return "products" }
 //   - N:N with Account via AccountRole
 type Role struct {
 	BaseEntity
-	Dummy Dummy
+	Dummy Dummy  `xql:"-"`
 	Key   string `xql:"unique;index"`
 	Name  string
 }
@@ -111,11 +111,13 @@ func (r Role) Table() string { return "roles" }
 // Joins:
 //   - N:1 with Account via AccountRole.AccountID
 //   - N:1 with Role via AccountRole.RoleID
+//
+// An account holds a role at most once: (account_id, role_id) is unique.
 type AccountRole struct {
 	BaseEntity
-	Dummy     Dummy
-	AccountID int64
-	RoleID    int64
+	Dummy     Dummy `xql:"-"`
+	AccountID int64 `xql:"fk:accounts.id;on delete:cascade;unique:account_role"`
+	RoleID    int64 `xql:"fk:roles.id;on delete:cascade;unique:account_role"`
 }
 
 func (ar AccountRole) Table() string { return "account_roles" }
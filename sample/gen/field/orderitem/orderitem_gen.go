@@ -1,5 +1,5 @@
 // Code generated by gob xql schema. DO NOT EDIT.
-// Generated at: 2025-12-27 23:14:48 (ver: 88d0278a34)
+// Generated at: 2026-10-16 14:39:51 (ver: d8aba2b234)
 
 package orderitem
 
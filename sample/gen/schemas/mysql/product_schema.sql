@@ -11,4 +11,4 @@ CREATE TABLE IF NOT EXISTS products (
     created_by TEXT,
     updated_by TEXT
 );
-CREATE INDEX IF NOT EXISTS idx_products_sku ON products (sku);
+CREATE INDEX idx_products_sku ON products (sku);
@@ -10,4 +10,4 @@ CREATE TABLE IF NOT EXISTS roles (
     created_by TEXT,
     updated_by TEXT
 );
-CREATE INDEX IF NOT EXISTS idx_roles_key ON roles (key);
+CREATE INDEX idx_roles_key ON roles (key);
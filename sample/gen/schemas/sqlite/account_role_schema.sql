@@ -1,5 +1,5 @@
 -- Code generated by dvo xql. DO NOT EDIT.
--- Generated at: 2025-12-27 23:14:48 (ver: aa64d1d521)
+-- Generated at: 2026-10-16 14:39:51 (ver: 4653d3fe84)
 
 CREATE TABLE IF NOT EXISTS account_roles (
     id INTEGER PRIMARY KEY,
@@ -8,5 +8,8 @@ CREATE TABLE IF NOT EXISTS account_roles (
     created_at DATETIME,
     updated_at DATETIME,
     created_by TEXT,
-    updated_by TEXT
+    updated_by TEXT,
+    CONSTRAINT fk_account_roles_account_id FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_account_roles_role_id FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
 );
+CREATE UNIQUE INDEX IF NOT EXISTS uk_account_roles_account_role ON account_roles (account_id, role_id);
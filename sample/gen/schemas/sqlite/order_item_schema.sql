@@ -1,5 +1,5 @@
 -- Code generated by dvo xql. DO NOT EDIT.
--- Generated at: 2025-12-27 23:14:48 (ver: 88d0278a34)
+-- Generated at: 2026-10-16 14:39:51 (ver: d8aba2b234)
 
 CREATE TABLE IF NOT EXISTS order_items (
     id INTEGER PRIMARY KEY,
@@ -10,5 +10,8 @@ CREATE TABLE IF NOT EXISTS order_items (
     created_at DATETIME,
     updated_at DATETIME,
     created_by TEXT,
-    updated_by TEXT
+    updated_by TEXT,
+    CONSTRAINT fk_order_items_order_id FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_items_product_id FOREIGN KEY (product_id) REFERENCES products (id)
 );
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items (order_id);
@@ -0,0 +1,116 @@
+package sqlx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kcmvp/xql"
+	"github.com/samber/lo"
+)
+
+// Aggregate is an aggregate projection such as SUM(orders.amount).
+//
+// Aggregates are added to a query with QueryExecutor.Aggregate and come back
+// in the result ValueObjects under their alias. The default alias is
+// "<function>_<column>" (e.g. "sum_amount", "count_distinct_account_id",
+// or "count" for COUNT(*)); use As to pick another one, e.g. when two joined
+// tables share a column name.
+//
+// The comparison methods (Gt, Lte, ...) build predicates on the aggregate
+// for use in QueryExecutor.Having.
+type Aggregate struct {
+	fn       string
+	field    xql.Field
+	distinct bool
+	alias    string
+}
+
+func newAggregate(fn string, field xql.Field, distinct bool) Aggregate {
+	lo.Assertf(field != nil, "%s requires a field", fn)
+	return Aggregate{fn: fn, field: field, distinct: distinct}
+}
+
+// Count builds COUNT(field). A nil field builds COUNT(*).
+func Count(field xql.Field) Aggregate {
+	return Aggregate{fn: "COUNT", field: field}
+}
+
+// CountDistinct builds COUNT(DISTINCT field).
+func CountDistinct(field xql.Field) Aggregate {
+	return newAggregate("COUNT", field, true)
+}
+
+// Sum builds SUM(field).
+func Sum(field xql.Field) Aggregate {
+	return newAggregate("SUM", field, false)
+}
+
+// Avg builds AVG(field).
+func Avg(field xql.Field) Aggregate {
+	return newAggregate("AVG", field, false)
+}
+
+// Min builds MIN(field).
+func Min(field xql.Field) Aggregate {
+	return newAggregate("MIN", field, false)
+}
+
+// Max builds MAX(field).
+func Max(field xql.Field) Aggregate {
+	return newAggregate("MAX", field, false)
+}
+
+// As returns a copy of the aggregate projected under alias.
+func (a Aggregate) As(alias string) Aggregate {
+	lo.Assert(alias != "", "alias must not be empty")
+	a.alias = alias
+	return a
+}
+
+// Alias returns the key under which the aggregate appears in result ValueObjects.
+func (a Aggregate) Alias() string {
+	if a.alias != "" {
+		return a.alias
+	}
+	fn := strings.ToLower(a.fn)
+	if a.field == nil {
+		return fn
+	}
+	return lo.Ternary(a.distinct, fn+"_distinct_", fn+"_") + a.field.Name()
+}
+
+// expr renders the aggregate expression, e.g. COUNT(DISTINCT orders.account_id).
+func (a Aggregate) expr(d Dialect) string {
+	switch {
+	case a.field == nil:
+		return "COUNT(*)"
+	case a.distinct:
+		return fmt.Sprintf("%s(DISTINCT %s)", a.fn, qualify(d, a.field.QualifiedName()))
+	default:
+		return fmt.Sprintf("%s(%s)", a.fn, qualify(d, a.field.QualifiedName()))
+	}
+}
+
+func (a Aggregate) cmp(operator string, value any) Where {
+	return whereFunc(func(d Dialect) (string, []any) {
+		return fmt.Sprintf("%s %s ?", a.expr(d), operator), []any{value}
+	})
+}
+
+// Eq builds an "aggregate = ?" predicate.
+func (a Aggregate) Eq(value any) Where { return a.cmp("=", value) }
+
+// Ne builds an "aggregate != ?" predicate.
+func (a Aggregate) Ne(value any) Where { return a.cmp("!=", value) }
+
+// Gt builds an "aggregate > ?" predicate.
+func (a Aggregate) Gt(value any) Where { return a.cmp(">", value) }
+
+// Gte builds an "aggregate >= ?" predicate.
+func (a Aggregate) Gte(value any) Where { return a.cmp(">=", value) }
+
+// Lt builds an "aggregate < ?" predicate.
+func (a Aggregate) Lt(value any) Where { return a.cmp("<", value) }
+
+// Lte builds an "aggregate <= ?" predicate.
+func (a Aggregate) Lte(value any) Where { return a.cmp("<=", value) }
@@ -0,0 +1,109 @@
+package sqlx
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/kcmvp/xql/sample/entity"
+	"github.com/kcmvp/xql/sample/gen/field/account"
+	"github.com/kcmvp/xql/sample/gen/field/order"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate_Alias(t *testing.T) {
+	require.Equal(t, "count", Count(nil).Alias())
+	require.Equal(t, "count_id", Count(order.ID).Alias())
+	require.Equal(t, "count_distinct_account_id", CountDistinct(order.AccountID).Alias())
+	require.Equal(t, "sum_amount", Sum(order.Amount).Alias())
+	require.Equal(t, "avg_amount", Avg(order.Amount).Alias())
+	require.Equal(t, "min_amount", Min(order.Amount).Alias())
+	require.Equal(t, "max_amount", Max(order.Amount).Alias())
+	require.Equal(t, "total", Sum(order.Amount).As("total").Alias())
+	require.Panics(t, func() { Sum(nil) })
+}
+
+func TestSqlGeneration_Aggregate(t *testing.T) {
+	cases := []struct {
+		name string
+		exec QueryExecutor
+		exp  string
+	}{
+		{"CountAll", Query[Order](nil)(nil).Aggregate(Count(nil)),
+			"SELECT COUNT(*) AS count FROM orders"},
+		{"GroupBy", Query[Order](Schema{order.AccountID})(Gt(order.Amount, 0)).
+			Aggregate(CountDistinct(order.CreatedBy), Sum(order.Amount).As("total")).
+			GroupBy(order.AccountID),
+			"SELECT orders.account_id AS orders__account_id, COUNT(DISTINCT orders.created_by) AS count_distinct_created_by, SUM(orders.amount) AS total FROM orders WHERE orders.amount > ? GROUP BY orders.account_id"},
+		{"Having", Query[Order](Schema{order.AccountID})(nil).
+			Aggregate(Avg(order.Amount)).
+			GroupBy(order.AccountID).
+			Having(And(Avg(order.Amount).Gte(10), Count(nil).Gt(1))).
+			OrderBy(order.AccountID, Asc).Limit(5),
+			"SELECT orders.account_id AS orders__account_id, AVG(orders.amount) AS avg_amount FROM orders GROUP BY orders.account_id HAVING (AVG(orders.amount) >= ? AND COUNT(*) > ?) ORDER BY orders.account_id ASC LIMIT 5"},
+		{"Join", QueryJoin(nil)(InnerJoin(order.AccountID, account.ID), nil).Aggregate(Max(order.Amount)),
+			"SELECT MAX(orders.amount) AS max_amount FROM orders INNER JOIN accounts ON orders.account_id = accounts.id"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, err := c.exec.sql()
+			require.NoError(t, err)
+			require.Equal(t, c.exp, q)
+		})
+	}
+
+	t.Run("HavingArgsFollowWhereArgs", func(t *testing.T) {
+		schema := Schema{order.AccountID}
+		qe := Query[Order](schema)(Eq(order.CreatedBy, "john")).Aggregate(Sum(order.Amount)).GroupBy(order.AccountID).
+			Having(Sum(order.Amount).Gt(100)).(queryExec[Order])
+		q, args, err := selectSQL[Order](postgresDialect, &schema, qe.where, qe.opts)
+		require.NoError(t, err)
+		require.Contains(t, q, "WHERE orders.created_by = $1 GROUP BY orders.account_id HAVING SUM(orders.amount) > $2")
+		require.Equal(t, []any{"john", 100}, args)
+	})
+
+	errCases := []struct {
+		name string
+		exec QueryExecutor
+		msg  string
+	}{
+		{"FieldNotGrouped", Query[Order](Schema{order.AccountID, order.CreatedBy})(nil).Aggregate(Count(nil)).GroupBy(order.AccountID), "field orders.created_by must appear in group by"},
+		{"HavingWithoutGroup", Query[Order](Schema{order.AccountID})(nil).Having(Count(nil).Gt(1)), "having requires group by or aggregates"},
+		{"DuplicateAlias", Query[Order](nil)(nil).Aggregate(Sum(order.Amount), Sum(order.Amount)), "duplicate result key sum_amount: aggregate sum_amount and aggregate sum_amount"},
+		{"AliasIsFieldName", Query[Order](Schema{order.AccountID})(nil).Aggregate(Max(order.Amount).As("account_id")).GroupBy(order.AccountID), "duplicate result key account_id: orders.account_id and aggregate account_id"},
+		{"JoinedFieldNames", QueryJoin(Schema{order.ID, account.ID})(InnerJoin(order.AccountID, account.ID), nil), "duplicate result key id: orders.id and accounts.id"},
+		{"EmptyProjection", Query[Order](nil)(nil), "schema has no fields"},
+	}
+	for _, c := range errCases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := c.exec.sql()
+			require.Error(t, err)
+			require.Contains(t, err.Error(), c.msg)
+		})
+	}
+}
+
+func TestAggregate_Execute_SQLite(t *testing.T) {
+	db := newOrdersDB(t, "aggregate_exec")
+	ctx := context.Background()
+
+	row := func(accountID int64, amount float64) ValueObject {
+		return NewValueObject(map[string]any{"__schema": Schema{order.AccountID, order.Amount}, "account_id": accountID, "amount": amount})
+	}
+	_, err := InsertMany[Order](row(1, 10), row(1, 30), row(2, 5), row(3, 50), row(3, 70)).Execute(ctx, db)
+	require.NoError(t, err)
+
+	res, err := Query[Order](Schema{order.AccountID})(nil).
+		Aggregate(Count(nil), Sum(order.Amount)).
+		GroupBy(order.AccountID).
+		Having(Sum(order.Amount).Gt(20)).
+		OrderBy(order.AccountID, Asc).
+		Execute(ctx, db)
+	require.NoError(t, err)
+	items := res.MustLeft()
+	require.Len(t, items, 2)
+	require.Equal(t, int64(1), items[0].Get("account_id").MustGet())
+	require.Equal(t, int64(2), items[0].Get("count").MustGet())
+	require.Equal(t, 40.0, items[0].Get("sum_amount").MustGet())
+	require.Equal(t, int64(3), items[1].Get("account_id").MustGet())
+	require.Equal(t, 120.0, items[1].Get("sum_amount").MustGet())
+}
@@ -3,8 +3,10 @@ package sqlx
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
 	"log"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -13,20 +15,38 @@ import (
 	"github.com/spf13/viper"
 )
 
+// Querier is the minimal contract executors need to run a statement.
+// It is satisfied by *sql.DB, *sql.Tx, *sql.Conn and every DB returned by this package.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+var (
+	_ Querier = (*sql.DB)(nil)
+	_ Querier = (*sql.Tx)(nil)
+	_ Querier = (*sql.Conn)(nil)
+)
+
 // DB is the minimal database contract used by this package.
 // It mirrors the methods we use from *sql.DB and can be backed by *sql.DB or a thin wrapper.
 //
 // This indirection lets us add cross-cutting features (SQL logging, tracing, metrics) without
 // changing the higher-level query builder APIs.
 type DB interface {
-	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
-	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	Querier
 	PingContext(ctx context.Context) error
 	Close() error
 }
 
 // stdDB adapts *sql.DB to the DB interface.
-type stdDB struct{ *sql.DB }
+// driver is the database/sql driver name from the datasource configuration.
+type stdDB struct {
+	*sql.DB
+	driver string
+}
+
+func (d stdDB) driverName() string { return d.driver }
 
 func (d stdDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
 	return d.DB.ExecContext(ctx, query, args...)
@@ -67,6 +87,34 @@ func (d loggingDB) PingContext(ctx context.Context) error {
 	return err
 }
 
+func (d loggingDB) driverName() string {
+	if dn, ok := d.inner.(driverNamer); ok {
+		return dn.driverName()
+	}
+	return ""
+}
+
+// Driver exposes the driver of the wrapped DB so executors can still detect the dialect.
+func (d loggingDB) Driver() driver.Driver {
+	if dp, ok := d.inner.(driverProvider); ok {
+		return dp.Driver()
+	}
+	return nil
+}
+
+// BeginTx starts a transaction on the wrapped DB.
+// Statements run inside the transaction are not logged by this wrapper.
+func (d loggingDB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	beginner, ok := d.inner.(txBeginner)
+	if !ok {
+		return nil, fmt.Errorf("datasource does not support transactions")
+	}
+	start := time.Now()
+	tx, err := beginner.BeginTx(ctx, opts)
+	d.logger.Printf("sqlx begin dur=%s err=%v", time.Since(start), err)
+	return tx, err
+}
+
 func (d loggingDB) Close() error {
 	start := time.Now()
 	err := d.inner.Close()
@@ -74,6 +122,20 @@ func (d loggingDB) Close() error {
 	return err
 }
 
+// sqlDBOf returns the *sql.DB behind q: q itself, or the one wrapped by a
+// datasource of this package.
+func sqlDBOf(q Querier) (*sql.DB, bool) {
+	switch v := q.(type) {
+	case *sql.DB:
+		return v, v != nil
+	case stdDB:
+		return v.DB, v.DB != nil
+	case loggingDB:
+		return sqlDBOf(v.inner)
+	}
+	return nil, false
+}
+
 // WithSQLLogger wraps db with a SQL logger if logger is not nil.
 func WithSQLLogger(db DB, logger *log.Logger) DB {
 	if logger == nil {
@@ -86,7 +148,9 @@ var (
 	defaultDS DB
 	// registry holds named datasource
 	dsRegistry = map[string]DB{}
-	dsMu       sync.RWMutex
+	// dsScripts holds the configured migration scripts of the named datasources
+	dsScripts = map[string][]string{}
+	dsMu      sync.RWMutex
 
 	initOnce sync.Once
 	initErr  error
@@ -157,6 +221,75 @@ func (ds dataSource) DSN() string {
 	return strings.ReplaceAll(dsn, HostKey, ds.Host)
 }
 
+// Driver families understood by the execution layer. The mapping from driver
+// package to family mirrors cmd/gob/xql/resources/drivers.json.
+const (
+	sqliteDriver   = "sqlite"
+	mysqlDriver    = "mysql"
+	postgresDriver = "postgres"
+)
+
+var driverPackages = map[string]string{
+	"github.com/mattn/go-sqlite3":    sqliteDriver,
+	"modernc.org/sqlite":             sqliteDriver,
+	"github.com/glebarez/sqlite":     sqliteDriver,
+	"github.com/go-sql-driver/mysql": mysqlDriver,
+	"github.com/ziutek/mymysql":      mysqlDriver,
+	"github.com/lib/pq":              postgresDriver,
+	"github.com/jackc/pgx/v5":        postgresDriver,
+}
+
+// driverProvider is implemented by *sql.DB and the wrappers of this package.
+type driverProvider interface {
+	Driver() driver.Driver
+}
+
+// driverOf resolves the driver family of q from the package path of its
+// database/sql driver. *sql.Conn is resolved through its raw driver
+// connection. It returns an empty string for unknown drivers and for
+// queriers that do not expose their driver (e.g. *sql.Tx).
+func driverOf(q Querier) string {
+	var impl any
+	switch v := q.(type) {
+	case *sql.Conn:
+		if v == nil {
+			return ""
+		}
+		_ = v.Raw(func(dc any) error {
+			impl = dc
+			return nil
+		})
+	case driverProvider:
+		if isNilQuerier(q) {
+			return ""
+		}
+		impl = v.Driver()
+	}
+	if impl == nil {
+		return ""
+	}
+	t := reflect.TypeOf(impl)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	pkg := t.PkgPath()
+	for prefix, family := range driverPackages {
+		if pkg == prefix || strings.HasPrefix(pkg, prefix+"/") {
+			return family
+		}
+	}
+	return ""
+}
+
+// isNilQuerier reports whether q is nil or a typed nil pointer.
+func isNilQuerier(q Querier) bool {
+	if q == nil {
+		return true
+	}
+	v := reflect.ValueOf(q)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
 // registerDataSource opens a database connection from cfg and registers it under the provided name.
 // If name is empty, default is used. The function will Ping the DB to validate the connection.
 func registerDataSource(name string, cfg dataSource) error {
@@ -180,7 +313,7 @@ func registerDataSource(name string, cfg dataSource) error {
 		return fmt.Errorf("ping datasource %q: %w", name, err)
 	}
 
-	var db DB = stdDB{DB: raw}
+	var db DB = stdDB{DB: raw, driver: cfg.Driver}
 	if sqlLogger != nil {
 		db = WithSQLLogger(db, sqlLogger)
 	}
@@ -188,6 +321,7 @@ func registerDataSource(name string, cfg dataSource) error {
 	dsMu.Lock()
 	defer dsMu.Unlock()
 	dsRegistry[name] = db
+	dsScripts[name] = cfg.Scripts
 	if name == defaultDs && defaultDS == nil {
 		defaultDS = db
 	}
@@ -265,6 +399,7 @@ func CloseDataSource(name string) error {
 	}
 	dsMu.Lock()
 	defer dsMu.Unlock()
+	delete(dsScripts, name)
 	if db, ok := dsRegistry[name]; ok {
 		delete(dsRegistry, name)
 		return db.Close()
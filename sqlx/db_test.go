@@ -1,10 +1,15 @@
 package sqlx
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"log"
 	"sync"
 	"testing"
 
+	. "github.com/kcmvp/xql/sample/entity"
+	"github.com/kcmvp/xql/sample/gen/field/order"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/require"
 )
@@ -95,3 +100,64 @@ func TestGetDS_DefaultDS_Close(t *testing.T) {
 	_, ok4 := DefaultDS()
 	require.False(t, ok4)
 }
+
+func TestExecutors_RunOnQueriers(t *testing.T) {
+	raw := newOrdersDB(t, "querier_exec")
+
+	var buf bytes.Buffer
+	logged := WithSQLLogger(stdDB{DB: raw}, log.New(&buf, "", 0))
+	ctx := context.Background()
+	schema := Schema(order.AllExclude(order.ID))
+	row := func(accountID int64) ValueObject {
+		return NewValueObject(map[string]any{"__schema": schema, "account_id": accountID, "amount": 2.5})
+	}
+
+	t.Run("LoggingDB", func(t *testing.T) {
+		require.Equal(t, sqliteDriver, driverOf(logged))
+		res, err := Insert[Order](row(1)).Execute(ctx, logged)
+		require.NoError(t, err)
+		require.Equal(t, []any{int64(1)}, res.MustRight().(InsertResult).GeneratedKeys())
+		require.Contains(t, buf.String(), "INSERT INTO orders")
+	})
+
+	t.Run("Tx", func(t *testing.T) {
+		tx, err := raw.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = Insert[Order](row(2)).Execute(ctx, tx)
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+
+		res, err := Query[Order](Schema(order.All()))(Eq(order.AccountID, 2)).Execute(ctx, raw)
+		require.NoError(t, err)
+		require.Empty(t, res.MustLeft())
+	})
+
+	t.Run("Conn", func(t *testing.T) {
+		conn, err := raw.Conn(ctx)
+		require.NoError(t, err)
+		defer func() { _ = conn.Close() }()
+		require.Equal(t, sqliteDriver, driverOf(conn))
+		_, err = Insert[Order](row(3)).Execute(ctx, conn)
+		require.NoError(t, err)
+
+		res, err := Query[Order](Schema(order.All()))(Eq(order.AccountID, 3)).Execute(ctx, logged)
+		require.NoError(t, err)
+		require.Len(t, res.MustLeft(), 1)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		vo := NewValueObject(map[string]any{"__schema": Schema{order.Amount}, "amount": 9.5})
+		res, err := Update[Order](vo)(Eq(order.AccountID, 1)).Execute(ctx, logged)
+		require.NoError(t, err)
+		n, err := res.MustRight().RowsAffected()
+		require.NoError(t, err)
+		require.EqualValues(t, 1, n)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		var db *sql.DB
+		_, err := Delete[Order](Eq(order.ID, 1)).Execute(ctx, db)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "db is required")
+	})
+}
@@ -0,0 +1,197 @@
+package sqlx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Dialect captures the SQL differences between the databases supported by sqlx.
+//
+// Builders always produce `?` placeholders and unquoted identifiers internally;
+// the dialect selected for the target Querier renders the final statement:
+//   - Placeholder(n) renders the n-th (1-based) bind parameter;
+//   - Quote(ident) quotes an identifier when it is a reserved word or is not a
+//     plain lower-case identifier, and returns it unchanged otherwise;
+//   - LimitOffset renders the paging clause; a non-positive limit means "no limit";
+//   - SupportsReturning reports whether `INSERT ... RETURNING` is available;
+//   - ILike renders a case-insensitive LIKE of column against a `?` placeholder.
+type Dialect interface {
+	Name() string
+	Placeholder(n int) string
+	Quote(ident string) string
+	LimitOffset(limit, offset int) string
+	SupportsReturning() bool
+	ILike(column string) string
+}
+
+// dialect is the built-in Dialect implementation.
+type dialect struct {
+	name      string
+	dollar    bool   // $1, $2 ... instead of ?
+	quote     string // identifier quote character
+	noLimit   string // LIMIT value used when only OFFSET is requested
+	returning bool
+	ilike     bool // native ILIKE operator
+}
+
+var _ Dialect = dialect{}
+
+func (d dialect) Name() string { return d.name }
+
+func (d dialect) Placeholder(n int) string {
+	if d.dollar {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func (d dialect) Quote(ident string) string {
+	if plainIdent.MatchString(ident) && !reservedWords[ident] {
+		return ident
+	}
+	return d.quote + strings.ReplaceAll(ident, d.quote, d.quote+d.quote) + d.quote
+}
+
+func (d dialect) LimitOffset(limit, offset int) string {
+	switch {
+	case limit > 0 && offset > 0:
+		return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+	case limit > 0:
+		return fmt.Sprintf("LIMIT %d", limit)
+	case offset > 0 && d.noLimit != "":
+		return fmt.Sprintf("LIMIT %s OFFSET %d", d.noLimit, offset)
+	case offset > 0:
+		return fmt.Sprintf("OFFSET %d", offset)
+	default:
+		return ""
+	}
+}
+
+func (d dialect) SupportsReturning() bool { return d.returning }
+
+func (d dialect) ILike(column string) string {
+	if d.ilike {
+		return column + " ILIKE ?"
+	}
+	return fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", column)
+}
+
+var (
+	sqliteDialect   = dialect{name: sqliteDriver, quote: `"`, noLimit: "-1"}
+	mysqlDialect    = dialect{name: mysqlDriver, quote: "`", noLimit: "18446744073709551615"}
+	postgresDialect = dialect{name: postgresDriver, dollar: true, quote: `"`, returning: true, ilike: true}
+
+	// defaultDialect renders `?` placeholders; it is used by the pure sql()
+	// helpers, by Where.Build() and for queriers whose driver is unknown.
+	defaultDialect Dialect = sqliteDialect
+
+	plainIdent = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+	// reservedWords are keywords reserved by at least one of sqlite, mysql and postgres.
+	reservedWords = map[string]bool{}
+
+	// dialects is keyed by database/sql driver name (as used in the datasource
+	// configuration) and by driver family.
+	dialects = map[string]Dialect{
+		"sqlite3":      sqliteDialect,
+		sqliteDriver:   sqliteDialect,
+		mysqlDriver:    mysqlDialect,
+		postgresDriver: postgresDialect,
+		"pgx":          postgresDialect,
+	}
+	dialectMu sync.RWMutex
+)
+
+func init() {
+	for _, w := range strings.Fields(`all alter and any as asc between by case check column constraint
+		create cross default delete desc distinct drop else end exists false foreign from full
+		grant group having in index inner insert interval into is join key left like limit natural
+		not null offset on or order outer primary range references right row rows select set
+		table then to true union unique update user using values when where window with`) {
+		reservedWords[w] = true
+	}
+}
+
+// RegisterDialect registers (or replaces) the dialect used for the given
+// database/sql driver name, e.g. "sqlite3", "mysql", "postgres" or "pgx".
+func RegisterDialect(driver string, d Dialect) {
+	if driver == "" || d == nil {
+		return
+	}
+	dialectMu.Lock()
+	defer dialectMu.Unlock()
+	dialects[driver] = d
+}
+
+// GetDialect returns the dialect registered for a database/sql driver name.
+func GetDialect(driver string) (Dialect, bool) {
+	dialectMu.RLock()
+	defer dialectMu.RUnlock()
+	d, ok := dialects[driver]
+	return d, ok
+}
+
+// driverNamer is implemented by the datasources registered by this package;
+// it returns the driver name from the datasource configuration.
+type driverNamer interface {
+	driverName() string
+}
+
+// dialectOf selects the dialect for q: the configured driver name of a
+// registered datasource first, then the driver family detected from the
+// database/sql driver, and finally the default dialect.
+func dialectOf(q Querier) Dialect {
+	if dn, ok := q.(driverNamer); ok {
+		if d, ok := GetDialect(dn.driverName()); ok {
+			return d
+		}
+	}
+	if d, ok := GetDialect(driverOf(q)); ok {
+		return d
+	}
+	return defaultDialect
+}
+
+// DialectOf returns the dialect the executors of this package use for q, e.g.
+// a datasource returned by GetDS.
+func DialectOf(q Querier) Dialect {
+	return dialectOf(q)
+}
+
+// qualify renders a "table.column" qualified name through the dialect.
+func qualify(d Dialect, qname string) string {
+	parts := strings.Split(qname, ".")
+	for i, p := range parts {
+		parts[i] = d.Quote(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// rebind rewrites the `?` placeholders of q into the dialect's placeholder
+// style. Question marks inside quoted literals or identifiers are left untouched.
+func rebind(d Dialect, q string) string {
+	if d.Placeholder(1) == "?" {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	var quote rune
+	for _, r := range q {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString(d.Placeholder(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package sqlx
+
+import (
+	"database/sql"
+	"testing"
+
+	. "github.com/kcmvp/xql/sample/entity"
+	"github.com/kcmvp/xql/sample/gen/field/order"
+	"github.com/kcmvp/xql/sample/gen/field/profile"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_Render(t *testing.T) {
+	where := And(Eq(order.Amount, 50.0), In(order.ID, 1, 2))
+	vo := NewValueObject(map[string]any{"__schema": Schema{order.Amount, order.CreatedBy}, "amount": 1.0, "created_by": "x"})
+
+	cases := []struct {
+		name string
+		d    Dialect
+		exp  string
+	}{
+		{"Sqlite", sqliteDialect, "DELETE FROM orders WHERE (orders.amount = ? AND orders.id IN (?,?))"},
+		{"MySQL", mysqlDialect, "DELETE FROM orders WHERE (orders.amount = ? AND orders.id IN (?,?))"},
+		{"Postgres", postgresDialect, "DELETE FROM orders WHERE (orders.amount = $1 AND orders.id IN ($2,$3))"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, args, err := deleteSQL[Order](c.d, where)
+			require.NoError(t, err)
+			require.Equal(t, c.exp, q)
+			require.Len(t, args, 3)
+		})
+	}
+
+	t.Run("PostgresUpdate", func(t *testing.T) {
+		q, args, err := updateSQLFromValues[Order](postgresDialect, vo, Eq(order.ID, 7))
+		require.NoError(t, err)
+		require.Equal(t, "UPDATE orders SET amount = $1, created_by = $2 WHERE orders.id = $3", q)
+		require.Equal(t, []any{1.0, "x", 7}, args)
+	})
+
+	t.Run("PostgresUpdateNull", func(t *testing.T) {
+		// explicit nulls clear the column, absent fields are left out
+		patch := NewValueObject(map[string]any{"amount": 2.0, "createdBy": nil})
+		q, args, err := updateSQLFromValues[Order](postgresDialect, patch, Eq(order.ID, 7))
+		require.NoError(t, err)
+		require.Equal(t, "UPDATE orders SET amount = $1, created_by = NULL WHERE orders.id = $2", q)
+		require.Equal(t, []any{2.0, 7}, args)
+
+		// schema fields absent from the values are left out too
+		withSchema := NewValueObject(map[string]any{"__schema": Schema{order.AccountID, order.Amount, order.CreatedBy, order.UpdatedBy}, "amount": 2.0, "created_by": nil, "updated_by": "ops"})
+		q, args, err = updateSQLFromValues[Order](postgresDialect, withSchema, Eq(order.ID, 7))
+		require.NoError(t, err)
+		require.Equal(t, "UPDATE orders SET amount = $1, created_by = NULL, updated_by = $2 WHERE orders.id = $3", q)
+		require.Equal(t, []any{2.0, "ops", 7}, args)
+	})
+
+	t.Run("PostgresInsertReturning", func(t *testing.T) {
+		q, _, err := insertSQL[Order](postgresDialect, []ValueObject{vo, vo}, "id")
+		require.NoError(t, err)
+		require.Equal(t, "INSERT INTO orders (amount, created_by) VALUES ($1,$2), ($3,$4) RETURNING id", q)
+	})
+
+	t.Run("PostgresSelect", func(t *testing.T) {
+		schema := Schema{order.ID}
+		q, _, err := selectSQL[Order](postgresDialect, &schema, Gt(order.Amount, 1), queryOpts{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT orders.id AS orders__id FROM orders WHERE orders.amount > $1", q)
+	})
+
+	t.Run("PostgresJoin", func(t *testing.T) {
+		join := InnerJoin(order.AccountID, profile.AccountID).On(Eq(profile.Bio, "vip"))
+		q, args, err := buildSelectWithJoin(postgresDialect, Schema{order.ID}, join, Eq(order.Amount, 1), queryOpts{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT orders.id AS orders__id FROM orders INNER JOIN profiles ON (orders.account_id = profiles.account_id AND profiles.bio = $1) WHERE orders.amount = $2", q)
+		require.Equal(t, []any{"vip", 1}, args)
+	})
+
+	t.Run("BuildUsesDefaultDialect", func(t *testing.T) {
+		clause, _ := where.Build()
+		require.Equal(t, "(orders.amount = ? AND orders.id IN (?,?))", clause)
+	})
+}
+
+func TestDialect_Quote(t *testing.T) {
+	require.Equal(t, "amount", postgresDialect.Quote("amount"))
+	require.Equal(t, `"key"`, postgresDialect.Quote("key"))
+	require.Equal(t, "`order`", mysqlDialect.Quote("order"))
+	require.Equal(t, `"CamelCase"`, sqliteDialect.Quote("CamelCase"))
+	require.Equal(t, `"a""b"`, sqliteDialect.Quote(`a"b`))
+	require.Equal(t, "`user`.`key`", qualify(mysqlDialect, "user.key"))
+}
+
+func TestDialect_LimitOffset(t *testing.T) {
+	cases := []struct {
+		d             Dialect
+		limit, offset int
+		exp           string
+	}{
+		{sqliteDialect, 10, 0, "LIMIT 10"},
+		{sqliteDialect, 10, 20, "LIMIT 10 OFFSET 20"},
+		{sqliteDialect, 0, 20, "LIMIT -1 OFFSET 20"},
+		{mysqlDialect, 0, 20, "LIMIT 18446744073709551615 OFFSET 20"},
+		{postgresDialect, 0, 20, "OFFSET 20"},
+		{postgresDialect, 0, 0, ""},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.exp, c.d.LimitOffset(c.limit, c.offset), "%s(%d,%d)", c.d.Name(), c.limit, c.offset)
+	}
+}
+
+func TestDialect_Rebind(t *testing.T) {
+	q := rebind(postgresDialect, `SELECT '?', "a?" FROM t WHERE a = ? AND b IN (?,?)`)
+	require.Equal(t, `SELECT '?', "a?" FROM t WHERE a = $1 AND b IN ($2,$3)`, q)
+	require.Equal(t, "a = ?", rebind(mysqlDialect, "a = ?"))
+}
+
+func TestDialect_Of(t *testing.T) {
+	raw, err := sql.Open("sqlite3", "file:dialect_of?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = raw.Close() })
+
+	require.Equal(t, sqliteDriver, dialectOf(raw).Name())
+	// the configured driver name wins over driver detection
+	require.Equal(t, postgresDriver, dialectOf(stdDB{DB: raw, driver: "pgx"}).Name())
+	require.Equal(t, postgresDriver, dialectOf(WithSQLLogger(stdDB{DB: raw, driver: "postgres"}, nil)).Name())
+	// unknown configured names fall back to detection
+	require.Equal(t, sqliteDriver, dialectOf(stdDB{DB: raw, driver: "custom"}).Name())
+
+	RegisterDialect("custom", mysqlDialect)
+	t.Cleanup(func() {
+		dialectMu.Lock()
+		delete(dialects, "custom")
+		dialectMu.Unlock()
+	})
+	require.Equal(t, mysqlDriver, dialectOf(stdDB{DB: raw, driver: "custom"}).Name())
+}
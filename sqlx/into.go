@@ -0,0 +1,200 @@
+package sqlx
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"reflect"
+
+	"github.com/kcmvp/xql"
+	"github.com/kcmvp/xql/entity"
+)
+
+// IntoExecutor is returned by QueryInto. It runs a single-table SELECT and
+// fills entity structs instead of ValueObjects. Like QueryExecutor its
+// options return a new IntoExecutor and leave the receiver unchanged.
+//
+//   - Execute loads all rows into a slice.
+//   - Stream returns an iterator that scans one row at a time; the rows are
+//     closed when the loop ends, including on break. An error is yielded
+//     once, together with the zero value of T, and ends the iteration.
+type IntoExecutor[T entity.Entity] interface {
+	Execute(ctx context.Context, ds Querier) ([]T, error)
+	Stream(ctx context.Context, ds Querier) iter.Seq2[T, error]
+	OrderBy(field xql.Field, dir SortDirection) IntoExecutor[T]
+	Limit(n int) IntoExecutor[T]
+	Offset(n int) IntoExecutor[T]
+	After(values ...any) IntoExecutor[T]
+	sql() (string, error)
+}
+
+// QueryInto builds a single-table SELECT whose rows are mapped into T.
+//
+// Every schema field must belong to T's table and map to a struct field of T
+// by the generator's rules: snake_case field names, `xql:"name:..."`
+// overrides and flattened embedded structs such as BaseEntity. Struct fields
+// outside the schema keep their zero value, and so do fields whose column is
+// NULL. Column values are converted to the struct field's type; pointer
+// fields are allocated for non-NULL values.
+//
+// Usage example:
+//
+//	accounts, err := QueryInto[Account](account.All())(Gt(account.Balance, 0)).
+//		OrderBy(account.ID, Asc).
+//		Execute(ctx, db)
+//
+//	for acc, err := range QueryInto[Account](account.All())(nil).Stream(ctx, db) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func QueryInto[T entity.Entity](schema Schema) func(where Where) IntoExecutor[T] {
+	return func(where Where) IntoExecutor[T] {
+		return intoExec[T]{schema: schema, where: where}
+	}
+}
+
+type intoExec[T entity.Entity] struct {
+	schema Schema
+	where  Where
+	opts   queryOpts
+}
+
+var _ IntoExecutor[entity.Entity] = intoExec[entity.Entity]{}
+
+func (q intoExec[T]) OrderBy(field xql.Field, dir SortDirection) IntoExecutor[T] {
+	q.opts = q.opts.orderBy(field, dir)
+	return q
+}
+
+func (q intoExec[T]) Limit(n int) IntoExecutor[T] {
+	q.opts = q.opts.withLimit(n)
+	return q
+}
+
+func (q intoExec[T]) Offset(n int) IntoExecutor[T] {
+	q.opts = q.opts.withOffset(n)
+	return q
+}
+
+func (q intoExec[T]) After(values ...any) IntoExecutor[T] {
+	q.opts = q.opts.withAfter(values)
+	return q
+}
+
+func (q intoExec[T]) sql() (string, error) {
+	qstr, _, err := selectSQL[T](defaultDialect, &q.schema, q.where, q.opts)
+	return qstr, err
+}
+
+func (q intoExec[T]) Execute(ctx context.Context, ds Querier) ([]T, error) {
+	out := make([]T, 0)
+	for v, err := range q.Stream(ctx, ds) {
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func (q intoExec[T]) Stream(ctx context.Context, ds Querier) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		ds, d, err := querierFrom(ctx, ds)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		targets, err := structTargets[T](q.schema)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		query, args, err := selectSQL[T](d, &q.schema, q.where, q.opts)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		rows, err := ds.QueryContext(ctx, query, args...)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer func() { _ = rows.Close() }()
+
+		vals := make([]any, len(targets))
+		dests := make([]any, len(targets))
+		for i := range vals {
+			dests[i] = &vals[i]
+		}
+		for rows.Next() {
+			if err := rows.Scan(dests...); err != nil {
+				yield(zero, err)
+				return
+			}
+			var ent T
+			rv := reflect.ValueOf(&ent).Elem()
+			for i, col := range targets {
+				if err := setField(rv.FieldByIndex(col.index), vals[i]); err != nil {
+					yield(zero, fmt.Errorf("column %s: %w", q.schema[i].QualifiedName(), err))
+					return
+				}
+			}
+			if !yield(ent, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// structTargets resolves the struct field of T that receives each schema field.
+func structTargets[T entity.Entity](schema Schema) ([]entityColumn, error) {
+	var ent T
+	t := reflect.TypeOf(ent)
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("entity %s must be a struct", t)
+	}
+	index := columnIndexOf(t)
+	targets := make([]entityColumn, 0, len(schema))
+	for _, f := range schema {
+		if f.Scope() != ent.Table() {
+			return nil, fmt.Errorf("field %s is not a field of %s", f.QualifiedName(), t.Name())
+		}
+		col, ok := index[f.Name()]
+		if !ok {
+			return nil, fmt.Errorf("field %s does not map to a struct field of %s", f.QualifiedName(), t.Name())
+		}
+		targets = append(targets, col)
+	}
+	return targets, nil
+}
+
+// setField stores a scanned column value into a struct field. NULL leaves the
+// field at its zero value.
+func setField(fv reflect.Value, v any) error {
+	if v == nil {
+		return nil
+	}
+	t := fv.Type()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	cv, err := convertValue(v, t)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(cv)
+	if fv.Kind() == reflect.Pointer {
+		p := reflect.New(t)
+		p.Elem().Set(rv)
+		fv.Set(p)
+		return nil
+	}
+	fv.Set(rv)
+	return nil
+}
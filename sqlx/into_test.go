@@ -0,0 +1,106 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/kcmvp/xql/sample/entity"
+	"github.com/kcmvp/xql/sample/gen/field/account"
+	"github.com/kcmvp/xql/sample/gen/field/order"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryInto_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:query_into?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	for _, stmt := range []string{
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY, email TEXT, nick_name TEXT, category INTEGER, balance REAL, created_at TEXT, updated_at TEXT, created_by TEXT, updated_by TEXT)",
+		"INSERT INTO accounts (id, email, nick_name, category, balance, created_at, created_by) VALUES " +
+			"(1, 'ann@x.io', 'ann', 1, 10.5, '2024-05-01 08:00:00', 'sys'), " +
+			"(2, 'bob@x.io', 'bob', 2, 0, '2024-05-02 09:30:00', NULL), " +
+			"(3, 'cid@x.io', NULL, 2, 7, NULL, 'sys')",
+	} {
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	ctx := context.Background()
+	query := QueryInto[Account](account.All())
+
+	t.Run("Execute", func(t *testing.T) {
+		accounts, err := query(Gt(account.Category, 0)).OrderBy(account.ID, Asc).Execute(ctx, db)
+		require.NoError(t, err)
+		require.Len(t, accounts, 3)
+		ann := accounts[0]
+		require.Equal(t, int64(1), ann.ID)
+		require.Equal(t, "ann@x.io", ann.Email)
+		require.Equal(t, "ann", ann.Nickname) // xql:"name:nick_name"
+		require.Equal(t, int64(1), ann.Category)
+		require.Equal(t, 10.5, ann.Balance)
+		require.Equal(t, time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC), ann.CreatedAt)
+		require.Equal(t, "sys", ann.CreatedBy)
+		// NULL columns keep the zero value
+		require.Empty(t, accounts[1].CreatedBy)
+		require.Empty(t, accounts[2].Nickname)
+		require.True(t, accounts[2].CreatedAt.IsZero())
+	})
+
+	t.Run("PartialSchema", func(t *testing.T) {
+		accounts, err := QueryInto[Account](Schema{account.ID, account.Email})(Eq(account.ID, 2)).Execute(ctx, db)
+		require.NoError(t, err)
+		require.Equal(t, []Account{{BaseEntity: BaseEntity{ID: 2}, Email: "bob@x.io"}}, accounts)
+	})
+
+	t.Run("StreamBreak", func(t *testing.T) {
+		var ids []int64
+		for acc, err := range query(nil).OrderBy(account.ID, Desc).Stream(ctx, db) {
+			require.NoError(t, err)
+			ids = append(ids, acc.ID)
+			if len(ids) == 2 {
+				break
+			}
+		}
+		require.Equal(t, []int64{3, 2}, ids)
+	})
+
+	t.Run("Paging", func(t *testing.T) {
+		accounts, err := query(nil).OrderBy(account.ID, Asc).Limit(1).After(int64(1)).Execute(ctx, db)
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+		require.Equal(t, int64(2), accounts[0].ID)
+	})
+
+	t.Run("ForeignField_should_error", func(t *testing.T) {
+		_, err := QueryInto[Account](Schema{account.ID, order.Amount})(nil).Execute(ctx, db)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field orders.amount is not a field of Account")
+	})
+
+	t.Run("NilDB_should_error", func(t *testing.T) {
+		_, err := query(nil).Execute(ctx, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestQueryInto_SQLGeneration(t *testing.T) {
+	q, err := QueryInto[Order](Schema{order.ID, order.Amount})(Gt(order.Amount, 10)).OrderBy(order.ID, Desc).Limit(5).sql()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT orders.id AS orders__id, orders.amount AS orders__amount FROM orders WHERE orders.amount > ? ORDER BY orders.id DESC LIMIT 5", q)
+}
+
+func TestSetField(t *testing.T) {
+	type target struct {
+		Name  *string
+		Count int32
+	}
+	var tg target
+	rv := reflect.ValueOf(&tg).Elem()
+	require.NoError(t, setField(rv.Field(0), []byte("x")))
+	require.NoError(t, setField(rv.Field(1), int64(3)))
+	require.Equal(t, "x", *tg.Name)
+	require.Equal(t, int32(3), tg.Count)
+	require.Error(t, setField(rv.Field(1), "abc"))
+}
@@ -0,0 +1,200 @@
+package sqlx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kcmvp/xql"
+	"github.com/samber/lo"
+)
+
+type joinKind string
+
+const (
+	innerJoin joinKind = "INNER JOIN"
+	leftJoin  joinKind = "LEFT JOIN"
+	rightJoin joinKind = "RIGHT JOIN"
+)
+
+// joinStep joins table with the ON conditions conds.
+type joinStep struct {
+	kind  joinKind
+	table string
+	conds []Where
+}
+
+// Join is a FROM clause built from generated fields. It starts at the table
+// of the left field of the first join; every following join must reference an
+// already joined table on its left side and add a new table on its right side.
+//
+// Join values are immutable: each method returns a new Join.
+//
+// Usage example:
+//
+//	j := InnerJoin(order.AccountID, account.ID).
+//		LeftJoin(account.ID, profile.AccountID).
+//		On(Eq(profile.Bio, "vip"))
+//	exec := QueryJoin(schema)(j, Gt(order.Amount, 100))
+type Join struct {
+	base  string
+	steps []joinStep
+}
+
+// InnerJoin starts a join: FROM <left table> INNER JOIN <right table> ON left = right.
+func InnerJoin(left, right xql.Field) Join {
+	return Join{}.join(innerJoin, left, right)
+}
+
+// LeftJoin starts a join: FROM <left table> LEFT JOIN <right table> ON left = right.
+func LeftJoin(left, right xql.Field) Join {
+	return Join{}.join(leftJoin, left, right)
+}
+
+// RightJoin starts a join: FROM <left table> RIGHT JOIN <right table> ON left = right.
+func RightJoin(left, right xql.Field) Join {
+	return Join{}.join(rightJoin, left, right)
+}
+
+// InnerJoin appends an INNER JOIN of right's table on left = right.
+func (j Join) InnerJoin(left, right xql.Field) Join {
+	return j.join(innerJoin, left, right)
+}
+
+// LeftJoin appends a LEFT JOIN of right's table on left = right.
+func (j Join) LeftJoin(left, right xql.Field) Join {
+	return j.join(leftJoin, left, right)
+}
+
+// RightJoin appends a RIGHT JOIN of right's table on left = right.
+func (j Join) RightJoin(left, right xql.Field) Join {
+	return j.join(rightJoin, left, right)
+}
+
+// On adds conditions to the ON clause of the last join. Use EqField for
+// additional column pairs of a composite key; value predicates (Eq, In, ...)
+// are parameterized like in a Where.
+func (j Join) On(conds ...Where) Join {
+	lo.Assert(len(j.steps) > 0, "On requires a join")
+	steps := append([]joinStep{}, j.steps...)
+	last := steps[len(steps)-1]
+	last.conds = append(append([]Where{}, last.conds...), lo.Filter(conds, func(w Where, _ int) bool { return w != nil })...)
+	steps[len(steps)-1] = last
+	j.steps = steps
+	return j
+}
+
+func (j Join) join(kind joinKind, left, right xql.Field) Join {
+	lo.Assert(left != nil && right != nil, "join fields must not be nil")
+	if j.base == "" {
+		j.base = left.Scope()
+	}
+	tables := j.Tables()
+	lo.Assertf(lo.Contains(tables, left.Scope()), "join: table %s of %s is not part of the join", left.Scope(), left.QualifiedName())
+	lo.Assertf(!lo.Contains(tables, right.Scope()), "join: table %s is already joined", right.Scope())
+	j.steps = append(append([]joinStep{}, j.steps...), joinStep{kind: kind, table: right.Scope(), conds: []Where{EqField(left, right)}})
+	return j
+}
+
+// Tables returns the joined tables, starting with the base table.
+func (j Join) Tables() []string {
+	if j.base == "" {
+		return nil
+	}
+	return append([]string{j.base}, lo.Map(j.steps, func(s joinStep, _ int) string { return s.table })...)
+}
+
+// EqField builds a "left = right" predicate comparing two columns.
+func EqField(left, right xql.Field) Where {
+	lo.Assert(left != nil && right != nil, "fields must not be nil")
+	return whereFunc(func(d Dialect) (string, []any) {
+		return fmt.Sprintf("%s = %s", qualify(d, left.QualifiedName()), qualify(d, right.QualifiedName())), nil
+	})
+}
+
+// onWhere combines the ON conditions of a join step.
+func (s joinStep) onWhere() Where {
+	if len(s.conds) == 1 {
+		return s.conds[0]
+	}
+	return and(s.conds...)
+}
+
+// from renders the FROM clause (without the FROM keyword) and its arguments.
+func (j Join) from(d Dialect) (string, []any, error) {
+	if j.base == "" {
+		return "", nil, fmt.Errorf("join is required")
+	}
+	var sb strings.Builder
+	var args []any
+	sb.WriteString(d.Quote(j.base))
+	for _, s := range j.steps {
+		if err := whereErr(s.conds...); err != nil {
+			return "", nil, err
+		}
+		clause, a := buildWhere(s.onWhere(), d)
+		sb.WriteString(fmt.Sprintf(" %s %s ON %s", s.kind, d.Quote(s.table), clause))
+		args = append(args, a...)
+	}
+	return sb.String(), args, nil
+}
+
+// exists converts an inner join starting at baseTable into a correlated
+// EXISTS predicate, ANDed with where:
+//
+//	EXISTS (SELECT 1 FROM t2, t3 WHERE <on conditions> AND (<where>))
+//
+// It is used by DeleteJoin and UpdateJoin, where the joined tables only filter
+// the rows of the base table.
+func (j Join) exists(baseTable string, where Where) (Where, error) {
+	if j.base == "" {
+		return nil, fmt.Errorf("join is required")
+	}
+	if j.base != baseTable {
+		return nil, fmt.Errorf("join must start from table %s, got %s", baseTable, j.base)
+	}
+	for _, s := range j.steps {
+		if s.kind != innerJoin {
+			return nil, fmt.Errorf("only inner joins are supported here, got %s %s", s.kind, s.table)
+		}
+	}
+	conds := lo.Map(j.steps, func(s joinStep, _ int) Where { return s.onWhere() })
+	if err := whereErr(append(conds, where)...); err != nil {
+		return nil, err
+	}
+	return whereFunc(func(d Dialect) (string, []any) {
+		tables := lo.Map(j.steps, func(s joinStep, _ int) string { return d.Quote(s.table) })
+		parts := make([]string, 0, len(conds)+1)
+		var args []any
+		for _, c := range conds {
+			clause, a := buildWhere(c, d)
+			parts = append(parts, clause)
+			args = append(args, a...)
+		}
+		if wc, wargs := buildWhere(where, d); wc != "" {
+			parts = append(parts, "("+wc+")")
+			args = append(args, wargs...)
+		}
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s)", strings.Join(tables, ", "), strings.Join(parts, " AND ")), args
+	}), nil
+}
+
+// checkScope verifies that every projected, aggregated, grouped and ordered
+// field belongs to one of the joined tables.
+func (j Join) checkScope(schema Schema, opts queryOpts) error {
+	tables := j.Tables()
+	fields := append(append([]xql.Field{}, schema...), opts.groupBy...)
+	for _, a := range opts.aggs {
+		if a.field != nil {
+			fields = append(fields, a.field)
+		}
+	}
+	for _, o := range opts.orders {
+		fields = append(fields, o.field)
+	}
+	for _, f := range fields {
+		if !lo.Contains(tables, f.Scope()) {
+			return fmt.Errorf("field %s is not part of the join (%s)", f.QualifiedName(), strings.Join(tables, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package sqlx
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// entityColumn describes how a single exported struct field of an entity maps
+// to a database column. The rules mirror the ones used by `gob xql schema`:
+//   - column names are the snake_case form of the Go field name;
+//   - `xql:"name:..."` overrides the column name;
+//   - `xql:"-"` and unexported fields are skipped;
+//   - embedded structs (e.g. BaseEntity) are flattened;
+//   - nested struct fields other than time.Time are skipped.
+type entityColumn struct {
+	name  string
+	index []int
+	pk    bool
+}
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	columnsCache     sync.Map // reflect.Type -> []entityColumn
+	columnIndexCache sync.Map // reflect.Type -> map[string]entityColumn
+)
+
+// columnsOf returns the column mapping for the given struct type. Results are
+// cached per type as the mapping is derived from static type information.
+func columnsOf(t reflect.Type) []entityColumn {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if cached, ok := columnsCache.Load(t); ok {
+		return cached.([]entityColumn)
+	}
+	var cols []entityColumn
+	if t.Kind() == reflect.Struct {
+		cols = collectColumns(t, nil)
+	}
+	actual, _ := columnsCache.LoadOrStore(t, cols)
+	return actual.([]entityColumn)
+}
+
+// columnIndexOf returns the column mapping of the given struct type keyed by
+// column name, cached per type like columnsOf.
+func columnIndexOf(t reflect.Type) map[string]entityColumn {
+	if cached, ok := columnIndexCache.Load(t); ok {
+		return cached.(map[string]entityColumn)
+	}
+	idx := lo.KeyBy(columnsOf(t), func(c entityColumn) string { return c.name })
+	actual, _ := columnIndexCache.LoadOrStore(t, idx)
+	return actual.(map[string]entityColumn)
+}
+
+func collectColumns(t reflect.Type, parent []int) []entityColumn {
+	var cols []entityColumn
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			cols = append(cols, collectColumns(sf.Type, index)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if sf.Type.Kind() == reflect.Struct && sf.Type != timeType {
+			continue
+		}
+		tag := sf.Tag.Get("xql")
+		if tag == "-" {
+			continue
+		}
+		col := entityColumn{name: lo.SnakeCase(sf.Name), index: index}
+		for _, d := range strings.Split(tag, ";") {
+			parts := strings.SplitN(strings.TrimSpace(d), ":", 2)
+			switch strings.ToLower(parts[0]) {
+			case "pk":
+				col.pk = true
+			case "name":
+				if len(parts) == 2 && strings.TrimSpace(parts[1]) != "" {
+					col.name = strings.TrimSpace(parts[1])
+				}
+			}
+		}
+		cols = append(cols, col)
+	}
+	return cols
+}
+
+// primaryKeyOf returns the primary key column name of entity T, if declared
+// with the `xql:"pk"` directive.
+func primaryKeyOf[T any]() (string, bool) {
+	var ent T
+	col, ok := lo.Find(columnsOf(reflect.TypeOf(ent)), func(c entityColumn) bool {
+		return c.pk
+	})
+	return col.name, ok
+}
@@ -0,0 +1,469 @@
+package sqlx
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// migrationTable is the bookkeeping table recording the applied scripts.
+const migrationTable = "xql_migrations"
+
+// migrationLockTimeout is how long, in seconds, MySQL waits for the migration lock.
+const migrationLockTimeout = 60
+
+// ErrChecksumMismatch is returned by Migrate when an applied script was changed afterwards.
+var ErrChecksumMismatch = errors.New("migration checksum mismatch")
+
+// Migration is a versioned schema script, e.g. a file written by `gob xql schema`.
+//   - Name identifies the script: its file name, e.g. "account_schema.sql";
+//   - Version is the entity version stamped in the script header, or the
+//     checksum prefix for hand-written scripts;
+//   - Checksum is the SHA-256 of the statements; comments and blank lines,
+//     such as the generation timestamp, do not count.
+type Migration struct {
+	Name       string
+	Version    string
+	Checksum   string
+	Statements []string
+	creates    []string // tables created by the script
+	requires   []string // tables referenced or altered by the script
+	references []string // tables only referenced by the script
+	// foreignKeysOff is set by a script turning foreign keys off, e.g. a SQLite
+	// table rebuild written by `gob xql diff`
+	foreignKeysOff bool
+}
+
+var (
+	versionPattern         = regexp.MustCompile(`\(ver: ([0-9a-zA-Z]+)\)`)
+	createPattern          = regexp.MustCompile("(?i)CREATE\\s+TABLE\\s+(?:IF\\s+NOT\\s+EXISTS\\s+)?[\"`]?(\\w+)")
+	referencesPattern      = regexp.MustCompile("(?i)REFERENCES\\s+[\"`]?(\\w+)")
+	alterPattern           = regexp.MustCompile("(?i)ALTER\\s+TABLE\\s+(?:IF\\s+EXISTS\\s+)?(?:ONLY\\s+)?[\"`]?(\\w+)")
+	foreignKeysPattern     = regexp.MustCompile(`(?i)^PRAGMA\s+foreign_keys\s*=\s*(\w+)`)
+	foreignKeyCheckPattern = regexp.MustCompile(`(?i)^PRAGMA\s+foreign_key_check\b`)
+)
+
+// ParseMigration parses a migration script.
+func ParseMigration(name, script string) (Migration, error) {
+	stmts := statements(script)
+	if len(stmts) == 0 {
+		return Migration{}, fmt.Errorf("migration %s has no statements", name)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(stmts, ";\n")))
+	m := Migration{Name: name, Checksum: hex.EncodeToString(sum[:]), Statements: stmts}
+	m.Version = m.Checksum[:10]
+	if match := versionPattern.FindStringSubmatch(script); match != nil {
+		m.Version = match[1]
+	}
+	var altered []string
+	for _, stmt := range stmts {
+		for _, match := range createPattern.FindAllStringSubmatch(stmt, -1) {
+			m.creates = append(m.creates, strings.ToLower(match[1]))
+		}
+		for _, match := range referencesPattern.FindAllStringSubmatch(stmt, -1) {
+			m.references = append(m.references, strings.ToLower(match[1]))
+		}
+		for _, match := range alterPattern.FindAllStringSubmatch(stmt, -1) {
+			altered = append(altered, strings.ToLower(match[1]))
+		}
+		if match := foreignKeysPattern.FindStringSubmatch(stmt); match != nil {
+			m.foreignKeysOff = m.foreignKeysOff || slices.Contains([]string{"off", "0", "false", "no"}, strings.ToLower(match[1]))
+		}
+	}
+	m.requires = lo.Without(lo.Uniq(append(slices.Clone(m.references), altered...)), m.creates...)
+	m.references = lo.Without(lo.Uniq(m.references), append(m.creates, altered...)...)
+	return m, nil
+}
+
+// LoadMigrations reads the *.sql scripts of the given files and directories.
+func LoadMigrations(paths ...string) ([]Migration, error) {
+	var files []string
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, p)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(p, "*.sql"))
+		if err != nil {
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+		files = append(files, matches...)
+	}
+	var migrations []Migration
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("load migrations: %w", err)
+		}
+		m, err := ParseMigration(filepath.Base(file), string(data))
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(migrations, func(o Migration) bool { return o.Name == m.Name }) {
+			return nil, fmt.Errorf("duplicate migration %s", m.Name)
+		}
+		migrations = append(migrations, m)
+	}
+	return migrations, nil
+}
+
+// MigrateOption customizes Migrate.
+type MigrateOption func(*migrateConfig)
+
+type migrateConfig struct {
+	scripts []string
+	dryRun  bool
+}
+
+// WithScripts sets the script files and directories, overriding the `scripts`
+// of the datasource configuration.
+func WithScripts(paths ...string) MigrateOption {
+	return func(c *migrateConfig) {
+		c.scripts = paths
+	}
+}
+
+// DryRun reports the pending scripts without applying them.
+func DryRun() MigrateOption {
+	return func(c *migrateConfig) {
+		c.dryRun = true
+	}
+}
+
+// Migrate applies the pending migration scripts to the datasource registered as
+// dsName (empty means the default datasource) and returns them in the order they
+// were applied.
+//
+// The scripts are the `scripts` files and directories of the datasource
+// configuration, or gen/schemas/<dialect> (as written by `gob xql schema`) when
+// none are configured; WithScripts overrides both. Applied scripts are recorded
+// in the xql_migrations table with their version and checksum. Migrate refuses
+// to run, with ErrChecksumMismatch, when an applied script was changed since.
+//
+// Pending scripts run in dependency order, a script creating a table before the
+// scripts referencing or altering it, inside a single transaction. Note that
+// MySQL commits DDL statements implicitly, so a failure there leaves the earlier
+// scripts applied.
+//
+// `PRAGMA foreign_keys` has no effect inside a transaction, so when a script
+// turns foreign keys off, e.g. a SQLite table rebuild, they are turned off on
+// the connection before the transaction and back on after it; the pragma
+// statements of the scripts are skipped. A `PRAGMA foreign_key_check` reporting
+// a violation fails the migration.
+//
+// Concurrent migrations of the same database, e.g. several instances of a
+// service migrating on start-up, are serialized: Migrate reads the applied
+// scripts and runs the pending ones while holding a lock, an advisory lock on
+// PostgreSQL, GET_LOCK on MySQL and the database write lock on SQLite, so each
+// script is applied once.
+//
+// Usage example:
+//
+//	applied, err := sqlx.Migrate(ctx, "")
+func Migrate(ctx context.Context, dsName string, opts ...MigrateOption) ([]Migration, error) {
+	if dsName == "" {
+		dsName = defaultDs
+	}
+	db, ok := GetDS(dsName)
+	if !ok {
+		return nil, fmt.Errorf("datasource %q is not registered", dsName)
+	}
+	d := dialectOf(db)
+	cfg := &migrateConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.scripts == nil {
+		dsMu.RLock()
+		cfg.scripts = dsScripts[dsName]
+		dsMu.RUnlock()
+	}
+	if len(cfg.scripts) == 0 {
+		cfg.scripts = []string{filepath.Join("gen", "schemas", d.Name())}
+	}
+	migrations, err := LoadMigrations(cfg.scripts...)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := sqlDBOf(db)
+	if !ok {
+		return nil, fmt.Errorf("datasource %q does not expose its *sql.DB", dsName)
+	}
+	return applyMigrations(ctx, raw, d, migrations, cfg.dryRun)
+}
+
+// errForeignKeysOn is returned by migrateTx when a pending script turns foreign
+// keys off while they are on, as the pragma must be set before the transaction.
+var errForeignKeysOn = errors.New("foreign keys are on")
+
+// applyMigrations holds the migration lock on a connection of its own while it
+// reads the applied scripts and runs the pending ones, with foreign keys off
+// when a script asks for it.
+func applyMigrations(ctx context.Context, raw *sql.DB, d Dialect, migrations []Migration, dryRun bool) (pending []Migration, err error) {
+	conn, err := raw.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migration connection: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+	unlock, err := lockMigrations(ctx, conn, d)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	if _, err = conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (script VARCHAR(255) PRIMARY KEY, version VARCHAR(64) NOT NULL, checksum VARCHAR(64) NOT NULL, applied_at TIMESTAMP)", migrationTable)); err != nil {
+		return nil, fmt.Errorf("create %s: %w", migrationTable, err)
+	}
+	pending, err = migrateTx(ctx, conn, d, migrations, dryRun, false)
+	if !errors.Is(err, errForeignKeysOn) {
+		return pending, err
+	}
+	if _, err = conn.ExecContext(ctx, "PRAGMA foreign_keys=OFF"); err != nil {
+		return nil, fmt.Errorf("turn foreign keys off: %w", err)
+	}
+	defer func() {
+		if _, onErr := conn.ExecContext(context.WithoutCancel(ctx), "PRAGMA foreign_keys=ON"); onErr != nil && err == nil {
+			pending, err = nil, fmt.Errorf("turn foreign keys back on: %w", onErr)
+		}
+	}()
+	return migrateTx(ctx, conn, d, migrations, dryRun, true)
+}
+
+// lockMigrations serializes concurrent migrations, e.g. several instances of a
+// service starting at once, with a session lock on PostgreSQL and MySQL. SQLite
+// has no such lock; migrateTx takes its write lock first instead.
+func lockMigrations(ctx context.Context, conn *sql.Conn, d Dialect) (func(), error) {
+	var unlock string
+	switch d.Name() {
+	case postgresDriver:
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("SELECT pg_advisory_lock(hashtext('%s'))", migrationTable)); err != nil {
+			return nil, fmt.Errorf("lock %s: %w", migrationTable, err)
+		}
+		unlock = fmt.Sprintf("SELECT pg_advisory_unlock(hashtext('%s'))", migrationTable)
+	case mysqlDriver:
+		var got sql.NullInt64
+		if err := conn.QueryRowContext(ctx, fmt.Sprintf("SELECT GET_LOCK('%s', %d)", migrationTable, migrationLockTimeout)).Scan(&got); err != nil {
+			return nil, fmt.Errorf("lock %s: %w", migrationTable, err)
+		}
+		if got.Int64 != 1 {
+			return nil, fmt.Errorf("lock %s: not granted within %ds", migrationTable, migrationLockTimeout)
+		}
+		unlock = fmt.Sprintf("SELECT RELEASE_LOCK('%s')", migrationTable)
+	default:
+		return func() {}, nil
+	}
+	return func() { _, _ = conn.ExecContext(context.WithoutCancel(ctx), unlock) }, nil
+}
+
+// migrateTx reads the applied scripts and runs the pending ones in a single
+// transaction; a dry run only reports them.
+func migrateTx(ctx context.Context, conn *sql.Conn, d Dialect, migrations []Migration, dryRun, foreignKeysOff bool) (pending []Migration, err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin migration: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if d.Name() == sqliteDriver {
+		// a write as the first statement takes the database write lock, as
+		// BEGIN IMMEDIATE would, before the applied scripts are read
+		if _, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE 1 = 0", migrationTable)); err != nil {
+			return nil, fmt.Errorf("lock %s: %w", migrationTable, err)
+		}
+	}
+	applied, err := appliedChecksums(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if pending, err = pendingMigrations(migrations, applied, d.Name() == sqliteDriver); err != nil {
+		return nil, err
+	}
+	if dryRun || len(pending) == 0 {
+		_ = tx.Rollback()
+		return pending, nil
+	}
+	if !foreignKeysOff && lo.ContainsBy(pending, func(m Migration) bool { return m.foreignKeysOff }) {
+		var on bool
+		if err = tx.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&on); err != nil {
+			return nil, fmt.Errorf("read foreign_keys: %w", err)
+		}
+		if on {
+			return nil, errForeignKeysOn
+		}
+	}
+	insert := rebind(d, fmt.Sprintf("INSERT INTO %s (script, version, checksum, applied_at) VALUES (?, ?, ?, ?)", migrationTable))
+	for _, m := range pending {
+		for _, stmt := range m.Statements {
+			switch {
+			case foreignKeysPattern.MatchString(stmt):
+				// set around the transaction
+			case foreignKeyCheckPattern.MatchString(stmt):
+				err = checkForeignKeys(ctx, tx, stmt)
+			default:
+				_, err = tx.ExecContext(ctx, stmt)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("migration %s: %w", m.Name, err)
+			}
+		}
+		if _, err = tx.ExecContext(ctx, insert, m.Name, m.Version, m.Checksum, time.Now().UTC()); err != nil {
+			return nil, fmt.Errorf("record migration %s: %w", m.Name, err)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit migration: %w", err)
+	}
+	return pending, nil
+}
+
+// checkForeignKeys runs a PRAGMA foreign_key_check and fails on the first
+// violation it reports.
+func checkForeignKeys(ctx context.Context, q Querier, stmt string) error {
+	rows, err := q.QueryContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	if rows.Next() {
+		var (
+			table, parent string
+			rowid         sql.NullInt64
+			fkid          int
+		)
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		return fmt.Errorf("foreign key violation: row %d of %s references a missing row of %s", rowid.Int64, table, parent)
+	}
+	return rows.Err()
+}
+
+// appliedChecksums returns the checksums of the applied scripts by name.
+func appliedChecksums(ctx context.Context, q Querier) (map[string]string, error) {
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT script, checksum FROM %s", migrationTable))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", migrationTable, err)
+	}
+	defer func() { _ = rows.Close() }()
+	applied := map[string]string{}
+	for rows.Next() {
+		var name, checksum string
+		if err := rows.Scan(&name, &checksum); err != nil {
+			return nil, fmt.Errorf("read %s: %w", migrationTable, err)
+		}
+		applied[name] = checksum
+	}
+	return applied, rows.Err()
+}
+
+// pendingMigrations checks the applied scripts against their recorded checksums
+// and returns the others in dependency order; independent scripts are sorted by name.
+// With forwardRefs, for databases accepting foreign keys to tables created later
+// such as SQLite, a cycle of references is broken at the first script whose
+// unmet requirements are all references.
+func pendingMigrations(migrations []Migration, applied map[string]string, forwardRefs bool) ([]Migration, error) {
+	var pending []Migration
+	for _, m := range migrations {
+		checksum, ok := applied[m.Name]
+		if !ok {
+			pending = append(pending, m)
+		} else if checksum != m.Checksum {
+			return nil, fmt.Errorf("%w: %s was applied with checksum %s but is now %s", ErrChecksumMismatch, m.Name, checksum, m.Checksum)
+		}
+	}
+	slices.SortFunc(pending, func(a, b Migration) int { return strings.Compare(a.Name, b.Name) })
+	creators := map[string]string{}
+	for _, m := range pending {
+		for _, table := range m.creates {
+			creators[table] = m.Name
+		}
+	}
+	var ordered []Migration
+	done := map[string]bool{}
+	for len(pending) > 0 {
+		met := func(table string) bool {
+			creator, ok := creators[table]
+			return !ok || done[creator]
+		}
+		i := slices.IndexFunc(pending, func(m Migration) bool { return lo.EveryBy(m.requires, met) })
+		if i < 0 && forwardRefs {
+			i = slices.IndexFunc(pending, func(m Migration) bool {
+				return lo.EveryBy(m.requires, func(table string) bool { return met(table) || slices.Contains(m.references, table) })
+			})
+		}
+		if i < 0 {
+			names := lo.Map(pending, func(m Migration, _ int) string { return m.Name })
+			return nil, fmt.Errorf("migrations %s have cyclic table references", strings.Join(names, ", "))
+		}
+		done[pending[i].Name] = true
+		ordered = append(ordered, pending[i])
+		pending = slices.Delete(pending, i, i+1)
+	}
+	return ordered, nil
+}
+
+// statements splits a script into its statements, dropping comments and blank
+// lines. Semicolons inside quoted literals or identifiers do not end a statement.
+func statements(script string) []string {
+	var stmts []string
+	var b strings.Builder
+	flush := func() {
+		lines := lo.FilterMap(strings.Split(b.String(), "\n"), func(line string, _ int) (string, bool) {
+			line = strings.TrimRight(line, " \t\r")
+			return line, strings.TrimSpace(line) != ""
+		})
+		if len(lines) > 0 {
+			stmts = append(stmts, strings.Join(lines, "\n"))
+		}
+		b.Reset()
+	}
+	var quote rune
+	comment := false
+	runes := []rune(script)
+	for i, r := range runes {
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+				b.WriteRune(r)
+			}
+			continue
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			comment = true
+			continue
+		case r == ';':
+			flush()
+			continue
+		}
+		b.WriteRune(r)
+	}
+	flush()
+	return stmts
+}
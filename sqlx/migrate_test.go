@@ -0,0 +1,269 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/require"
+)
+
+// setupMigrateDS registers an empty in-memory sqlite datasource enforcing
+// foreign keys.
+func setupMigrateDS(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	raw, err := sql.Open("sqlite3", "file:"+name+"?mode=memory&cache=shared&_foreign_keys=1")
+	require.NoError(t, err)
+	dsMu.Lock()
+	dsRegistry[name] = stdDB{DB: raw, driver: "sqlite3"}
+	dsMu.Unlock()
+	t.Cleanup(func() { _ = CloseDataSource(name) })
+	return raw
+}
+
+func writeScripts(t *testing.T, dir string, scripts map[string]string) {
+	t.Helper()
+	for name, script := range scripts {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), 0o644))
+	}
+}
+
+func names(ms []Migration) []string {
+	return lo.Map(ms, func(m Migration, _ int) string { return m.Name })
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(1) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&n))
+	return n == 1
+}
+
+func TestMigrate(t *testing.T) {
+	raw := setupMigrateDS(t, "migrate_test")
+	dir := t.TempDir()
+	writeScripts(t, dir, map[string]string{
+		"a_item_schema.sql": `-- Code generated by dvo xql. DO NOT EDIT.
+-- Generated at: 2025-12-27 23:14:48 (ver: 88d0278a34)
+
+CREATE TABLE IF NOT EXISTS items (
+    id INTEGER PRIMARY KEY,
+    order_id INTEGER REFERENCES orders (id)
+);`,
+		"b_order_schema.sql": `-- Generated at: 2025-12-27 23:14:48 (ver: 1234567890)
+CREATE TABLE IF NOT EXISTS orders (id INTEGER PRIMARY KEY, note TEXT DEFAULT 'a;b');
+CREATE INDEX IF NOT EXISTS idx_orders_note ON orders (note);`,
+	})
+	ctx := context.Background()
+
+	pending, err := Migrate(ctx, "migrate_test", WithScripts(dir), DryRun())
+	require.NoError(t, err)
+	require.Equal(t, []string{"b_order_schema.sql", "a_item_schema.sql"}, names(pending))
+	require.False(t, tableExists(t, raw, "orders"))
+
+	applied, err := Migrate(ctx, "migrate_test", WithScripts(dir))
+	require.NoError(t, err)
+	require.Equal(t, []string{"b_order_schema.sql", "a_item_schema.sql"}, names(applied))
+	require.True(t, tableExists(t, raw, "orders"))
+	require.True(t, tableExists(t, raw, "items"))
+	var version string
+	require.NoError(t, raw.QueryRow("SELECT version FROM xql_migrations WHERE script = 'a_item_schema.sql'").Scan(&version))
+	require.Equal(t, "88d0278a34", version)
+
+	applied, err = Migrate(ctx, "migrate_test", WithScripts(dir))
+	require.NoError(t, err)
+	require.Empty(t, applied)
+
+	t.Run("RegeneratedHeader", func(t *testing.T) {
+		script, err := os.ReadFile(filepath.Join(dir, "b_order_schema.sql"))
+		require.NoError(t, err)
+		writeScripts(t, dir, map[string]string{
+			"b_order_schema.sql": strings.Replace(string(script), "2025-12-27 23:14:48", "2026-01-02 03:04:05", 1),
+		})
+		applied, err := Migrate(ctx, "migrate_test", WithScripts(dir))
+		require.NoError(t, err)
+		require.Empty(t, applied)
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		writeScripts(t, dir, map[string]string{
+			"b_order_schema.sql": "CREATE TABLE IF NOT EXISTS orders (id INTEGER PRIMARY KEY, note TEXT, amount REAL);",
+			"c_user_schema.sql":  "CREATE TABLE IF NOT EXISTS users (id INTEGER PRIMARY KEY);",
+		})
+		_, err := Migrate(ctx, "migrate_test", WithScripts(dir))
+		require.ErrorIs(t, err, ErrChecksumMismatch)
+		require.ErrorContains(t, err, "b_order_schema.sql")
+		require.False(t, tableExists(t, raw, "users"))
+	})
+}
+
+func TestMigrate_Rollback(t *testing.T) {
+	raw := setupMigrateDS(t, "migrate_rollback_test")
+	dir := t.TempDir()
+	writeScripts(t, dir, map[string]string{
+		"a.sql": "CREATE TABLE accounts (id INTEGER PRIMARY KEY);",
+		"b.sql": "CREATE TABLE broken (id INTEGER PRIMARY KEY,);",
+	})
+	_, err := Migrate(context.Background(), "migrate_rollback_test", WithScripts(dir))
+	require.ErrorContains(t, err, "migration b.sql")
+	require.False(t, tableExists(t, raw, "accounts"))
+	var n int
+	require.NoError(t, raw.QueryRow("SELECT COUNT(1) FROM xql_migrations").Scan(&n))
+	require.Zero(t, n)
+}
+
+func TestMigrate_Rebuild(t *testing.T) {
+	raw := setupMigrateDS(t, "migrate_rebuild_test")
+	dir := t.TempDir()
+	writeScripts(t, dir, map[string]string{
+		"a.sql": "CREATE TABLE orders (id INTEGER PRIMARY KEY, note TEXT);",
+		"b.sql": "CREATE TABLE order_items (id INTEGER PRIMARY KEY, order_id INTEGER REFERENCES orders (id) ON DELETE CASCADE);",
+	})
+	ctx := context.Background()
+	_, err := Migrate(ctx, "migrate_rebuild_test", WithScripts(dir))
+	require.NoError(t, err)
+	_, err = raw.Exec("INSERT INTO orders (id, note) VALUES (1, 'a'); INSERT INTO order_items (id, order_id) VALUES (1, 1)")
+	require.NoError(t, err)
+	count := func(table string) int {
+		var n int
+		require.NoError(t, raw.QueryRow("SELECT COUNT(1) FROM "+table).Scan(&n))
+		return n
+	}
+	rebuild := func(copyRows string) string {
+		return `PRAGMA foreign_keys=OFF;
+CREATE TABLE orders__new (id INTEGER PRIMARY KEY, note VARCHAR(64));
+` + copyRows + `
+DROP TABLE orders;
+ALTER TABLE orders__new RENAME TO orders;
+PRAGMA foreign_key_check;
+PRAGMA foreign_keys=ON;`
+	}
+
+	// a rebuild losing the referenced rows is rolled back
+	writeScripts(t, dir, map[string]string{"c.sql": rebuild("")})
+	_, err = Migrate(ctx, "migrate_rebuild_test", WithScripts(dir))
+	require.ErrorContains(t, err, "foreign key violation: row 1 of order_items references a missing row of orders")
+	require.Equal(t, 1, count("orders"))
+
+	// dropping the parent neither cascades to the children nor fails
+	writeScripts(t, dir, map[string]string{"c.sql": rebuild("INSERT INTO orders__new (id, note) SELECT id, note FROM orders;")})
+	applied, err := Migrate(ctx, "migrate_rebuild_test", WithScripts(dir))
+	require.NoError(t, err)
+	require.Equal(t, []string{"c.sql"}, names(applied))
+	require.Equal(t, 1, count("order_items"))
+
+	// foreign keys are enforced again
+	_, err = raw.Exec("DELETE FROM orders")
+	require.NoError(t, err)
+	require.Zero(t, count("order_items"))
+}
+
+func TestMigrate_Concurrent(t *testing.T) {
+	// a file database: in-memory shared cache fails on lock contention instead of waiting
+	raw, err := sql.Open("sqlite3", "file:"+filepath.Join(t.TempDir(), "migrate.db")+"?_busy_timeout=10000")
+	require.NoError(t, err)
+	dsMu.Lock()
+	dsRegistry["migrate_concurrent_test"] = stdDB{DB: raw, driver: "sqlite3"}
+	dsMu.Unlock()
+	t.Cleanup(func() { _ = CloseDataSource("migrate_concurrent_test") })
+	dir := t.TempDir()
+	writeScripts(t, dir, map[string]string{
+		"a.sql": "CREATE TABLE accounts (id INTEGER PRIMARY KEY);",
+		"b.sql": "CREATE TABLE orders (id INTEGER PRIMARY KEY, account_id INTEGER REFERENCES accounts (id));",
+	})
+	const workers = 8
+	var wg sync.WaitGroup
+	applied := make([][]Migration, workers)
+	errs := make([]error, workers)
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			applied[i], errs[i] = Migrate(context.Background(), "migrate_concurrent_test", WithScripts(dir))
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	// the scripts are applied by exactly one of the migrations
+	all := lo.Flatten(lo.Map(applied, func(ms []Migration, _ int) []string { return names(ms) }))
+	require.ElementsMatch(t, []string{"a.sql", "b.sql"}, all)
+	var n int
+	require.NoError(t, raw.QueryRow("SELECT COUNT(1) FROM xql_migrations").Scan(&n))
+	require.Equal(t, 2, n)
+}
+
+func TestMigrate_GeneratedSchemas(t *testing.T) {
+	raw := setupMigrateDS(t, "migrate_sample_test")
+	applied, err := Migrate(context.Background(), "migrate_sample_test", WithScripts(filepath.Join("..", "sample", "gen", "schemas", "sqlite")))
+	require.NoError(t, err)
+	require.NotEmpty(t, applied)
+	for _, table := range []string{"accounts", "orders", "order_items"} {
+		require.True(t, tableExists(t, raw, table), table)
+	}
+}
+
+func TestPendingMigrations(t *testing.T) {
+	parse := func(name, script string) Migration {
+		m, err := ParseMigration(name, script)
+		require.NoError(t, err)
+		return m
+	}
+	a := parse("a.sql", "CREATE TABLE a (id INT, b_id INT REFERENCES b (id))")
+	b := parse("b.sql", "CREATE TABLE b (id INT, c_id INT REFERENCES c (id))")
+	c := parse("c.sql", "CREATE TABLE c (id INT, x_id INT REFERENCES external (id))")
+
+	pending, err := pendingMigrations([]Migration{a, b, c}, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c.sql", "b.sql", "a.sql"}, names(pending))
+
+	// applied scripts satisfy the references
+	pending, err = pendingMigrations([]Migration{a, b, c}, map[string]string{"c.sql": c.Checksum}, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"b.sql", "a.sql"}, names(pending))
+
+	// a diff migration runs after the script creating the table it alters
+	alter := parse("20260101000000_alter_c.sql", "ALTER TABLE c ADD COLUMN note TEXT")
+	pending, err = pendingMigrations([]Migration{c, alter}, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{"c.sql", "20260101000000_alter_c.sql"}, names(pending))
+
+	cyclic := parse("d.sql", "CREATE TABLE c2 (id INT REFERENCES a (id)); CREATE TABLE d (id INT)")
+	a2 := parse("a.sql", "CREATE TABLE a (id INT REFERENCES c2 (id))")
+	_, err = pendingMigrations([]Migration{a2, cyclic}, nil, false)
+	require.ErrorContains(t, err, "cyclic table references")
+	// unless the database accepts references to tables created later
+	pending, err = pendingMigrations([]Migration{a2, cyclic}, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a.sql", "d.sql"}, names(pending))
+
+	// an altered table must be created first
+	a3 := parse("a.sql", "CREATE TABLE a (id INT); ALTER TABLE c3 ADD COLUMN a_id INT")
+	c3 := parse("c.sql", "CREATE TABLE c3 (id INT); ALTER TABLE a ADD COLUMN c_id INT")
+	_, err = pendingMigrations([]Migration{a3, c3}, nil, true)
+	require.ErrorContains(t, err, "cyclic table references")
+
+	_, err = ParseMigration("empty.sql", "-- nothing here\n\n")
+	require.ErrorContains(t, err, "has no statements")
+}
+
+func TestStatements(t *testing.T) {
+	script := `-- header; with a semicolon
+CREATE TABLE t (
+    name TEXT DEFAULT 'x;y', -- trailing comment
+    "a;b" INT
+);
+
+CREATE INDEX i ON t (name);
+`
+	require.Equal(t, []string{
+		"CREATE TABLE t (\n    name TEXT DEFAULT 'x;y',\n    \"a;b\" INT\n)",
+		"CREATE INDEX i ON t (name)",
+	}, statements(script))
+}
@@ -0,0 +1,209 @@
+package sqlx
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kcmvp/xql"
+)
+
+var (
+	int64Type   = reflect.TypeFor[int64]()
+	float64Type = reflect.TypeFor[float64]()
+)
+
+// timeLayouts are the textual time formats accepted for time.Time fields:
+// RFC 3339 (postgres text output), the formats written by mattn/go-sqlite3
+// and the MySQL DATETIME / DATE formats returned when parseTime is off.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02T15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// fieldType returns the Go type a column is converted to: the field's type
+// hint, or nil to keep the driver value as-is.
+func fieldType(f xql.Field) reflect.Type {
+	if f == nil {
+		return nil
+	}
+	return f.Type()
+}
+
+// aggregateType returns the Go type of an aggregate result: COUNT is an
+// int64, AVG a float64, SUM/MIN/MAX share the type of their field.
+func aggregateType(a Aggregate) reflect.Type {
+	switch a.fn {
+	case "COUNT":
+		return int64Type
+	case "AVG":
+		return float64Type
+	default:
+		return fieldType(a.field)
+	}
+}
+
+// convertValue converts a value scanned by database/sql into t.
+//
+// Drivers report the same column differently: SQLite returns TEXT time
+// columns as strings and booleans as 0/1 integers, MySQL returns []byte for
+// text and DECIMAL values, and every driver widens integers to int64. NULL
+// stays nil.
+func convertValue(v any, t reflect.Type) (any, error) {
+	if v == nil || t == nil {
+		return v, nil
+	}
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	if reflect.TypeOf(v) == t {
+		return v, nil
+	}
+	switch {
+	case t == timeType:
+		return toTime(v)
+	case t.Kind() == reflect.String:
+		return toString(v, t)
+	case t.Kind() == reflect.Bool:
+		return toBool(v, t)
+	case isInt(t.Kind()), isUint(t.Kind()), isFloat(t.Kind()):
+		return toNumber(v, t)
+	}
+	return nil, fmt.Errorf("cannot convert %T to %s", v, t)
+}
+
+func toTime(v any) (any, error) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, nil
+	case string:
+		s := strings.TrimSpace(x)
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("cannot parse %q as time", x)
+	case int64:
+		// unix epoch seconds, as stored by some SQLite schemas
+		return time.Unix(x, 0).UTC(), nil
+	}
+	return nil, fmt.Errorf("cannot convert %T to time.Time", v)
+}
+
+func toString(v any, t reflect.Type) (any, error) {
+	var s string
+	switch x := v.(type) {
+	case string:
+		s = x
+	case int64, float64, bool:
+		s = fmt.Sprint(x)
+	case time.Time:
+		s = x.Format(time.RFC3339Nano)
+	default:
+		return nil, fmt.Errorf("cannot convert %T to %s", v, t)
+	}
+	return reflect.ValueOf(s).Convert(t).Interface(), nil
+}
+
+func toBool(v any, t reflect.Type) (any, error) {
+	var b bool
+	switch x := v.(type) {
+	case bool:
+		b = x
+	case int64:
+		if x != 0 && x != 1 {
+			return nil, fmt.Errorf("cannot convert %d to bool", x)
+		}
+		b = x == 1
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(x))
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert %q to bool", x)
+		}
+		b = parsed
+	default:
+		return nil, fmt.Errorf("cannot convert %T to %s", v, t)
+	}
+	return reflect.ValueOf(b).Convert(t).Interface(), nil
+}
+
+// toNumber converts integers, floats, booleans and numeric text into the
+// numeric type t, rejecting values that would overflow or lose precision.
+func toNumber(v any, t reflect.Type) (any, error) {
+	var rv reflect.Value
+	switch x := v.(type) {
+	case string:
+		s := strings.TrimSpace(x)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			rv = reflect.ValueOf(i)
+		} else if f, err := strconv.ParseFloat(s, 64); err == nil {
+			rv = reflect.ValueOf(f)
+		} else {
+			return nil, fmt.Errorf("cannot convert %q to %s", x, t)
+		}
+	case bool:
+		rv = reflect.ValueOf(int64(0))
+		if x {
+			rv = reflect.ValueOf(int64(1))
+		}
+	default:
+		rv = reflect.ValueOf(v)
+		if k := rv.Kind(); !isInt(k) && !isUint(k) && !isFloat(k) {
+			return nil, fmt.Errorf("cannot convert %T to %s", v, t)
+		}
+	}
+	out := reflect.New(t).Elem()
+	switch k := rv.Kind(); {
+	case isFloat(t.Kind()):
+		f := rv.Convert(float64Type).Float()
+		if out.OverflowFloat(f) {
+			return nil, fmt.Errorf("value %v overflows %s", v, t)
+		}
+		out.SetFloat(f)
+	case isFloat(k):
+		f := rv.Float()
+		if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return nil, fmt.Errorf("cannot convert %v to %s without losing precision", v, t)
+		}
+		return toNumber(int64(f), t)
+	case isInt(t.Kind()):
+		i := rv.Convert(int64Type).Int()
+		if (isUint(k) && rv.Uint() > math.MaxInt64) || out.OverflowInt(i) {
+			return nil, fmt.Errorf("value %v overflows %s", v, t)
+		}
+		out.SetInt(i)
+	default: // unsigned target
+		if isInt(k) && rv.Int() < 0 {
+			return nil, fmt.Errorf("value %v overflows %s", v, t)
+		}
+		u := rv.Convert(reflect.TypeFor[uint64]()).Uint()
+		if out.OverflowUint(u) {
+			return nil, fmt.Errorf("value %v overflows %s", v, t)
+		}
+		out.SetUint(u)
+	}
+	return out.Interface(), nil
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uint64
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
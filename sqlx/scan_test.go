@@ -0,0 +1,109 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/kcmvp/xql/sample/entity"
+	"github.com/kcmvp/xql/sample/gen/field/order"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertValue(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	cases := []struct {
+		name string
+		v    any
+		t    reflect.Type
+		exp  any
+	}{
+		{"Nil", nil, reflect.TypeFor[int64](), nil},
+		{"SameType", int64(7), reflect.TypeFor[int64](), int64(7)},
+		{"Int64ToInt32", int64(7), reflect.TypeFor[int32](), int32(7)},
+		{"Int64ToUint8", int64(200), reflect.TypeFor[uint8](), uint8(200)},
+		{"Int64ToFloat", int64(3), reflect.TypeFor[float64](), 3.0},
+		{"WholeFloatToInt", 40.0, reflect.TypeFor[int64](), int64(40)},
+		{"DecimalBytesToFloat", []byte("12.50"), reflect.TypeFor[float64](), 12.5},
+		{"DecimalBytesToInt", []byte("40.00"), reflect.TypeFor[int64](), int64(40)},
+		{"FloatToFloat32", 1.5, reflect.TypeFor[float32](), float32(1.5)},
+		{"BytesToString", []byte("abc"), reflect.TypeFor[string](), "abc"},
+		{"IntToString", int64(5), reflect.TypeFor[string](), "5"},
+		{"IntToBool", int64(1), reflect.TypeFor[bool](), true},
+		{"ZeroToBool", int64(0), reflect.TypeFor[bool](), false},
+		{"TextToBool", "true", reflect.TypeFor[bool](), true},
+		{"SQLiteText", "2024-01-02 03:04:05", timeType, ts},
+		{"SQLiteTextTZ", "2024-01-02 03:04:05+00:00", timeType, ts},
+		{"RFC3339", "2024-01-02T03:04:05Z", timeType, ts},
+		{"MySQLBytes", []byte("2024-01-02 03:04:05"), timeType, ts},
+		{"Date", "2024-01-02", timeType, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"UnixSeconds", ts.Unix(), timeType, ts},
+		{"Time", ts, timeType, ts},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := convertValue(c.v, c.t)
+			require.NoError(t, err)
+			if exp, ok := c.exp.(time.Time); ok {
+				require.True(t, exp.Equal(got.(time.Time)), "got %v", got)
+				return
+			}
+			require.Equal(t, c.exp, got)
+		})
+	}
+
+	errCases := []struct {
+		name string
+		v    any
+		t    reflect.Type
+	}{
+		{"Overflow", int64(300), reflect.TypeFor[uint8]()},
+		{"Negative", int64(-1), reflect.TypeFor[uint64]()},
+		{"Fraction", 1.5, reflect.TypeFor[int64]()},
+		{"NotNumber", "abc", reflect.TypeFor[int64]()},
+		{"NotBool", int64(2), reflect.TypeFor[bool]()},
+		{"NotTime", "yesterday", timeType},
+	}
+	for _, c := range errCases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := convertValue(c.v, c.t)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestQuery_TypedResults_SQLite(t *testing.T) {
+	db, err := sql.Open("sqlite3", "file:typed_results?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	// TEXT time columns are returned as strings by the sqlite driver
+	for _, stmt := range []string{
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, account_id TEXT, amount INTEGER, created_at TEXT, updated_at TEXT, created_by TEXT, updated_by TEXT)",
+		"INSERT INTO orders (id, account_id, amount, created_at, created_by) VALUES (1, '10', 25, '2024-03-01 10:00:00', 'ann'), (2, '10', 30, '2024-03-02T11:30:00Z', NULL)",
+	} {
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	ctx := context.Background()
+
+	res, err := Query[Order](Schema{order.ID, order.AccountID, order.Amount, order.CreatedAt, order.CreatedBy})(nil).OrderBy(order.ID, Asc).Execute(ctx, db)
+	require.NoError(t, err)
+	items := res.MustLeft()
+	require.Len(t, items, 2)
+	require.Equal(t, int64(10), items[0].MstInt64("account_id"))
+	require.Equal(t, 25.0, items[0].MstFloat64("amount"))
+	require.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), items[0].MstTime("created_at"))
+	require.Equal(t, "ann", items[0].MstString("created_by"))
+	require.True(t, items[1].MstTime("created_at").Equal(time.Date(2024, 3, 2, 11, 30, 0, 0, time.UTC)))
+	require.Contains(t, items[1].Fields(), "created_by") // NULL is kept as a nil value
+
+	res, err = Query[Order](nil)(nil).Aggregate(Max(order.CreatedAt), Sum(order.Amount), Avg(order.Amount), Count(nil)).Execute(ctx, db)
+	require.NoError(t, err)
+	agg := res.MustLeft()[0]
+	require.Equal(t, time.Date(2024, 3, 2, 11, 30, 0, 0, time.UTC), agg.MstTime("max_created_at"))
+	require.Equal(t, 55.0, agg.MstFloat64("sum_amount"))
+	require.Equal(t, 27.5, agg.MstFloat64("avg_amount"))
+	require.Equal(t, int64(2), agg.MstInt64("count"))
+}
@@ -23,7 +23,7 @@ import (
 //    helpers (e.g. `selectSQL`, `insertSQL`, `updateSQL`, `deleteSQL`) so
 //    generation and execution responsibilities are clearly separated.
 //  - Public API is tiny: consumers construct `Executor` via `Query` /
-//    `Delete` / `Update` factory functions and call `Execute(ctx, db)` to
+//    `Insert` / `Delete` / `Update` factory functions and call `Execute(ctx, db)` to
 //    run the statement. `Execute` returns a union `mo.Either` value where
 //    the left side is `[]meta.ValueObject` (SELECT results) and the right
 //    side is `sql.Result` (non-query statements).
@@ -33,7 +33,7 @@ import (
 //
 // Important helper contracts (implemented in this package):
 //  - selectSQL[T](schema *meta.Schema, where Where) (string, []any, error)
-//  - insertSQL[T](schema meta.Schema, g getter) (string, []any, error)
+//  - insertSQL[T](rows []meta.ValueObject, returning string) (string, []any, error)
 //  - updateSQL[T](schema meta.Schema, g getter, where Where) (string, []any, error)
 //  - deleteSQL[T](where Where) (string, []any, error)
 //
@@ -99,14 +99,62 @@ func Like(field xql.Field, value string) Where {
 	return op(field, "LIKE", value)
 }
 
+// ILike builds a case-insensitive LIKE predicate: "field ILIKE ?" on
+// postgres, "LOWER(field) LIKE LOWER(?)" on other dialects.
+func ILike(field xql.Field, value string) Where {
+	return iLikeWhere(field, value)
+}
+
 // In builds a "field IN (?, ?, ...)" predicate.
 // Empty values produce an always-false clause (1=0).
+//
+// A single QueryExecutor value builds "field IN (SELECT ...)" instead. The
+// subquery must project exactly one schema field of the same generated type
+// as field; its arguments are bound together with the outer statement.
+//
+//	In(account.ID, Query[Order](Schema{order.AccountID})(Gt(order.Amount, 100)))
 func In(field xql.Field, values ...any) Where {
 	return inWhere(field, values...)
 }
 
+// NotIn builds a "field NOT IN (?, ?, ...)" predicate; like In it accepts a
+// single QueryExecutor as subquery. Empty values produce an always-true
+// clause (1=1). Note that NOT IN never matches when the list or the subquery
+// contains NULL.
+func NotIn(field xql.Field, values ...any) Where {
+	return notInWhere(field, values...)
+}
+
+// IsNull builds a "field IS NULL" predicate.
+func IsNull(field xql.Field) Where {
+	return nullWhere(field, "IS NULL")
+}
+
+// IsNotNull builds a "field IS NOT NULL" predicate.
+func IsNotNull(field xql.Field) Where {
+	return nullWhere(field, "IS NOT NULL")
+}
+
+// Between builds a "field BETWEEN ? AND ?" predicate; both bounds are inclusive.
+func Between(field xql.Field, low, high any) Where {
+	return betweenWhere(field, low, high)
+}
+
+// Not negates a Where: "NOT (<where>)". A nil or empty Where stays empty.
+func Not(where Where) Where {
+	return not(where)
+}
+
+// Exists builds an "EXISTS (SELECT ...)" predicate from a Query or QueryJoin
+// executor. Correlate it with the outer query through EqField:
+//
+//	Exists(Query[Order](Schema{order.ID})(EqField(order.AccountID, account.ID)))
+func Exists(sub QueryExecutor) Where {
+	return existsWhere(sub)
+}
+
 // Executor represents the delayed execution step constructed by the
-// top-level factory helpers (`Query`, `Delete`, `Update`).
+// top-level factory helpers (`Query`, `Insert`, `Delete`, `Update`).
 //
 // Execution contract:
 //   - Callers obtain an Executor via one of the factory functions and then
@@ -120,16 +168,62 @@ func In(field xql.Field, values ...any) Where {
 //   - For SELECT statements the left side of `mo.Either` holds the
 //     `[]meta.ValueObject` results; for non-SELECT statements the right
 //     side holds the `sql.Result`.
+//   - `ds` is any Querier: a `*sql.DB`, `*sql.Tx`, `*sql.Conn` or a
+//     datasource obtained from `GetDS` / `DefaultDS`.
 //
 // Note: `sql()` is kept pure and only returns the generated SQL string and
 // an error. It is primarily useful for testing and inspection.
 type Executor interface {
-	Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]ValueObject, sql.Result], error)
+	Execute(ctx context.Context, ds Querier) (mo.Either[[]ValueObject, sql.Result], error)
 	// sql generates the SQL string only (pure). Arguments are produced by lower-level helpers
 	// (selectSQL/insertSQL/updateSQL/deleteSQL) and consumed by Execute when running against DB.
 	sql() (string, error)
 }
 
+// SortDirection is the direction of an ORDER BY term.
+type SortDirection int
+
+const (
+	Asc SortDirection = iota
+	Desc
+)
+
+// QueryExecutor is the Executor returned by Query and QueryJoin. Its fluent
+// options return a new QueryExecutor and leave the receiver unchanged.
+//
+//   - OrderBy appends an ORDER BY term; call it repeatedly for multi-column sorts.
+//   - Limit and Offset page the result; the clause is rendered per dialect.
+//     Execute fails on a negative value.
+//   - After switches to keyset pagination: it takes one cursor value per
+//     OrderBy term (typically the values of the last row of the previous page)
+//     and only returns rows that sort after them. Include a unique field (e.g.
+//     the primary key) as the last OrderBy term to get a stable order.
+//   - Aggregate appends aggregate projections (Count, Sum, ...) after the
+//     schema fields; their values are keyed by Aggregate.Alias() in results,
+//     which must differ from the names of the fields.
+//     The schema may be empty when aggregates are projected.
+//   - GroupBy appends GROUP BY fields. In a grouped query (aggregates or
+//     GROUP BY present) every schema field must appear in GROUP BY.
+//   - Having sets the HAVING predicate, typically built from the aggregate
+//     comparison methods, e.g. Sum(order.Amount).Gt(100).
+//
+// A QueryExecutor can also be used as a subquery with In, NotIn and Exists.
+type QueryExecutor interface {
+	Executor
+	OrderBy(field xql.Field, dir SortDirection) QueryExecutor
+	Limit(n int) QueryExecutor
+	Offset(n int) QueryExecutor
+	After(values ...any) QueryExecutor
+	Aggregate(aggs ...Aggregate) QueryExecutor
+	GroupBy(fields ...xql.Field) QueryExecutor
+	Having(where Where) QueryExecutor
+	// subquery renders the SELECT with `?` placeholders for embedding in
+	// In, NotIn and Exists.
+	subquery(d Dialect) (string, []any, error)
+	// columns returns the projected fields and aggregates.
+	columns() (Schema, []Aggregate)
+}
+
 // Query builds a single-table SELECT query.
 //
 // Usage example:
@@ -139,12 +233,61 @@ type Executor interface {
 //	// run
 //	resEither, err := exec.Execute(ctx, db)
 //	// check left/right and handle accordingly
-func Query[T entity.Entity](schema Schema) func(where Where) Executor {
-	return func(where Where) Executor {
+//
+//	// sorted and paged
+//	page := Query[Order](schema)(nil).OrderBy(order.CreatedAt, Desc).OrderBy(order.ID, Desc).Limit(20)
+//	next := page.After(lastCreatedAt, lastID)
+//
+//	// totals per account
+//	totals := Query[Order](Schema{order.AccountID})(nil).
+//		Aggregate(Count(nil), Sum(order.Amount)).
+//		GroupBy(order.AccountID).
+//		Having(Sum(order.Amount).Gt(100))
+func Query[T entity.Entity](schema Schema) func(where Where) QueryExecutor {
+	return func(where Where) QueryExecutor {
 		return queryExec[T]{schema: schema, where: where}
 	}
 }
 
+// Insert builds a single-row INSERT statement for entity T.
+//
+// Columns are derived from the payload the same way as for Update: a
+// "__schema" entry selects the candidate fields, otherwise the ValueObject's
+// own Fields() are used. Only columns that carry a value are inserted, so an
+// auto-generated primary key can simply be omitted.
+//
+// The right side of the Execute result implements InsertResult.
+func Insert[T entity.Entity](values ValueObject) Executor {
+	return insertExec[T]{rows: []ValueObject{values}}
+}
+
+// InsertMany builds a multi-row INSERT statement for entity T. All rows must
+// provide the same set of columns.
+//
+// Usage example:
+//
+//	exec := InsertMany[Order](vo1, vo2)
+//	resEither, err := exec.Execute(ctx, db)
+//	keys := resEither.MustRight().(InsertResult).GeneratedKeys()
+func InsertMany[T entity.Entity](values ...ValueObject) Executor {
+	return insertExec[T]{rows: values}
+}
+
+// InsertResult is the sql.Result returned by Insert and InsertMany executors.
+//
+// GeneratedKeys returns the primary key of every inserted row, in insertion
+// order:
+//   - Postgres: values read back through a "RETURNING <pk>" clause;
+//   - MySQL/SQLite: derived from LastInsertId (ids of a multi-row insert are
+//     consecutive);
+//   - when the payload supplies the primary key explicitly, those values.
+//
+// Entities without an `xql:"pk"` field report no generated keys.
+type InsertResult interface {
+	sql.Result
+	GeneratedKeys() []any
+}
+
 // Delete builds a single-table DELETE query.
 //
 // Note: per design, callers should provide a non-empty where clause; the
@@ -159,34 +302,45 @@ func Delete[T entity.Entity](where Where) Executor {
 // New design: public Update accepts the update payload as a meta.ValueObject;
 // the ValueObject may include a special "__schema" entry or provide its own
 // Fields() listing. This avoids a global runtime schema registry.
+// Explicit nulls in the payload, e.g. from a view.Field(...).Nullable() PATCH
+// field, clear the column with "SET col = NULL"; absent fields are untouched.
 func Update[T entity.Entity](values ValueObject) func(where Where) Executor {
 	return func(where Where) Executor {
 		return updateExec[T]{values: values, where: where}
 	}
 }
 
-// QueryJoin builds a select executor that injects `joinstmt` into the FROM
-// clause. The returned Executor follows the existing `Executor` contract.
-func QueryJoin(schema Schema) func(joinstmt string, where Where) Executor {
-	return func(joinstmt string, where Where) Executor {
-		return joinQueryExec{schema: schema, joinstmt: joinstmt, where: where}
+// QueryJoin builds a select executor over a typed Join. The FROM clause is
+// rendered from the join; every projected (and aggregated, grouped or
+// ordered) field must belong to one of the joined tables.
+//
+// Usage example:
+//
+//	exec := QueryJoin(schema)(InnerJoin(order.AccountID, account.ID), Eq(account.Email, email))
+func QueryJoin(schema Schema) func(join Join, where Where) QueryExecutor {
+	return func(join Join, where Where) QueryExecutor {
+		return joinQueryExec{schema: schema, join: join, where: where}
 	}
 }
 
 // DeleteJoin builds a delete executor that uses an EXISTS-correlated subquery
-// to apply the join-based filter. It derives base table from generic type T.
-func DeleteJoin[T entity.Entity](joinstmt string, where Where) Executor {
-	var ent T
-	baseTable := ent.Table()
-	return joinDeleteExec{baseTable: baseTable, joinstmt: joinstmt, where: where}
+// to apply the join-based filter. The join must start from the table of T and
+// consist of inner joins only.
+//
+// Usage example:
+//
+//	exec := DeleteJoin[Order](InnerJoin(order.AccountID, account.ID), Eq(account.Email, email))
+func DeleteJoin[T entity.Entity](join Join, where Where) Executor {
+	return joinDeleteExec[T]{join: join, where: where}
 }
 
 // UpdateJoin builds an update executor that applies an EXISTS-correlated
 // join filter. The update payload values are supplied as a meta.ValueObject
-// when creating the executor via UpdateJoin[T](values)(joinstmt, where).
-func UpdateJoin[T entity.Entity](values ValueObject) func(joinstmt string, where Where) Executor {
-	return func(joinstmt string, where Where) Executor {
-		return updateJoinExec[T]{values: values, joinstmt: joinstmt, where: where}
+// when creating the executor via UpdateJoin[T](values)(join, where). The join
+// must start from the table of T and consist of inner joins only.
+func UpdateJoin[T entity.Entity](values ValueObject) func(join Join, where Where) Executor {
+	return func(join Join, where Where) Executor {
+		return updateJoinExec[T]{values: values, join: join, where: where}
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/kcmvp/xql"
@@ -16,21 +17,63 @@ import (
 // This file contains package-private helpers used by the public `sqlx` API.
 // See sqlx.go for higher-level executors and public APIs.
 
-type whereFunc func() (string, []any)
+// whereFunc adapts a dialect-aware closure into a Where. Build renders the
+// clause with the default dialect; executors call build with the dialect of
+// the target database.
+type whereFunc func(d Dialect) (string, []any)
 
 func (f whereFunc) Build() (string, []any) {
-	return f()
+	return f(defaultDialect)
+}
+
+func (f whereFunc) build(d Dialect) (string, []any) {
+	return f(d)
+}
+
+// buildWhere renders w through d. Where implementations outside this package
+// only provide Build() and are rendered as-is.
+func buildWhere(w Where, d Dialect) (string, []any) {
+	if w == nil {
+		return "", nil
+	}
+	if dw, ok := w.(interface {
+		build(d Dialect) (string, []any)
+	}); ok {
+		return dw.build(d)
+	}
+	return w.Build()
+}
+
+// invalidWhere is a predicate that could not be built, e.g. In with an invalid
+// subquery. It renders nothing; the statements using it fail with its error.
+type invalidWhere struct {
+	cause error
+}
+
+func (w invalidWhere) Build() (string, []any) {
+	return "", nil
+}
+
+// whereErr returns the error of the first predicate of wheres that could not
+// be built. Predicates combining others fail with their first error.
+func whereErr(wheres ...Where) error {
+	for _, w := range wheres {
+		if iw, ok := w.(invalidWhere); ok {
+			return iw.cause
+		}
+	}
+	return nil
 }
 
 func and(wheres ...Where) Where {
-	f := func() (string, []any) {
+	if err := whereErr(wheres...); err != nil {
+		return invalidWhere{err}
+	}
+	f := func(d Dialect) (string, []any) {
 		clauses := make([]string, 0, len(wheres))
 		var allArgs []any
 		for _, w := range wheres {
-			if w == nil {
-				continue
-			}
-			clause, args := w.Build()
+			clause, args := buildWhere(w, d)
 			if clause == "" {
 				continue
 			}
@@ -46,14 +89,14 @@ func and(wheres ...Where) Where {
 }
 
 func or(wheres ...Where) Where {
-	f := func() (string, []any) {
+	if err := whereErr(wheres...); err != nil {
+		return invalidWhere{err}
+	}
+	f := func(d Dialect) (string, []any) {
 		clauses := make([]string, 0, len(wheres))
 		var allArgs []any
 		for _, w := range wheres {
-			if w == nil {
-				continue
-			}
-			clause, args := w.Build()
+			clause, args := buildWhere(w, d)
 			if clause == "" {
 				continue
 			}
@@ -68,16 +111,6 @@ func or(wheres ...Where) Where {
 	return whereFunc(f)
 }
 
-func dbQualifiedNameFromQName(q string) string {
-	parts := strings.Split(q, ".")
-	if len(parts) != 2 {
-		return q
-	}
-	table := parts[0]
-	col := parts[1]
-	return fmt.Sprintf("%s.%s", table, col)
-}
-
 func makePlaceholders(n int) string {
 	if n <= 0 {
 		return ""
@@ -90,68 +123,494 @@ func makePlaceholders(n int) string {
 }
 
 func op(field xql.Field, operator string, value any) Where {
-	f := func() (string, []any) {
-		clause := fmt.Sprintf("%s %s ?", dbQualifiedNameFromQName(field.QualifiedName()), operator)
+	f := func(d Dialect) (string, []any) {
+		clause := fmt.Sprintf("%s %s ?", qualify(d, field.QualifiedName()), operator)
 		return clause, []any{value}
 	}
 	return whereFunc(f)
 }
 
 func inWhere(field xql.Field, values ...any) Where {
+	if sub, ok := subqueryOf(values); ok {
+		return inSubquery(field, "IN", sub)
+	}
+	if len(values) == 0 {
+		return whereFunc(func(Dialect) (string, []any) { return "1=0", nil })
+	}
+	placeholders := makePlaceholders(len(values))
+	return whereFunc(func(d Dialect) (string, []any) {
+		return fmt.Sprintf("%s IN (%s)", qualify(d, field.QualifiedName()), placeholders), values
+	})
+}
+
+func notInWhere(field xql.Field, values ...any) Where {
+	if sub, ok := subqueryOf(values); ok {
+		return inSubquery(field, "NOT IN", sub)
+	}
 	if len(values) == 0 {
-		return whereFunc(func() (string, []any) { return "1=0", nil })
+		return whereFunc(func(Dialect) (string, []any) { return "1=1", nil })
 	}
 	placeholders := makePlaceholders(len(values))
-	clause := fmt.Sprintf("%s IN (%s)", dbQualifiedNameFromQName(field.QualifiedName()), placeholders)
-	return whereFunc(func() (string, []any) { return clause, values })
+	return whereFunc(func(d Dialect) (string, []any) {
+		return fmt.Sprintf("%s NOT IN (%s)", qualify(d, field.QualifiedName()), placeholders), values
+	})
+}
+
+func nullWhere(field xql.Field, operator string) Where {
+	return whereFunc(func(d Dialect) (string, []any) {
+		return fmt.Sprintf("%s %s", qualify(d, field.QualifiedName()), operator), nil
+	})
+}
+
+func betweenWhere(field xql.Field, low, high any) Where {
+	return whereFunc(func(d Dialect) (string, []any) {
+		return fmt.Sprintf("%s BETWEEN ? AND ?", qualify(d, field.QualifiedName())), []any{low, high}
+	})
+}
+
+func iLikeWhere(field xql.Field, value string) Where {
+	return whereFunc(func(d Dialect) (string, []any) {
+		return d.ILike(qualify(d, field.QualifiedName())), []any{value}
+	})
+}
+
+func not(w Where) Where {
+	if err := whereErr(w); err != nil {
+		return invalidWhere{err}
+	}
+	return whereFunc(func(d Dialect) (string, []any) {
+		clause, args := buildWhere(w, d)
+		if clause == "" {
+			return "", nil
+		}
+		if !enclosed(clause) {
+			clause = "(" + clause + ")"
+		}
+		return "NOT " + clause, args
+	})
+}
+
+// enclosed reports whether clause is wrapped in a single pair of parentheses,
+// e.g. "(a AND b)" but not "(a) OR (b)". Quoted text is skipped.
+func enclosed(clause string) bool {
+	if !strings.HasPrefix(clause, "(") || !strings.HasSuffix(clause, ")") {
+		return false
+	}
+	depth := 0
+	var quote rune
+	for i, r := range clause {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 && i < len(clause)-1 {
+				return false
+			}
+		}
+	}
+	return depth == 0
+}
+
+// subqueryOf reports whether the values of an IN predicate are a single subquery.
+func subqueryOf(values []any) (QueryExecutor, bool) {
+	if len(values) != 1 {
+		return nil, false
+	}
+	sub, ok := values[0].(QueryExecutor)
+	return sub, ok
+}
+
+// checkSubquery renders sub once when the predicate is built, so an invalid
+// subquery fails the statement using the predicate.
+func checkSubquery(sub QueryExecutor) error {
+	if sub == nil {
+		return fmt.Errorf("subquery must not be nil")
+	}
+	if _, _, err := sub.subquery(defaultDialect); err != nil {
+		return fmt.Errorf("invalid subquery: %w", err)
+	}
+	return nil
+}
+
+// renderSubquery renders a subquery accepted by checkSubquery; the dialect does
+// not change whether it renders.
+func renderSubquery(sub QueryExecutor, d Dialect) (string, []any) {
+	q, args, _ := sub.subquery(d)
+	return q, args
+}
+
+// inSubquery builds "field [NOT] IN (SELECT ...)". The subquery must project
+// exactly one field, generated with the same Go type as field.
+func inSubquery(field xql.Field, operator string, sub QueryExecutor) Where {
+	if err := checkSubquery(sub); err != nil {
+		return invalidWhere{err}
+	}
+	schema, aggs := sub.columns()
+	if len(schema) != 1 || len(aggs) != 0 {
+		return invalidWhere{fmt.Errorf("subquery for %s must project exactly one field", field.QualifiedName())}
+	}
+	if reflect.TypeOf(schema[0]) != reflect.TypeOf(field) {
+		return invalidWhere{fmt.Errorf("subquery field %s does not match the type of %s", schema[0].QualifiedName(), field.QualifiedName())}
+	}
+	return whereFunc(func(d Dialect) (string, []any) {
+		q, args := renderSubquery(sub, d)
+		return fmt.Sprintf("%s %s (%s)", qualify(d, field.QualifiedName()), operator, q), args
+	})
+}
+
+func existsWhere(sub QueryExecutor) Where {
+	if err := checkSubquery(sub); err != nil {
+		return invalidWhere{err}
+	}
+	return whereFunc(func(d Dialect) (string, []any) {
+		q, args := renderSubquery(sub, d)
+		return fmt.Sprintf("EXISTS (%s)", q), args
+	})
+}
+
+// orderTerm is a single ORDER BY term.
+type orderTerm struct {
+	field xql.Field
+	dir   SortDirection
+}
+
+// queryOpts holds the optional clauses of a SELECT: aggregates, GROUP BY,
+// HAVING, ORDER BY, LIMIT / OFFSET and the keyset cursor.
+type queryOpts struct {
+	aggs    []Aggregate
+	groupBy []xql.Field
+	having  Where
+	orders  []orderTerm
+	limit   int
+	offset  int
+	after   []any
+}
+
+func (p queryOpts) aggregate(aggs []Aggregate) queryOpts {
+	p.aggs = append(append([]Aggregate{}, p.aggs...), aggs...)
+	return p
+}
+
+func (p queryOpts) withGroupBy(fields []xql.Field) queryOpts {
+	lo.Assert(!lo.Contains(fields, nil), "group by field must not be nil")
+	p.groupBy = append(append([]xql.Field{}, p.groupBy...), fields...)
+	return p
+}
+
+func (p queryOpts) withHaving(w Where) queryOpts {
+	p.having = w
+	return p
+}
+
+// validate checks the grouping rules against the plain projected fields:
+//   - the result keys, the names of the fields and the aliases of the
+//     aggregates, are unique;
+//   - in a grouped query every plain field appears in GROUP BY;
+//   - HAVING is only allowed in a grouped query;
+//   - LIMIT and OFFSET are not negative.
+func (p queryOpts) validate(schema Schema) error {
+	if p.limit < 0 {
+		return fmt.Errorf("limit must not be negative: %d", p.limit)
+	}
+	if p.offset < 0 {
+		return fmt.Errorf("offset must not be negative: %d", p.offset)
+	}
+	// the keys of the result ValueObjects, see rowsToValueObjects
+	seen := map[string]string{}
+	for _, f := range schema {
+		if other, ok := seen[f.Name()]; ok {
+			return fmt.Errorf("duplicate result key %s: %s and %s", f.Name(), other, f.QualifiedName())
+		}
+		seen[f.Name()] = f.QualifiedName()
+	}
+	for _, a := range p.aggs {
+		if other, ok := seen[a.Alias()]; ok {
+			return fmt.Errorf("duplicate result key %s: %s and aggregate %s", a.Alias(), other, a.Alias())
+		}
+		seen[a.Alias()] = "aggregate " + a.Alias()
+	}
+	grouped := len(p.aggs) > 0 || len(p.groupBy) > 0
+	if p.having != nil && !grouped {
+		return fmt.Errorf("having requires group by or aggregates")
+	}
+	if !grouped {
+		return nil
+	}
+	grouping := lo.SliceToMap(p.groupBy, func(f xql.Field) (string, bool) { return f.QualifiedName(), true })
+	for _, f := range schema {
+		if !grouping[f.QualifiedName()] {
+			return fmt.Errorf("field %s must appear in group by", f.QualifiedName())
+		}
+	}
+	return nil
+}
+
+func (p queryOpts) orderBy(field xql.Field, dir SortDirection) queryOpts {
+	lo.Assert(field != nil, "order by field must not be nil")
+	p.orders = append(append([]orderTerm{}, p.orders...), orderTerm{field: field, dir: dir})
+	return p
+}
+
+func (p queryOpts) withLimit(n int) queryOpts {
+	p.limit = n
+	return p
+}
+
+func (p queryOpts) withOffset(n int) queryOpts {
+	p.offset = n
+	return p
+}
+
+func (p queryOpts) withAfter(values []any) queryOpts {
+	p.after = append([]any{}, values...)
+	return p
+}
+
+// where combines w with the keyset predicate when After is used. For order
+// terms t1..tn and cursor values v1..vn the predicate is
+//
+//	(t1 > v1) OR (t1 = v1 AND t2 > v2) OR ...
+//
+// where ">" becomes "<" for descending terms. It is portable across dialects,
+// unlike row-value comparisons which cannot mix directions.
+func (p queryOpts) where(w Where) (Where, error) {
+	if p.after == nil {
+		return w, nil
+	}
+	if len(p.orders) == 0 {
+		return nil, fmt.Errorf("after requires order by")
+	}
+	if len(p.after) != len(p.orders) {
+		return nil, fmt.Errorf("after requires %d cursor values, got %d", len(p.orders), len(p.after))
+	}
+	ors := make([]Where, 0, len(p.orders))
+	for i, o := range p.orders {
+		ands := make([]Where, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, op(p.orders[j].field, "=", p.after[j]))
+		}
+		ands = append(ands, op(o.field, lo.Ternary(o.dir == Desc, "<", ">"), p.after[i]))
+		if len(ands) == 1 {
+			ors = append(ors, ands[0])
+		} else {
+			ors = append(ors, and(ands...))
+		}
+	}
+	keyset := ors[0]
+	if len(ors) > 1 {
+		keyset = or(ors...)
+	}
+	if w == nil {
+		return keyset, nil
+	}
+	return and(w, keyset), nil
+}
+
+// suffix renders the ORDER BY and LIMIT/OFFSET clauses, with a leading space.
+func (p queryOpts) suffix(d Dialect) string {
+	var sb strings.Builder
+	if len(p.orders) > 0 {
+		terms := lo.Map(p.orders, func(o orderTerm, _ int) string {
+			return qualify(d, o.field.QualifiedName()) + lo.Ternary(o.dir == Desc, " DESC", " ASC")
+		})
+		sb.WriteString(" ORDER BY " + strings.Join(terms, ", "))
+	}
+	if lim := d.LimitOffset(p.limit, p.offset); lim != "" {
+		sb.WriteString(" " + lim)
+	}
+	return sb.String()
 }
 
-func selectSQL[T entity.Entity](schema *Schema, where Where) (string, []any, error) {
+func selectSQL[T entity.Entity](d Dialect, schema *Schema, where Where, opts queryOpts) (string, []any, error) {
 	if schema == nil {
 		return "", nil, fmt.Errorf("schema is required")
 	}
-	if len(*schema) == 0 {
+	if len(*schema) == 0 && len(opts.aggs) == 0 {
 		return "", nil, fmt.Errorf("schema has no fields")
 	}
 
+	q, args, err := tableSelect[T](d, *schema, where, opts)
+	if err != nil {
+		return "", nil, err
+	}
+	return rebind(d, q), args, nil
+}
+
+// tableSelect renders the SELECT of a single-table query with `?`
+// placeholders; it is shared by selectSQL and subqueries.
+func tableSelect[T entity.Entity](d Dialect, schema Schema, where Where, opts queryOpts) (string, []any, error) {
 	var ent T
 	table := ent.Table()
 	if strings.TrimSpace(table) == "" {
 		return "", nil, fmt.Errorf("entity table is empty")
 	}
+	return selectStatement(d, schema, d.Quote(table), nil, where, opts)
+}
 
-	cols := make([]string, 0, len(*schema))
-	for _, f := range *schema {
-		q := dbQualifiedNameFromQName(f.QualifiedName())
-		parts := strings.Split(q, ".")
-		alias := ""
-		if len(parts) == 2 {
-			alias = fmt.Sprintf("%s__%s", parts[0], parts[1])
-		} else {
-			alias = q
+// selectStatement renders a complete SELECT over from (a table, optionally
+// followed by joins whose ON arguments are fromArgs). Placeholders are left
+// as `?` so the statement can be embedded as a subquery; callers rebind the
+// outermost statement for d.
+func selectStatement(d Dialect, schema Schema, from string, fromArgs []any, where Where, opts queryOpts) (string, []any, error) {
+	if err := opts.validate(schema); err != nil {
+		return "", nil, err
+	}
+	where, err := opts.where(where)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := whereErr(where, opts.having); err != nil {
+		return "", nil, err
+	}
+	sqlStr := fmt.Sprintf("SELECT %s FROM %s", projection(d, schema, opts.aggs), from)
+	args := append([]any{}, fromArgs...)
+	if clause, wargs := buildWhere(where, d); clause != "" {
+		sqlStr += " WHERE " + clause
+		args = append(args, wargs...)
+	}
+	if len(opts.groupBy) > 0 {
+		cols := lo.Map(opts.groupBy, func(f xql.Field, _ int) string { return qualify(d, f.QualifiedName()) })
+		sqlStr += " GROUP BY " + strings.Join(cols, ", ")
+	}
+	if having, hargs := buildWhere(opts.having, d); having != "" {
+		sqlStr += " HAVING " + having
+		args = append(args, hargs...)
+	}
+	return sqlStr + opts.suffix(d), args, nil
+}
+
+// projection renders the select list: every field is projected by its
+// qualified name and aliased as "table__column" for rowsToValueObjects,
+// followed by the aggregates under their Alias().
+func projection(d Dialect, schema Schema, aggs []Aggregate) string {
+	cols := make([]string, 0, len(schema)+len(aggs))
+	for _, f := range schema {
+		qname := f.QualifiedName()
+		alias := strings.ReplaceAll(qname, ".", "__")
+		cols = append(cols, fmt.Sprintf("%s AS %s", qualify(d, qname), d.Quote(alias)))
+	}
+	for _, a := range aggs {
+		cols = append(cols, fmt.Sprintf("%s AS %s", a.expr(d), d.Quote(a.Alias())))
+	}
+	return strings.Join(cols, ", ")
+}
+
+// insertSQL builds a (multi-row) INSERT statement for entity T.
+//
+// Column policy:
+//   - If the first row carries a "__schema" entry, the schema determines the
+//     candidate columns (in schema order); otherwise the row's own Fields()
+//     are used, converted to snake_case column names.
+//   - Only columns that hold a value in the first row are inserted, so an
+//     auto-generated primary key is simply left out of the payload.
+//   - Every following row must provide exactly the same set of columns.
+//
+// When returning is non-empty a "RETURNING <column>" clause is appended.
+func insertSQL[T entity.Entity](d Dialect, rows []ValueObject, returning string) (string, []any, error) {
+	if len(rows) == 0 {
+		return "", nil, fmt.Errorf("values is required")
+	}
+	var ent T
+	table := ent.Table()
+	if strings.TrimSpace(table) == "" {
+		return "", nil, fmt.Errorf("entity table is empty")
+	}
+	first := rows[0]
+	if first == nil {
+		return "", nil, fmt.Errorf("values is required")
+	}
+
+	// keys are the ValueObject keys of the inserted columns, cols the DB column names.
+	var keys, cols []string
+	if schema := schemaOf(first); len(schema) > 0 {
+		for _, f := range schema {
+			if f.Scope() != table {
+				return "", nil, fmt.Errorf("field %s does not belong to table %s", f.QualifiedName(), table)
+			}
+			if first.Get(f.Name()).IsPresent() {
+				keys = append(keys, f.Name())
+				cols = append(cols, d.Quote(f.Name()))
+			}
+		}
+	} else {
+		for _, k := range first.Fields() {
+			if k == "__schema" || first.Get(k).IsAbsent() {
+				continue
+			}
+			keys = append(keys, k)
+			cols = append(cols, d.Quote(lo.SnakeCase(k)))
 		}
-		cols = append(cols, fmt.Sprintf("%s AS %s", q, alias))
+	}
+	if len(cols) == 0 {
+		return "", nil, fmt.Errorf("no fields to insert")
 	}
 
-	sqlStr := fmt.Sprintf("SELECT %s FROM %s", strings.Join(cols, ", "), table)
-	if where == nil {
-		return sqlStr, nil, nil
+	args := make([]any, 0, len(cols)*len(rows))
+	tuples := make([]string, 0, len(rows))
+	tuple := fmt.Sprintf("(%s)", makePlaceholders(len(cols)))
+	for i, row := range rows {
+		if row == nil {
+			return "", nil, fmt.Errorf("row %d: values is required", i)
+		}
+		present := lo.Filter(row.Fields(), func(k string, _ int) bool {
+			return k != "__schema" && row.Get(k).IsPresent()
+		})
+		if len(present) != len(keys) {
+			return "", nil, fmt.Errorf("row %d: column set differs from the first row", i)
+		}
+		for _, k := range keys {
+			vOpt := row.Get(k)
+			if vOpt.IsAbsent() {
+				return "", nil, fmt.Errorf("row %d: missing value for %s", i, k)
+			}
+			args = append(args, vOpt.MustGet())
+		}
+		tuples = append(tuples, tuple)
 	}
-	clause, args := where.Build()
-	if clause == "" {
-		return sqlStr, nil, nil
+
+	sqlStr := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", d.Quote(table), strings.Join(cols, ", "), strings.Join(tuples, ", "))
+	if returning != "" {
+		sqlStr += " RETURNING " + d.Quote(returning)
 	}
-	return sqlStr + " WHERE " + clause, args, nil
+	return rebind(d, sqlStr), args, nil
 }
 
-func updateSQL[T entity.Entity](schema Schema, g ValueObject, where Where) (string, []any, error) {
+// schemaOf extracts the optional "__schema" entry carried by a ValueObject.
+func schemaOf(g ValueObject) Schema {
+	sOpt := g.Get("__schema")
+	if sOpt.IsAbsent() {
+		return nil
+	}
+	switch sv := sOpt.MustGet().(type) {
+	case Schema:
+		return sv
+	case []xql.Field:
+		return Schema(sv)
+	case *[]xql.Field:
+		return Schema(*sv)
+	default:
+		return nil
+	}
+}
+
+func updateSQL[T entity.Entity](d Dialect, schema Schema, g ValueObject, where Where) (string, []any, error) {
 	if schema == nil || len(schema) == 0 {
 		return "", nil, fmt.Errorf("schema is required")
 	}
 	if where == nil {
 		return "", nil, fmt.Errorf("where is required")
 	}
-	whereClause, whereArgs := where.Build()
+	if err := whereErr(where); err != nil {
+		return "", nil, err
+	}
+	whereClause, whereArgs := buildWhere(where, d)
 	if whereClause == "" {
 		return "", nil, fmt.Errorf("where is required")
 	}
@@ -167,18 +626,19 @@ func updateSQL[T entity.Entity](schema Schema, g ValueObject, where Where) (stri
 
 	if g == nil {
 		for _, f := range schema {
-			q := dbQualifiedNameFromQName(f.QualifiedName())
-			sets = append(sets, fmt.Sprintf("%s = ?", q))
+			sets = append(sets, fmt.Sprintf("%s = ?", d.Quote(f.Name())))
 		}
 	} else {
 		for _, f := range schema {
 			col := f.Name()
 			vOpt := g.Get(col)
 			if vOpt.IsAbsent() {
+				if g.IsNull(col) {
+					sets = append(sets, fmt.Sprintf("%s = NULL", d.Quote(col)))
+				}
 				continue
 			}
-			q := dbQualifiedNameFromQName(f.QualifiedName())
-			sets = append(sets, fmt.Sprintf("%s = ?", q))
+			sets = append(sets, fmt.Sprintf("%s = ?", d.Quote(col)))
 			args = append(args, vOpt.MustGet())
 		}
 		if len(sets) == 0 {
@@ -186,31 +646,33 @@ func updateSQL[T entity.Entity](schema Schema, g ValueObject, where Where) (stri
 		}
 	}
 
-	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(sets, ", "), whereClause)
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s", d.Quote(table), strings.Join(sets, ", "), whereClause)
 	if len(whereArgs) > 0 {
 		args = append(args, whereArgs...)
 	}
-	return sql, args, nil
+	return rebind(d, sql), args, nil
 }
 
 // updateSQLFromValues builds an UPDATE statement using the provided ValueObject.
 // Behavior:
 //   - If the ValueObject contains a special key "__schema" with a meta.Schema,
-//     that schema is used to determine the set of possible columns. For each
-//     field in the schema, if the ValueObject contains a value for that field
-//     the corresponding SET will include a placeholder and the value will be
-//     appended to args. If the ValueObject does not contain a value for that
-//     schema field, the SET will still include a placeholder (for generated SQL
-//     inspection) but no value is appended (this mirrors prior generation-only
-//     behavior).
+//     that schema is used to determine the set of possible columns, in schema
+//     order. A schema field the ValueObject holds a value for gets a SET with a
+//     placeholder and its value appended to args; a field absent from the
+//     ValueObject is left untouched.
 //   - Otherwise, the ValueObject's Fields() (excluding the special key) are
 //     used as the list of fields to update; these names are converted to
 //     snake_case for DB column names.
-func updateSQLFromValues[T entity.Entity](g ValueObject, where Where) (string, []any, error) {
+//   - Either way, a field holding an explicit null (ValueObject.IsNull) is
+//     rendered as "col = NULL" without a placeholder.
+func updateSQLFromValues[T entity.Entity](d Dialect, g ValueObject, where Where) (string, []any, error) {
 	if where == nil {
 		return "", nil, fmt.Errorf("where is required")
 	}
-	whereClause, whereArgs := where.Build()
+	if err := whereErr(where); err != nil {
+		return "", nil, err
+	}
+	whereClause, whereArgs := buildWhere(where, d)
 	if whereClause == "" {
 		return "", nil, fmt.Errorf("where is required")
 	}
@@ -227,31 +689,21 @@ func updateSQLFromValues[T entity.Entity](g ValueObject, where Where) (string, [
 	sets := make([]string, 0)
 	args := make([]any, 0)
 
-	// Try to obtain schema from values
-	var schema Schema
-	if sOpt := g.Get("__schema"); !sOpt.IsAbsent() {
-		v := sOpt.MustGet()
-		// accept meta.Schema or []meta.Field
-		switch sv := v.(type) {
-		case Schema:
-			schema = sv
-		case []xql.Field:
-			schema = Schema(sv)
-		case *[]xql.Field:
-			schema = Schema(*sv)
-		default:
-			// ignore and fallback to Fields()
-		}
-	}
+	// Try to obtain schema from values; fallback to Fields() when absent
+	schema := schemaOf(g)
 
 	if schema != nil && len(schema) > 0 {
 		// Use schema order
 		for _, f := range schema {
-			colName := dbQualifiedNameFromQName(f.QualifiedName())
-			sets = append(sets, fmt.Sprintf("%s = ?", colName))
-			if vOpt := g.Get(f.Name()); !vOpt.IsAbsent() {
-				args = append(args, vOpt.MustGet())
+			vOpt := g.Get(f.Name())
+			if vOpt.IsAbsent() {
+				if g.IsNull(f.Name()) {
+					sets = append(sets, fmt.Sprintf("%s = NULL", d.Quote(f.Name())))
+				}
+				continue
 			}
+			sets = append(sets, fmt.Sprintf("%s = ?", d.Quote(f.Name())))
+			args = append(args, vOpt.MustGet())
 		}
 		if len(sets) == 0 {
 			return "", nil, fmt.Errorf("no fields to update")
@@ -264,11 +716,12 @@ func updateSQLFromValues[T entity.Entity](g ValueObject, where Where) (string, [
 			}
 			vOpt := g.Get(k)
 			if vOpt.IsAbsent() {
+				if g.IsNull(k) {
+					sets = append(sets, fmt.Sprintf("%s = NULL", d.Quote(lo.SnakeCase(k))))
+				}
 				continue
 			}
-			col := lo.SnakeCase(k)
-			q := fmt.Sprintf("%s.%s", table, col)
-			sets = append(sets, fmt.Sprintf("%s = ?", q))
+			sets = append(sets, fmt.Sprintf("%s = ?", d.Quote(lo.SnakeCase(k))))
 			args = append(args, vOpt.MustGet())
 		}
 		if len(sets) == 0 {
@@ -276,157 +729,69 @@ func updateSQLFromValues[T entity.Entity](g ValueObject, where Where) (string, [
 		}
 	}
 
-	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s", table, strings.Join(sets, ", "), whereClause)
+	sql := fmt.Sprintf("UPDATE %s SET %s WHERE %s", d.Quote(table), strings.Join(sets, ", "), whereClause)
 	if len(whereArgs) > 0 {
 		args = append(args, whereArgs...)
 	}
-	return sql, args, nil
+	return rebind(d, sql), args, nil
 }
 
-func deleteSQL[T entity.Entity](where Where) (string, []any, error) {
+func deleteSQL[T entity.Entity](d Dialect, where Where) (string, []any, error) {
 	if where == nil {
 		return "", nil, fmt.Errorf("where is required")
 	}
-	clause, args := where.Build()
+	if err := whereErr(where); err != nil {
+		return "", nil, err
+	}
+	clause, args := buildWhere(where, d)
 	if clause == "" {
 		return "", nil, fmt.Errorf("where is required")
 	}
 
 	var ent T
 	table := ent.Table()
-	return fmt.Sprintf("DELETE FROM %s WHERE %s", table, clause), args, nil
-}
-
-func buildSelectWithJoin(schema Schema, joinstmt string, where Where) (string, []any, error) {
-	if schema == nil || len(schema) == 0 {
-		return "", nil, fmt.Errorf("schema is required and must contain at least one field")
-	}
-	// derive base table from first schema field's QualifiedName
-	first := schema[0]
-	qname := first.QualifiedName()
-	parts := strings.Split(qname, ".")
-	if len(parts) < 1 {
-		return "", nil, fmt.Errorf("invalid qualified name: %s", qname)
-	}
-	baseTable := parts[0]
-
-	cols := make([]string, 0, len(schema))
-	for _, f := range schema {
-		q := f.QualifiedName()
-		q = dbQualifiedNameFromQName(q)
-		ps := strings.Split(q, ".")
-		alias := q
-		if len(ps) == 2 {
-			alias = fmt.Sprintf("%s__%s", ps[0], ps[1])
-		}
-		cols = append(cols, fmt.Sprintf("%s AS %s", q, alias))
-	}
-
-	sqlStr := fmt.Sprintf("SELECT %s FROM %s", strings.Join(cols, ", "), baseTable)
-	if strings.TrimSpace(joinstmt) != "" {
-		if strings.Contains(joinstmt, "?") {
-			return "", nil, fmt.Errorf("joinstmt must not contain placeholders; put parameters in Where")
-		}
-		sqlStr = sqlStr + " " + joinstmt
-	}
-	if where == nil {
-		return sqlStr, nil, nil
-	}
-	clause, args := where.Build()
-	if clause == "" {
-		return sqlStr, nil, nil
-	}
-	return sqlStr + " WHERE " + clause, args, nil
+	return rebind(d, fmt.Sprintf("DELETE FROM %s WHERE %s", d.Quote(table), clause)), args, nil
 }
 
-func buildDeleteWithJoin(baseTable string, joinstmt string, where Where) (string, []any, error) {
-	if strings.TrimSpace(baseTable) == "" {
-		return "", nil, fmt.Errorf("base table is required")
-	}
-	up := strings.ToUpper(strings.TrimSpace(joinstmt))
-	joinIdx := strings.Index(up, "JOIN ")
-	onIdx := strings.Index(up, " ON ")
-	if joinIdx == -1 || onIdx == -1 {
-		return "", nil, fmt.Errorf("unsupported joinstmt for delete: expected single 'JOIN <table> ON <cond>' pattern")
-	}
-	// locate original ON index in joinstmt
-	onIdxOrig := strings.Index(strings.ToUpper(joinstmt), " ON ")
-	if onIdxOrig == -1 {
-		return "", nil, fmt.Errorf("unsupported joinstmt for delete: missing ON clause")
-	}
-	// table part between 'JOIN ' and ' ON '
-	tablePart := strings.TrimSpace(joinstmt[joinIdx+5 : onIdxOrig])
-	onPart := strings.TrimSpace(joinstmt[onIdxOrig+4:])
-
-	clause := ""
-	var args []any
-	if where != nil {
-		c, a := where.Build()
-		clause = c
-		args = a
-	}
-
-	sub := fmt.Sprintf("SELECT 1 FROM %s WHERE %s", tablePart, onPart)
-	if clause != "" {
-		sub = sub + " AND (" + clause + ")"
+func buildSelectWithJoin(d Dialect, schema Schema, join Join, where Where, opts queryOpts) (string, []any, error) {
+	q, args, err := joinSelect(d, schema, join, where, opts)
+	if err != nil {
+		return "", nil, err
 	}
-	sqlStr := fmt.Sprintf("DELETE FROM %s WHERE EXISTS (%s)", baseTable, sub)
-	return sqlStr, args, nil
+	return rebind(d, q), args, nil
 }
 
-func buildExistsWhere(joinstmt string, where Where) (Where, error) {
-	if strings.TrimSpace(joinstmt) == "" {
-		// no joinstmt -> if where provided, just use it; otherwise error
-		if where == nil {
-			return nil, fmt.Errorf("joinstmt or where required")
-		}
-		return where, nil
+// joinSelect renders the SELECT of a join query with `?` placeholders; it is
+// shared by buildSelectWithJoin and subqueries.
+func joinSelect(d Dialect, schema Schema, join Join, where Where, opts queryOpts) (string, []any, error) {
+	if len(schema) == 0 && len(opts.aggs) == 0 {
+		return "", nil, fmt.Errorf("schema is required and must contain at least one field")
 	}
-	up := strings.ToUpper(strings.TrimSpace(joinstmt))
-	joinIdx := strings.Index(up, "JOIN ")
-	onIdx := strings.Index(up, " ON ")
-	if joinIdx == -1 || onIdx == -1 {
-		return nil, fmt.Errorf("unsupported joinstmt for exists: expected single 'JOIN <table> ON <cond>' pattern")
+	from, fromArgs, err := join.from(d)
+	if err != nil {
+		return "", nil, err
 	}
-	onIdxOrig := strings.Index(strings.ToUpper(joinstmt), " ON ")
-	if onIdxOrig == -1 {
-		return nil, fmt.Errorf("unsupported joinstmt for exists: missing ON clause")
+	if err := join.checkScope(schema, opts); err != nil {
+		return "", nil, err
 	}
-	tablePart := strings.TrimSpace(joinstmt[joinIdx+5 : onIdxOrig])
-	onPart := strings.TrimSpace(joinstmt[onIdxOrig+4:])
-
-	w := func() (string, []any) {
-		clause := ""
-		var args []any
-		if where != nil {
-			c, a := where.Build()
-			clause = c
-			args = a
-		}
-		sub := fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s", tablePart, onPart)
-		if clause != "" {
-			sub = sub + " AND (" + clause + ")"
-		}
-		sub = sub + ")"
-		return sub, args
-	}
-	return whereFunc(w), nil
+	return selectStatement(d, schema, from, fromArgs, where, opts)
 }
 
 // rowsToValueObjects maps query results to meta.ValueObject using the schema order.
 // Mapping policy:
 // - Fields are schema field Name() (provider name).
-// - Values are scanned as driver values.
-func rowsToValueObjects(rows *sql.Rows, schema Schema) ([]ValueObject, error) {
+// - Aggregates follow the schema fields and are keyed by their Alias().
+// - Values are converted to the field's Go type (COUNT: int64, AVG: float64); NULL stays nil.
+func rowsToValueObjects(rows *sql.Rows, schema Schema, aggs []Aggregate) ([]ValueObject, error) {
 	if rows == nil {
 		return nil, fmt.Errorf("rows is required")
 	}
-	if len(schema) == 0 {
+	if len(schema) == 0 && len(aggs) == 0 {
 		return nil, fmt.Errorf("schema has no fields")
 	}
 
-	// We always project columns in the same order as schema in selectSQL.
-	n := len(schema)
+	// We always project columns in the same order as schema (then aggregates) in selectSQL.
+	n := len(schema) + len(aggs)
 	out := make([]ValueObject, 0)
 
 	for rows.Next() {
@@ -441,7 +806,18 @@ func rowsToValueObjects(rows *sql.Rows, schema Schema) ([]ValueObject, error) {
 
 		m := make(map[string]any, n)
 		for i, f := range schema {
-			m[f.Name()] = vals[i]
+			v, err := convertValue(vals[i], fieldType(f))
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", f.QualifiedName(), err)
+			}
+			m[f.Name()] = v
+		}
+		for i, a := range aggs {
+			v, err := convertValue(vals[len(schema)+i], aggregateType(a))
+			if err != nil {
+				return nil, fmt.Errorf("aggregate %s: %w", a.Alias(), err)
+			}
+			m[a.Alias()] = v
 		}
 		out = append(out, valueObject{Data: m})
 	}
@@ -458,13 +834,52 @@ func rowsToValueObjects(rows *sql.Rows, schema Schema) ([]ValueObject, error) {
 type queryExec[T entity.Entity] struct {
 	schema Schema
 	where  Where
+	opts   queryOpts
+}
+
+var _ QueryExecutor = queryExec[entity.Entity]{}
+
+func (q queryExec[T]) OrderBy(field xql.Field, dir SortDirection) QueryExecutor {
+	q.opts = q.opts.orderBy(field, dir)
+	return q
 }
 
-func (q queryExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]ValueObject, sql.Result], error) {
-	if ds == nil {
-		return mo.Left[[]ValueObject, sql.Result](nil), fmt.Errorf("db is required")
+func (q queryExec[T]) Limit(n int) QueryExecutor {
+	q.opts = q.opts.withLimit(n)
+	return q
+}
+
+func (q queryExec[T]) Offset(n int) QueryExecutor {
+	q.opts = q.opts.withOffset(n)
+	return q
+}
+
+func (q queryExec[T]) After(values ...any) QueryExecutor {
+	q.opts = q.opts.withAfter(values)
+	return q
+}
+
+func (q queryExec[T]) Aggregate(aggs ...Aggregate) QueryExecutor {
+	q.opts = q.opts.aggregate(aggs)
+	return q
+}
+
+func (q queryExec[T]) GroupBy(fields ...xql.Field) QueryExecutor {
+	q.opts = q.opts.withGroupBy(fields)
+	return q
+}
+
+func (q queryExec[T]) Having(where Where) QueryExecutor {
+	q.opts = q.opts.withHaving(where)
+	return q
+}
+
+func (q queryExec[T]) Execute(ctx context.Context, ds Querier) (mo.Either[[]ValueObject, sql.Result], error) {
+	ds, d, err := querierFrom(ctx, ds)
+	if err != nil {
+		return mo.Left[[]ValueObject, sql.Result](nil), err
 	}
-	query, qargs, err := selectSQL[T](&q.schema, q.where)
+	query, qargs, err := selectSQL[T](d, &q.schema, q.where, q.opts)
 	if err != nil {
 		return mo.Left[[]ValueObject, sql.Result](nil), err
 	}
@@ -474,7 +889,7 @@ func (q queryExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]Valu
 	}
 	defer func() { _ = rows.Close() }()
 
-	res, err := rowsToValueObjects(rows, q.schema)
+	res, err := rowsToValueObjects(rows, q.schema, q.opts.aggs)
 	if err != nil {
 		return mo.Left[[]ValueObject, sql.Result](nil), err
 	}
@@ -482,10 +897,21 @@ func (q queryExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]Valu
 }
 
 func (q queryExec[T]) sql() (string, error) {
-	qstr, _, err := selectSQL[T](&q.schema, q.where)
+	qstr, _, err := selectSQL[T](defaultDialect, &q.schema, q.where, q.opts)
 	return qstr, err
 }
 
+func (q queryExec[T]) subquery(d Dialect) (string, []any, error) {
+	if len(q.schema) == 0 && len(q.opts.aggs) == 0 {
+		return "", nil, fmt.Errorf("schema has no fields")
+	}
+	return tableSelect[T](d, q.schema, q.where, q.opts)
+}
+
+func (q queryExec[T]) columns() (Schema, []Aggregate) {
+	return q.schema, q.opts.aggs
+}
+
 // -----------------------------
 // Executors - DELETE
 // -----------------------------
@@ -494,11 +920,12 @@ type deleteExec[T entity.Entity] struct {
 	where Where
 }
 
-func (d deleteExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]ValueObject, sql.Result], error) {
-	if ds == nil {
-		return mo.Right[[]ValueObject, sql.Result](nil), fmt.Errorf("db is required")
+func (d deleteExec[T]) Execute(ctx context.Context, ds Querier) (mo.Either[[]ValueObject, sql.Result], error) {
+	ds, dl, err := querierFrom(ctx, ds)
+	if err != nil {
+		return mo.Right[[]ValueObject, sql.Result](nil), err
 	}
-	query, qargs, err := deleteSQL[T](d.where)
+	query, qargs, err := deleteSQL[T](dl, d.where)
 	if err != nil {
 		return mo.Right[[]ValueObject, sql.Result](nil), err
 	}
@@ -510,7 +937,7 @@ func (d deleteExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]Val
 }
 
 func (d deleteExec[T]) sql() (string, error) {
-	dstr, _, err := deleteSQL[T](d.where)
+	dstr, _, err := deleteSQL[T](defaultDialect, d.where)
 	return dstr, err
 }
 
@@ -523,11 +950,12 @@ type updateExec[T entity.Entity] struct {
 	where  Where
 }
 
-func (u updateExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]ValueObject, sql.Result], error) {
-	if ds == nil {
-		return mo.Right[[]ValueObject, sql.Result](nil), fmt.Errorf("db is required")
+func (u updateExec[T]) Execute(ctx context.Context, ds Querier) (mo.Either[[]ValueObject, sql.Result], error) {
+	ds, d, err := querierFrom(ctx, ds)
+	if err != nil {
+		return mo.Right[[]ValueObject, sql.Result](nil), err
 	}
-	q, args, err := updateSQLFromValues[T](u.values, u.where)
+	q, args, err := updateSQLFromValues[T](d, u.values, u.where)
 	if err != nil {
 		return mo.Right[[]ValueObject, sql.Result](nil), err
 	}
@@ -539,25 +967,168 @@ func (u updateExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]Val
 }
 
 func (u updateExec[T]) sql() (string, error) {
-	q, _, err := updateSQLFromValues[T](u.values, u.where)
+	q, _, err := updateSQLFromValues[T](defaultDialect, u.values, u.where)
 	return q, err
 }
 
+// -----------------------------
+// Executors - INSERT
+// -----------------------------
+
+type insertExec[T entity.Entity] struct {
+	rows []ValueObject
+}
+
+func (i insertExec[T]) Execute(ctx context.Context, ds Querier) (mo.Either[[]ValueObject, sql.Result], error) {
+	ds, d, err := querierFrom(ctx, ds)
+	if err != nil {
+		return mo.Right[[]ValueObject, sql.Result](nil), err
+	}
+	pk, hasPK := primaryKeyOf[T]()
+	returning := lo.Ternary(hasPK && d.SupportsReturning(), pk, "")
+	q, args, err := insertSQL[T](d, i.rows, returning)
+	if err != nil {
+		return mo.Right[[]ValueObject, sql.Result](nil), err
+	}
+	if returning != "" {
+		rows, err := ds.QueryContext(ctx, q, args...)
+		if err != nil {
+			return mo.Right[[]ValueObject, sql.Result](nil), err
+		}
+		defer func() { _ = rows.Close() }()
+		var keys []any
+		for rows.Next() {
+			var key any
+			if err := rows.Scan(&key); err != nil {
+				return mo.Right[[]ValueObject, sql.Result](nil), err
+			}
+			keys = append(keys, key)
+		}
+		if err := rows.Err(); err != nil {
+			return mo.Right[[]ValueObject, sql.Result](nil), err
+		}
+		return mo.Right[[]ValueObject, sql.Result](insertResult{keys: keys, affected: int64(len(keys))}), nil
+	}
+	res, err := ds.ExecContext(ctx, q, args...)
+	if err != nil {
+		return mo.Right[[]ValueObject, sql.Result](nil), err
+	}
+	return mo.Right[[]ValueObject, sql.Result](newInsertResult(res, d.Name(), pk, i.rows)), nil
+}
+
+func (i insertExec[T]) sql() (string, error) {
+	q, _, err := insertSQL[T](defaultDialect, i.rows, "")
+	return q, err
+}
+
+// insertResult implements InsertResult.
+type insertResult struct {
+	sql.Result
+	keys     []any
+	affected int64
+}
+
+var _ InsertResult = insertResult{}
+
+func (r insertResult) GeneratedKeys() []any { return r.keys }
+
+func (r insertResult) LastInsertId() (int64, error) {
+	if r.Result != nil {
+		return r.Result.LastInsertId()
+	}
+	if len(r.keys) == 0 {
+		return 0, fmt.Errorf("no generated keys")
+	}
+	id, ok := r.keys[len(r.keys)-1].(int64)
+	if !ok {
+		return 0, fmt.Errorf("generated key %v is not an integer", r.keys[len(r.keys)-1])
+	}
+	return id, nil
+}
+
+func (r insertResult) RowsAffected() (int64, error) {
+	if r.Result != nil {
+		return r.Result.RowsAffected()
+	}
+	return r.affected, nil
+}
+
+// newInsertResult derives the generated keys from a driver result.
+//   - If the primary key was part of the payload, the supplied values are the keys.
+//   - MySQL reports the id of the first inserted row; ids of a multi-row insert are consecutive.
+//   - SQLite reports the rowid of the last inserted row.
+func newInsertResult(res sql.Result, driver string, pk string, rows []ValueObject) insertResult {
+	out := insertResult{Result: res}
+	n := int64(len(rows))
+	if pk != "" && rows[0].Get(pk).IsPresent() {
+		out.keys = lo.Map(rows, func(row ValueObject, _ int) any { return row.Get(pk).MustGet() })
+		return out
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return out
+	}
+	first := lo.Ternary(driver == mysqlDriver, id, id-n+1)
+	for k := int64(0); k < n; k++ {
+		out.keys = append(out.keys, first+k)
+	}
+	return out
+}
+
 // -----------------------------
 // Executors - JOIN
 // -----------------------------
 
 type joinQueryExec struct {
-	schema   Schema
-	joinstmt string
-	where    Where
+	schema Schema
+	join   Join
+	where  Where
+	opts   queryOpts
+}
+
+var _ QueryExecutor = joinQueryExec{}
+
+func (j joinQueryExec) OrderBy(field xql.Field, dir SortDirection) QueryExecutor {
+	j.opts = j.opts.orderBy(field, dir)
+	return j
+}
+
+func (j joinQueryExec) Limit(n int) QueryExecutor {
+	j.opts = j.opts.withLimit(n)
+	return j
+}
+
+func (j joinQueryExec) Offset(n int) QueryExecutor {
+	j.opts = j.opts.withOffset(n)
+	return j
+}
+
+func (j joinQueryExec) After(values ...any) QueryExecutor {
+	j.opts = j.opts.withAfter(values)
+	return j
+}
+
+func (j joinQueryExec) Aggregate(aggs ...Aggregate) QueryExecutor {
+	j.opts = j.opts.aggregate(aggs)
+	return j
 }
 
-func (j joinQueryExec) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]ValueObject, sql.Result], error) {
-	if ds == nil {
-		return mo.Left[[]ValueObject, sql.Result](nil), fmt.Errorf("db is required")
+func (j joinQueryExec) GroupBy(fields ...xql.Field) QueryExecutor {
+	j.opts = j.opts.withGroupBy(fields)
+	return j
+}
+
+func (j joinQueryExec) Having(where Where) QueryExecutor {
+	j.opts = j.opts.withHaving(where)
+	return j
+}
+
+func (j joinQueryExec) Execute(ctx context.Context, ds Querier) (mo.Either[[]ValueObject, sql.Result], error) {
+	ds, d, err := querierFrom(ctx, ds)
+	if err != nil {
+		return mo.Left[[]ValueObject, sql.Result](nil), err
 	}
-	q, args, err := buildSelectWithJoin(j.schema, j.joinstmt, j.where)
+	q, args, err := buildSelectWithJoin(d, j.schema, j.join, j.where, j.opts)
 	if err != nil {
 		return mo.Left[[]ValueObject, sql.Result](nil), err
 	}
@@ -566,7 +1137,7 @@ func (j joinQueryExec) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]Val
 		return mo.Left[[]ValueObject, sql.Result](nil), err
 	}
 	defer func() { _ = rows.Close() }()
-	res, err := rowsToValueObjects(rows, j.schema)
+	res, err := rowsToValueObjects(rows, j.schema, j.opts.aggs)
 	if err != nil {
 		return mo.Left[[]ValueObject, sql.Result](nil), err
 	}
@@ -574,22 +1145,30 @@ func (j joinQueryExec) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]Val
 }
 
 func (j joinQueryExec) sql() (string, error) {
-	q, _, err := buildSelectWithJoin(j.schema, j.joinstmt, j.where)
+	q, _, err := buildSelectWithJoin(defaultDialect, j.schema, j.join, j.where, j.opts)
 	return q, err
 }
 
-// joinDeleteExec implements delete via EXISTS using a joinstmt.
-type joinDeleteExec struct {
-	baseTable string
-	joinstmt  string
-	where     Where
+func (j joinQueryExec) subquery(d Dialect) (string, []any, error) {
+	return joinSelect(d, j.schema, j.join, j.where, j.opts)
+}
+
+func (j joinQueryExec) columns() (Schema, []Aggregate) {
+	return j.schema, j.opts.aggs
 }
 
-func (j joinDeleteExec) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]ValueObject, sql.Result], error) {
-	if ds == nil {
-		return mo.Right[[]ValueObject, sql.Result](nil), fmt.Errorf("db is required")
+// joinDeleteExec implements delete with a join-based EXISTS filter.
+type joinDeleteExec[T entity.Entity] struct {
+	join  Join
+	where Where
+}
+
+func (j joinDeleteExec[T]) Execute(ctx context.Context, ds Querier) (mo.Either[[]ValueObject, sql.Result], error) {
+	ds, d, err := querierFrom(ctx, ds)
+	if err != nil {
+		return mo.Right[[]ValueObject, sql.Result](nil), err
 	}
-	q, args, err := buildDeleteWithJoin(j.baseTable, j.joinstmt, j.where)
+	q, args, err := j.build(d)
 	if err != nil {
 		return mo.Right[[]ValueObject, sql.Result](nil), err
 	}
@@ -600,28 +1179,33 @@ func (j joinDeleteExec) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]Va
 	return mo.Right[[]ValueObject, sql.Result](res), nil
 }
 
-func (j joinDeleteExec) sql() (string, error) {
-	q, _, err := buildDeleteWithJoin(j.baseTable, j.joinstmt, j.where)
+func (j joinDeleteExec[T]) build(d Dialect) (string, []any, error) {
+	var ent T
+	existsWhere, err := j.join.exists(ent.Table(), j.where)
+	if err != nil {
+		return "", nil, err
+	}
+	return deleteSQL[T](d, existsWhere)
+}
+
+func (j joinDeleteExec[T]) sql() (string, error) {
+	q, _, err := j.build(defaultDialect)
 	return q, err
 }
 
-// updateJoinExec implements update with join-based EXISTS filter.
+// updateJoinExec implements update with a join-based EXISTS filter.
 type updateJoinExec[T entity.Entity] struct {
-	values   ValueObject
-	joinstmt string
-	where    Where
+	values ValueObject
+	join   Join
+	where  Where
 }
 
-func (u updateJoinExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[]ValueObject, sql.Result], error) {
-	if ds == nil {
-		return mo.Right[[]ValueObject, sql.Result](nil), fmt.Errorf("db is required")
-	}
-	// build a Where representing the EXISTS(...) predicate (applies joinstmt and inner where)
-	existsWhere, err := buildExistsWhere(u.joinstmt, u.where)
+func (u updateJoinExec[T]) Execute(ctx context.Context, ds Querier) (mo.Either[[]ValueObject, sql.Result], error) {
+	ds, d, err := querierFrom(ctx, ds)
 	if err != nil {
 		return mo.Right[[]ValueObject, sql.Result](nil), err
 	}
-	q, args, err := updateSQLFromValues[T](u.values, existsWhere)
+	q, args, err := u.build(d)
 	if err != nil {
 		return mo.Right[[]ValueObject, sql.Result](nil), err
 	}
@@ -632,11 +1216,17 @@ func (u updateJoinExec[T]) Execute(ctx context.Context, ds *sql.DB) (mo.Either[[
 	return mo.Right[[]ValueObject, sql.Result](res), nil
 }
 
-func (u updateJoinExec[T]) sql() (string, error) {
-	existsWhere, err := buildExistsWhere(u.joinstmt, u.where)
+func (u updateJoinExec[T]) build(d Dialect) (string, []any, error) {
+	var ent T
+	// a Where representing the EXISTS(...) predicate (applies the join and the inner where)
+	existsWhere, err := u.join.exists(ent.Table(), u.where)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	ustr, _, err := updateSQLFromValues[T](u.values, existsWhere)
-	return ustr, err
+	return updateSQLFromValues[T](d, u.values, existsWhere)
+}
+
+func (u updateJoinExec[T]) sql() (string, error) {
+	q, _, err := u.build(defaultDialect)
+	return q, err
 }
@@ -1,6 +1,8 @@
 package sqlx
 
 import (
+	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -8,11 +10,30 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/kcmvp/xql"
 	. "github.com/kcmvp/xql/sample/entity"
+	"github.com/kcmvp/xql/sample/gen/field/account"
 	"github.com/kcmvp/xql/sample/gen/field/order"
+	"github.com/kcmvp/xql/sample/gen/field/profile"
+	"github.com/samber/lo"
 	"github.com/stretchr/testify/require"
 )
 
+// newOrdersDB opens the shared in-memory sqlite database name, closed with the
+// test, with the orders table of the sample entities; stmts run after it, e.g.
+// to create more tables or seed rows.
+func newOrdersDB(t *testing.T, name string, stmts ...string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", "file:"+name+"?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	for _, stmt := range append([]string{"CREATE TABLE orders (id INTEGER PRIMARY KEY, account_id INTEGER, amount REAL, created_at DATETIME, updated_at DATETIME, created_by TEXT, updated_by TEXT)"}, stmts...) {
+		_, err = db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	return db
+}
+
 // normalizeSQL removes SQL comments and normalizes whitespace for comparison.
 func normalizeSQL(s string) string {
 	// remove SQL comments starting with --
@@ -209,6 +230,13 @@ func TestSqlGeneration_Select(t *testing.T) {
 		{"InEmpty", In(order.ID), "WHERE 1=0", false, false, ""},
 		{"And", And(Eq(order.Amount, 50.0), Gt(order.ID, 0)), "WHERE (orders.amount = ? AND orders.id > ?)", true, false, ""},
 		{"Or", Or(Eq(order.Amount, 50.0), Eq(order.ID, 5)), "WHERE (orders.amount = ? OR orders.id = ?)", true, false, ""},
+		{"IsNull", IsNull(order.UpdatedBy), "WHERE orders.updated_by IS NULL", false, false, ""},
+		{"IsNotNull", IsNotNull(order.UpdatedBy), "WHERE orders.updated_by IS NOT NULL", false, false, ""},
+		{"Between", Between(order.Amount, 10, 20), "WHERE orders.amount BETWEEN ? AND ?", true, false, ""},
+		{"NotIn", NotIn(order.ID, 1, 2), "WHERE orders.id NOT IN (?,?)", true, false, ""},
+		{"NotInEmpty", NotIn(order.ID), "WHERE 1=1", false, false, ""},
+		{"ILike", ILike(order.CreatedBy, "%john%"), "WHERE LOWER(orders.created_by) LIKE LOWER(?)", true, false, ""},
+		{"Not", Not(Or(Eq(order.Amount, 50.0), IsNull(order.UpdatedBy))), "WHERE NOT (orders.amount = ? OR orders.updated_by IS NULL)", true, false, ""},
 	}
 
 	for _, c := range cases {
@@ -300,6 +328,105 @@ func TestSqlGeneration_Select(t *testing.T) {
 	}
 }
 
+func TestWhere_Subquery(t *testing.T) {
+	big := Query[Order](Schema{order.AccountID})(Gt(order.Amount, 100))
+
+	t.Run("In", func(t *testing.T) {
+		clause, args := In(account.ID, big).Build()
+		require.Equal(t, "accounts.id IN (SELECT orders.account_id AS orders__account_id FROM orders WHERE orders.amount > ?)", clause)
+		require.Equal(t, []any{100}, args)
+	})
+
+	t.Run("NotInGrouped", func(t *testing.T) {
+		sub := Query[Order](Schema{order.AccountID})(nil).GroupBy(order.AccountID).Having(Count(nil).Gt(2))
+		clause, args := NotIn(account.ID, sub).Build()
+		require.Equal(t, "accounts.id NOT IN (SELECT orders.account_id AS orders__account_id FROM orders GROUP BY orders.account_id HAVING COUNT(*) > ?)", clause)
+		require.Equal(t, []any{2}, args)
+	})
+
+	t.Run("ExistsCorrelated", func(t *testing.T) {
+		sub := Query[Order](Schema{order.ID})(And(EqField(order.AccountID, account.ID), Gt(order.Amount, 5)))
+		clause, args := Not(Exists(sub)).Build()
+		require.Equal(t, "NOT (EXISTS (SELECT orders.id AS orders__id FROM orders WHERE (orders.account_id = accounts.id AND orders.amount > ?)))", clause)
+		require.Equal(t, []any{5}, args)
+	})
+
+	t.Run("PostgresNumbering", func(t *testing.T) {
+		schema := Schema{account.Email}
+		where := And(Eq(account.Nickname, "n"), In(account.ID, big), ILike(account.Email, "%@x.io"))
+		q, args, err := selectSQL[Account](postgresDialect, &schema, where, queryOpts{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT accounts.email AS accounts__email FROM accounts WHERE (accounts.nick_name = $1 AND "+
+			"accounts.id IN (SELECT orders.account_id AS orders__account_id FROM orders WHERE orders.amount > $2) AND accounts.email ILIKE $3)", q)
+		require.Equal(t, []any{"n", 100, "%@x.io"}, args)
+	})
+
+	t.Run("JoinSubquery", func(t *testing.T) {
+		sub := QueryJoin(Schema{profile.AccountID})(InnerJoin(profile.AccountID, order.AccountID), Gt(order.Amount, 1))
+		clause, _ := In(account.ID, sub).Build()
+		require.Equal(t, "accounts.id IN (SELECT profiles.account_id AS profiles__account_id FROM profiles INNER JOIN orders ON profiles.account_id = orders.account_id WHERE orders.amount > ?)", clause)
+	})
+
+	t.Run("Invalid_should_error", func(t *testing.T) {
+		query := func(where Where) error {
+			_, err := Query[Account](Schema{account.ID})(where).sql()
+			return err
+		}
+		// more than one projected field
+		require.ErrorContains(t, query(In(account.ID, Query[Order](Schema{order.ID, order.AccountID})(nil))), "must project exactly one field")
+		// aggregate projection
+		require.ErrorContains(t, query(In(account.ID, Query[Order](nil)(nil).Aggregate(Max(order.AccountID)))), "must project exactly one field")
+		// type mismatch: string vs int64
+		require.ErrorContains(t, query(NotIn(account.ID, Query[Order](Schema{order.CreatedBy})(nil))), "does not match the type")
+		// invalid subqueries, also nested in other predicates
+		require.ErrorContains(t, query(Exists(Query[Order](Schema{order.ID})(nil).Aggregate(Count(nil)))), "invalid subquery")
+		require.ErrorContains(t, query(Not(Exists(nil))), "subquery must not be nil")
+		negative := In(account.ID, Query[Order](Schema{order.AccountID})(nil).Limit(-1))
+		require.ErrorContains(t, query(And(Eq(account.Nickname, "n"), Or(negative))), "invalid subquery: limit must not be negative: -1")
+		// a delete never runs without its predicate
+		db := newOrdersDB(t, "invalid_subquery")
+		_, err := Delete[Order](In(order.AccountID, Query[Order](Schema{order.AccountID})(nil).Limit(-1))).Execute(context.Background(), db)
+		require.ErrorContains(t, err, "limit must not be negative")
+	})
+
+	t.Run("MySQLILike", func(t *testing.T) {
+		clause, _ := buildWhere(ILike(account.Email, "a%"), mysqlDialect)
+		require.Equal(t, "LOWER(accounts.email) LIKE LOWER(?)", clause)
+	})
+
+	t.Run("NotEmpty", func(t *testing.T) {
+		clause, args := Not(nil).Build()
+		require.Empty(t, clause)
+		require.Empty(t, args)
+		clause, _ = Not(Eq(order.ID, 1)).Build()
+		require.Equal(t, "NOT (orders.id = ?)", clause)
+		clause, _ = Not(Or(Eq(order.ID, 1), Eq(order.ID, 2))).Build()
+		require.Equal(t, "NOT (orders.id = ? OR orders.id = ?)", clause)
+	})
+}
+
+func TestWhere_Predicates_SQLite(t *testing.T) {
+	db := newOrdersDB(t, "where_predicates",
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY, email TEXT, nick_name TEXT, category INTEGER, balance REAL, created_at DATETIME, updated_at DATETIME, created_by TEXT, updated_by TEXT)",
+		"INSERT INTO accounts (id, email) VALUES (1, 'Ann@X.io'), (2, 'bob@x.io'), (3, 'cid@y.io')",
+		"INSERT INTO orders (id, account_id, amount, updated_by) VALUES (1, 1, 10, NULL), (2, 1, 200, 'ops'), (3, 2, 50, NULL)",
+	)
+	ctx := context.Background()
+	ids := func(where Where) []any {
+		res, err := Query[Account](Schema{account.ID})(where).OrderBy(account.ID, Asc).Execute(ctx, db)
+		require.NoError(t, err)
+		return lo.Map(res.MustLeft(), func(vo ValueObject, _ int) any { return vo.Get("id").MustGet() })
+	}
+	withOrders := Query[Order](Schema{order.AccountID})(nil)
+	require.Equal(t, []any{int64(1), int64(2)}, ids(In(account.ID, withOrders)))
+	require.Equal(t, []any{int64(3)}, ids(NotIn(account.ID, withOrders)))
+	require.Equal(t, []any{int64(1)}, ids(Exists(Query[Order](Schema{order.ID})(And(EqField(order.AccountID, account.ID), Between(order.Amount, 100, 300))))))
+	require.Equal(t, []any{int64(1), int64(2)}, ids(ILike(account.Email, "%@x.IO")))
+	require.Equal(t, []any{int64(3)}, ids(Not(ILike(account.Email, "%@x.io"))))
+	require.Equal(t, []any{int64(1), int64(2), int64(3)}, ids(IsNull(account.Nickname)))
+	require.Empty(t, ids(IsNotNull(account.Nickname)))
+}
+
 // TestSqlGeneration_Delete mirrors the Select tests but for DELETE statements.
 func TestSqlGeneration_Delete(t *testing.T) {
 
@@ -356,7 +483,7 @@ func TestSqlGeneration_Delete(t *testing.T) {
 			}
 
 			// check args via deleteSQL helper
-			_, args, err := deleteSQL[Order](c.where)
+			_, args, err := deleteSQL[Order](defaultDialect, c.where)
 			require.NoError(t, err)
 			if c.hasArgs {
 				require.True(t, args != nil && len(args) > 0)
@@ -368,7 +495,7 @@ func TestSqlGeneration_Delete(t *testing.T) {
 
 	// Negative case: ensure deleteSQL requires a WHERE clause to avoid accidental full-table deletes
 	t.Run("NoWhere_should_error", func(t *testing.T) {
-		_, _, err := deleteSQL[Order](nil)
+		_, _, err := deleteSQL[Order](defaultDialect, nil)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "where is required")
 	})
@@ -413,10 +540,41 @@ func TestSqlGeneration_Delete(t *testing.T) {
 	}
 }
 
+// TestUpdate_Patch_SQLite updates some schema fields, clears one and leaves
+// the absent ones untouched.
+func TestUpdate_Patch_SQLite(t *testing.T) {
+	db := newOrdersDB(t, "update_patch",
+		"INSERT INTO orders (id, account_id, amount, created_by, updated_by) VALUES (1, 9, 10, 'ann', NULL)",
+	)
+	ctx := context.Background()
+	schema := Schema{order.AccountID, order.Amount, order.CreatedBy, order.UpdatedBy}
+	patch := NewValueObject(map[string]any{"__schema": schema, "amount": 20.0, "created_by": nil, "updated_by": "ops"})
+	res, err := Update[Order](patch)(Eq(order.ID, 1)).Execute(ctx, db)
+	require.NoError(t, err)
+	n, err := res.MustRight().RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	var (
+		accountID int64
+		amount    float64
+		createdBy sql.NullString
+		updatedBy string
+	)
+	require.NoError(t, db.QueryRow("SELECT account_id, amount, created_by, updated_by FROM orders WHERE id = 1").Scan(&accountID, &amount, &createdBy, &updatedBy))
+	require.Equal(t, int64(9), accountID)
+	require.Equal(t, 20.0, amount)
+	require.False(t, createdBy.Valid)
+	require.Equal(t, "ops", updatedBy)
+}
+
 // TestSqlGeneration_Update mirrors the Select/Delete tests but for UPDATE statements.
 func TestSqlGeneration_Update(t *testing.T) {
 	fields := order.All()
 	schema := Schema(fields)
+	// every schema field is set, so every one of them is updated
+	values := lo.SliceToMap(fields, func(f xql.Field) (string, any) { return f.Name(), 1 })
+	values["__schema"] = schema
 
 	cases := []struct {
 		name    string
@@ -439,7 +597,7 @@ func TestSqlGeneration_Update(t *testing.T) {
 
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			valuesVO := NewValueObject(map[string]any{"__schema": schema})
+			valuesVO := NewValueObject(values)
 			exec := Update[Order](valuesVO)(c.where)
 			require.NotNil(t, exec)
 
@@ -471,7 +629,7 @@ func TestSqlGeneration_Update(t *testing.T) {
 			}
 
 			// args via updateSQL with nil getter will include where args only
-			_, args, err := updateSQL[Order](schema, nil, c.where)
+			_, args, err := updateSQL[Order](defaultDialect, schema, nil, c.where)
 			require.NoError(t, err)
 			if c.hasArgs {
 				require.True(t, args != nil && len(args) > 0)
@@ -493,7 +651,7 @@ func TestSqlGeneration_Update(t *testing.T) {
 
 	for _, c := range complexCases {
 		t.Run(c.name, func(t *testing.T) {
-			valuesVO := NewValueObject(map[string]any{"__schema": schema})
+			valuesVO := NewValueObject(values)
 			exec := Update[Order](valuesVO)(c.where)
 			require.NotNil(t, exec)
 
@@ -527,18 +685,42 @@ func TestJoinAPIs_SQLGeneration(t *testing.T) {
 	fields := order.All()
 	schema := Schema(fields)
 
-	join := "JOIN profiles ON profiles.account_id = orders.account_id"
+	join := InnerJoin(order.AccountID, profile.AccountID)
 
 	t.Run("QueryJoin", func(t *testing.T) {
 		exec := QueryJoin(schema)(join, Eq(order.Amount, 100.0))
 		require.NotNil(t, exec)
 		q, err := exec.sql()
 		require.NoError(t, err)
-		// should include the injected join and the WHERE clause
-		require.True(t, strings.Contains(q, "JOIN profiles ON profiles.account_id = orders.account_id"), "join clause missing: %s", q)
+		// should include the rendered join and the WHERE clause
+		require.True(t, strings.Contains(q, "FROM orders INNER JOIN profiles ON orders.account_id = profiles.account_id"), "join clause missing: %s", q)
 		require.True(t, strings.Contains(strings.ToUpper(q), "WHERE"), "where missing: %s", q)
 	})
 
+	t.Run("QueryJoin_Chained", func(t *testing.T) {
+		chained := LeftJoin(order.AccountID, account.ID).
+			InnerJoin(account.ID, profile.AccountID).
+			On(EqField(account.CreatedBy, profile.CreatedBy), Eq(profile.Bio, "vip"))
+		q, args, err := buildSelectWithJoin(defaultDialect, Schema{order.ID, account.Email, profile.Bio}, chained, Gt(order.Amount, 10), queryOpts{})
+		require.NoError(t, err)
+		require.Equal(t, "SELECT orders.id AS orders__id, accounts.email AS accounts__email, profiles.bio AS profiles__bio "+
+			"FROM orders LEFT JOIN accounts ON orders.account_id = accounts.id "+
+			"INNER JOIN profiles ON (accounts.id = profiles.account_id AND accounts.created_by = profiles.created_by AND profiles.bio = ?) "+
+			"WHERE orders.amount > ?", q)
+		require.Equal(t, []any{"vip", 10}, args)
+	})
+
+	t.Run("QueryJoin_FieldOutOfScope_should_error", func(t *testing.T) {
+		_, err := QueryJoin(Schema{order.ID, account.Email})(join, nil).sql()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "field accounts.email is not part of the join")
+	})
+
+	t.Run("Join_InvalidChain_should_panic", func(t *testing.T) {
+		require.Panics(t, func() { InnerJoin(order.AccountID, account.ID).InnerJoin(profile.AccountID, order.ID) })
+		require.Panics(t, func() { InnerJoin(order.AccountID, account.ID).InnerJoin(order.ID, account.ID) })
+	})
+
 	t.Run("DeleteJoin", func(t *testing.T) {
 		exec := DeleteJoin[Order](join, nil)
 		require.NotNil(t, exec)
@@ -546,18 +728,258 @@ func TestJoinAPIs_SQLGeneration(t *testing.T) {
 		require.NoError(t, err)
 		// expect DELETE FROM <table> WHERE EXISTS (SELECT 1 FROM profiles WHERE ...)
 		require.True(t, strings.HasPrefix(strings.TrimSpace(q), "DELETE FROM orders"), "unexpected delete prefix: %s", q)
-		require.True(t, strings.Contains(q, "EXISTS (SELECT 1 FROM profiles WHERE profiles.account_id = orders.account_id"), "exists subquery missing: %s", q)
+		require.True(t, strings.Contains(q, "EXISTS (SELECT 1 FROM profiles WHERE orders.account_id = profiles.account_id"), "exists subquery missing: %s", q)
+	})
+
+	t.Run("DeleteJoin_WrongBase_should_error", func(t *testing.T) {
+		_, err := DeleteJoin[Account](join, nil).sql()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "join must start from table accounts")
+		_, err = DeleteJoin[Order](LeftJoin(order.AccountID, profile.AccountID), nil).sql()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "only inner joins")
 	})
 
 	t.Run("UpdateJoin", func(t *testing.T) {
-		// values can be nil for SQL generation test; provide __schema for updateSQLFromValues
-		valuesVO := NewValueObject(map[string]any{"__schema": schema})
-		exec := UpdateJoin[Order](valuesVO)(join, nil)
+		valuesVO := NewValueObject(lo.Assign(lo.SliceToMap(schema, func(f xql.Field) (string, any) { return f.Name(), 1 }), map[string]any{"__schema": schema}))
+		exec := UpdateJoin[Order](valuesVO)(join.InnerJoin(order.AccountID, account.ID), Eq(account.Email, "a@b.c"))
 		require.NotNil(t, exec)
 		q, err := exec.sql()
 		require.NoError(t, err)
 		// expect UPDATE <table> SET ... WHERE EXISTS(...)
 		require.True(t, strings.HasPrefix(strings.TrimSpace(q), "UPDATE orders"), "unexpected update prefix: %s", q)
-		require.True(t, strings.Contains(q, "EXISTS (SELECT 1 FROM profiles WHERE profiles.account_id = orders.account_id"), "exists subquery missing in update: %s", q)
+		require.True(t, strings.HasSuffix(q, "WHERE EXISTS (SELECT 1 FROM profiles, accounts WHERE orders.account_id = profiles.account_id AND orders.account_id = accounts.id AND (accounts.email = ?))"), "exists subquery missing in update: %s", q)
+	})
+}
+
+func TestJoin_Execute_SQLite(t *testing.T) {
+	db := newOrdersDB(t, "join_exec",
+		"CREATE TABLE accounts (id INTEGER PRIMARY KEY, email TEXT, nick_name TEXT, category INTEGER, balance REAL, created_at DATETIME, updated_at DATETIME, created_by TEXT, updated_by TEXT)",
+		"INSERT INTO accounts (id, email) VALUES (1, 'a@x.io'), (2, 'b@x.io')",
+		"INSERT INTO orders (id, account_id, amount) VALUES (1, 1, 10), (2, 1, 20), (3, 2, 30), (4, 9, 40)",
+	)
+	ctx := context.Background()
+	join := InnerJoin(order.AccountID, account.ID)
+
+	res, err := QueryJoin(Schema{order.ID, account.Email})(join.On(Eq(account.Email, "a@x.io")), nil).OrderBy(order.ID, Asc).Execute(ctx, db)
+	require.NoError(t, err)
+	items := res.MustLeft()
+	require.Len(t, items, 2)
+	require.Equal(t, int64(1), items[0].Get("id").MustGet())
+	require.Equal(t, "a@x.io", items[0].Get("email").MustGet())
+
+	_, err = DeleteJoin[Order](join, Eq(account.Email, "b@x.io")).Execute(ctx, db)
+	require.NoError(t, err)
+	var left int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM orders").Scan(&left))
+	require.Equal(t, 3, left)
+}
+
+// TestSqlGeneration_Insert verifies single and multi-row INSERT generation.
+func TestSqlGeneration_Insert(t *testing.T) {
+	schema := Schema(order.AllExclude(order.ID))
+	row := func(accountID int64, amount float64) ValueObject {
+		return NewValueObject(map[string]any{
+			"__schema":   schema,
+			"account_id": accountID,
+			"amount":     amount,
+			"created_by": "john",
+		})
+	}
+
+	cases := []struct {
+		name  string
+		exec  Executor
+		nargs int
+	}{
+		{"Single", Insert[Order](row(1, 10.5)), 3},
+		{"Many", InsertMany[Order](row(1, 10.5), row(2, 20.0)), 6},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			q, err := c.exec.sql()
+			require.NoError(t, err)
+			snapName := "TestSqlGeneration_Insert_" + c.name
+			rawExp, err := loadRawSnapshot(snapName)
+			require.NoError(t, err)
+			rawExp = strings.ReplaceAll(rawExp, "\r\n", "\n")
+			combined := extractLeadingCommentPrefix(rawExp) + formatSQLForComparison(q)
+			require.Equal(t, normalizeForCompare(rawExp), normalizeForCompare(combined), "generated INSERT SQL differs from exact snapshot %s", snapName)
+
+			ie := c.exec.(insertExec[Order])
+			_, args, err := insertSQL[Order](defaultDialect, ie.rows, "")
+			require.NoError(t, err)
+			require.Len(t, args, c.nargs)
+		})
+	}
+
+	t.Run("Returning", func(t *testing.T) {
+		q, _, err := insertSQL[Order](defaultDialect, []ValueObject{row(1, 10.5)}, "id")
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(q, " RETURNING id"), "returning clause missing: %s", q)
+	})
+
+	t.Run("FieldsFallback", func(t *testing.T) {
+		vo := NewValueObject(map[string]any{"accountId": int64(1)})
+		q, args, err := insertSQL[Order](defaultDialect, []ValueObject{vo}, "")
+		require.NoError(t, err)
+		require.Equal(t, "INSERT INTO orders (account_id) VALUES (?)", q)
+		require.Equal(t, []any{int64(1)}, args)
+	})
+
+	t.Run("NoValues_should_error", func(t *testing.T) {
+		_, err := InsertMany[Order]().sql()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "values is required")
+	})
+
+	t.Run("ColumnMismatch_should_error", func(t *testing.T) {
+		other := NewValueObject(map[string]any{"__schema": schema, "account_id": int64(3)})
+		_, err := InsertMany[Order](row(1, 10.5), other).sql()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "column set differs")
+	})
+}
+
+// TestInsert_Execute_SQLite runs Insert/InsertMany against an in-memory sqlite
+// database created from the generated schema and checks the generated keys.
+func TestInsert_Execute_SQLite(t *testing.T) {
+	_, thisFile, _, _ := runtime.Caller(0)
+	ddl, err := os.ReadFile(filepath.Join(filepath.Dir(thisFile), "..", "sample", "gen", "schemas", "sqlite", "order_schema.sql"))
+	require.NoError(t, err)
+
+	db, err := sql.Open("sqlite3", "file:insert_exec?mode=memory&cache=shared")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.Exec(string(ddl))
+	require.NoError(t, err)
+	require.Equal(t, sqliteDriver, driverOf(db))
+
+	schema := Schema(order.AllExclude(order.ID))
+	row := func(accountID int64) ValueObject {
+		return NewValueObject(map[string]any{"__schema": schema, "account_id": accountID, "amount": 1.5})
+	}
+	ctx := context.Background()
+
+	res, err := Insert[Order](row(1)).Execute(ctx, db)
+	require.NoError(t, err)
+	require.True(t, res.IsRight())
+	ir, ok := res.MustRight().(InsertResult)
+	require.True(t, ok)
+	require.Equal(t, []any{int64(1)}, ir.GeneratedKeys())
+
+	res, err = InsertMany[Order](row(2), row(3), row(4)).Execute(ctx, db)
+	require.NoError(t, err)
+	ir = res.MustRight().(InsertResult)
+	require.Equal(t, []any{int64(2), int64(3), int64(4)}, ir.GeneratedKeys())
+	affected, err := ir.RowsAffected()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, affected)
+
+	// explicit primary keys are reported as supplied
+	explicit := NewValueObject(map[string]any{"__schema": Schema(order.All()), "id": int64(100), "account_id": int64(5)})
+	res, err = Insert[Order](explicit).Execute(ctx, db)
+	require.NoError(t, err)
+	require.Equal(t, []any{int64(100)}, res.MustRight().(InsertResult).GeneratedKeys())
+}
+
+// TestSqlGeneration_Paging verifies ORDER BY, LIMIT/OFFSET and keyset rendering.
+func TestSqlGeneration_Paging(t *testing.T) {
+	schema := Schema{order.ID}
+	base := Query[Order](schema)(Gt(order.Amount, 1.0))
+
+	cases := []struct {
+		name  string
+		exec  QueryExecutor
+		d     Dialect
+		exp   string
+		nargs int
+	}{
+		{"OrderBy", base.OrderBy(order.Amount, Desc).OrderBy(order.ID, Asc), sqliteDialect,
+			"SELECT orders.id AS orders__id FROM orders WHERE orders.amount > ? ORDER BY orders.amount DESC, orders.id ASC", 1},
+		{"LimitOffset", base.OrderBy(order.ID, Asc).Limit(10).Offset(20), postgresDialect,
+			"SELECT orders.id AS orders__id FROM orders WHERE orders.amount > $1 ORDER BY orders.id ASC LIMIT 10 OFFSET 20", 1},
+		{"OffsetOnlyMySQL", base.Offset(5), mysqlDialect,
+			"SELECT orders.id AS orders__id FROM orders WHERE orders.amount > ? LIMIT 18446744073709551615 OFFSET 5", 1},
+		{"AfterSingle", Query[Order](schema)(nil).OrderBy(order.ID, Asc).After(7).Limit(2), postgresDialect,
+			"SELECT orders.id AS orders__id FROM orders WHERE orders.id > $1 ORDER BY orders.id ASC LIMIT 2", 1},
+		{"AfterMulti", base.OrderBy(order.Amount, Desc).OrderBy(order.ID, Asc).After(9.5, 3), postgresDialect,
+			"SELECT orders.id AS orders__id FROM orders WHERE (orders.amount > $1 AND (orders.amount < $2 OR (orders.amount = $3 AND orders.id > $4))) ORDER BY orders.amount DESC, orders.id ASC", 4},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			qe := c.exec.(queryExec[Order])
+			q, args, err := selectSQL[Order](c.d, &qe.schema, qe.where, qe.opts)
+			require.NoError(t, err)
+			require.Equal(t, c.exp, q)
+			require.Len(t, args, c.nargs)
+		})
+	}
+
+	t.Run("OptionsDoNotMutate", func(t *testing.T) {
+		_ = base.OrderBy(order.ID, Asc).Limit(3)
+		q, err := base.sql()
+		require.NoError(t, err)
+		require.NotContains(t, q, "ORDER BY")
+		require.NotContains(t, q, "LIMIT")
+	})
+
+	t.Run("AfterWithoutOrderBy_should_error", func(t *testing.T) {
+		_, err := base.After(1).sql()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "after requires order by")
+	})
+
+	t.Run("AfterArity_should_error", func(t *testing.T) {
+		_, err := base.OrderBy(order.ID, Asc).After(1, 2).sql()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "cursor values")
+	})
+
+	t.Run("Negative_should_error", func(t *testing.T) {
+		_, err := base.Limit(-1).sql()
+		require.ErrorContains(t, err, "limit must not be negative: -1")
+		db := newOrdersDB(t, "negative_paging")
+		_, err = QueryJoin(schema)(InnerJoin(order.AccountID, profile.AccountID), nil).Offset(-2).Execute(context.Background(), db)
+		require.ErrorContains(t, err, "offset must not be negative: -2")
+		_, err = QueryInto[Order](Schema(order.All()))(nil).Limit(-3).Execute(context.Background(), db)
+		require.ErrorContains(t, err, "limit must not be negative: -3")
+	})
+
+	t.Run("QueryJoin", func(t *testing.T) {
+		q, err := QueryJoin(schema)(InnerJoin(order.AccountID, profile.AccountID), nil).OrderBy(order.ID, Desc).Limit(5).sql()
+		require.NoError(t, err)
+		require.True(t, strings.HasSuffix(q, "ORDER BY orders.id DESC LIMIT 5"), "unexpected paging clause: %s", q)
 	})
 }
+
+// TestQuery_KeysetPaging_SQLite walks a table page by page using After.
+func TestQuery_KeysetPaging_SQLite(t *testing.T) {
+	db := newOrdersDB(t, "keyset_paging")
+	ctx := context.Background()
+	rows := make([]ValueObject, 0, 7)
+	for i := 1; i <= 7; i++ {
+		rows = append(rows, NewValueObject(map[string]any{"__schema": Schema{order.Amount}, "amount": float64(i % 3)}))
+	}
+	_, err := InsertMany[Order](rows...).Execute(ctx, db)
+	require.NoError(t, err)
+
+	page := Query[Order](Schema{order.ID, order.Amount})(nil).OrderBy(order.Amount, Desc).OrderBy(order.ID, Asc).Limit(3)
+	var seen []any
+	next := page
+	for {
+		res, err := next.Execute(ctx, db)
+		require.NoError(t, err)
+		items := res.MustLeft()
+		if len(items) == 0 {
+			break
+		}
+		for _, it := range items {
+			seen = append(seen, it.Get("id").MustGet())
+		}
+		last := items[len(items)-1]
+		next = page.After(last.Get("amount").MustGet(), last.Get("id").MustGet())
+	}
+	// amount desc (2,2,1,1,1,0,0), id asc within the same amount
+	require.Equal(t, []any{int64(2), int64(5), int64(1), int64(4), int64(7), int64(3), int64(6)}, seen)
+}
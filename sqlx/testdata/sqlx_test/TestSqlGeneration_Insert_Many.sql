@@ -0,0 +1,2 @@
+-- Expected SQL for TestSqlGeneration_Insert_Many
+INSERT INTO orders (account_id, amount, created_by) VALUES (?,?,?), (?,?,?)
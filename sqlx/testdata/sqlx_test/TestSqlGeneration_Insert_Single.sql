@@ -0,0 +1,2 @@
+-- Expected SQL for TestSqlGeneration_Insert_Single
+INSERT INTO orders (account_id, amount, created_by) VALUES (?,?,?)
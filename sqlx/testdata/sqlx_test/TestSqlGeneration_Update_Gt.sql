@@ -1,4 +1,4 @@
 -- Expected SQL for TestSqlGeneration_Update_Gt
-UPDATE orders SET orders.id = ?, orders.account_id = ?, orders.amount = ?, orders.created_at = ?, orders.updated_at = ?, orders.created_by = ?, orders.updated_by = ?
+UPDATE orders SET id = ?, account_id = ?, amount = ?, created_at = ?, updated_at = ?, created_by = ?, updated_by = ?
 WHERE orders.amount > ?
 
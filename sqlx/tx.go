@@ -0,0 +1,161 @@
+package sqlx
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// txBeginner is implemented by *sql.DB and by every DB registered in this package.
+type txBeginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// txKey is the context key under which the active transaction is stored.
+type txKey struct{}
+
+// txState is the transaction bound to a context by WithTx.
+//   - ds is the datasource name the transaction was started on, and db that datasource;
+//   - dialect is the dialect of that datasource, as *sql.Tx does not expose its driver;
+//   - depth is the nesting level, 0 for the outermost call.
+type txState struct {
+	tx      *sql.Tx
+	ds      string
+	db      DB
+	dialect Dialect
+	depth   int
+}
+
+// joins reports whether an executor run on q joins the transaction: q is nil,
+// the transaction itself, its datasource or runs on the same *sql.DB, e.g. the
+// one the datasource wraps with or without a SQL logger.
+func (s *txState) joins(q Querier) bool {
+	same := func(a, b any) bool {
+		t := reflect.TypeOf(a)
+		return t == reflect.TypeOf(b) && t.Comparable() && a == b
+	}
+	if isNilQuerier(q) || same(q, s.tx) || same(q, s.db) {
+		return true
+	}
+	raw, ok := sqlDBOf(s.db)
+	other, otherOK := sqlDBOf(q)
+	return ok && otherOK && raw == other
+}
+
+// savepointDialects lists the dialects that support SAVEPOINT.
+var savepointDialects = map[string]bool{
+	sqliteDriver:   true,
+	mysqlDriver:    true,
+	postgresDriver: true,
+}
+
+// WithTx runs fn inside a transaction on the datasource registered as dsName
+// (empty means the default datasource).
+//
+// The transaction is stored in the context passed to fn; every Executor run
+// with that context on the same datasource, or a nil Querier, joins it. Running
+// one on another datasource fails rather than silently escaping the transaction;
+// start a nested WithTx naming that datasource instead.
+// The transaction is committed when fn returns nil and rolled back when fn
+// returns an error or panics.
+//
+// Nested calls on the same datasource become SAVEPOINTs on drivers that
+// support them (sqlite, mysql, postgres), so a failing inner call only rolls
+// back its own work. On other drivers nested calls simply join the outer
+// transaction. A nested call naming another datasource starts an independent
+// transaction on that datasource.
+//
+// Usage example:
+//
+//	err := WithTx(ctx, "", func(ctx context.Context) error {
+//		if _, err := Insert[Order](vo).Execute(ctx, db); err != nil {
+//			return err
+//		}
+//		_, err := Delete[Cart](Eq(cart.ID, id)).Execute(ctx, db)
+//		return err
+//	})
+func WithTx(ctx context.Context, dsName string, fn func(ctx context.Context) error) (err error) {
+	if dsName == "" {
+		dsName = defaultDs
+	}
+	if outer, ok := ctx.Value(txKey{}).(*txState); ok && outer.ds == dsName {
+		return withSavepoint(ctx, outer, fn)
+	}
+	db, ok := GetDS(dsName)
+	if !ok {
+		return fmt.Errorf("datasource %q is not registered", dsName)
+	}
+	beginner, ok := db.(txBeginner)
+	if !ok {
+		return fmt.Errorf("datasource %q does not support transactions", dsName)
+	}
+	tx, err := beginner.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction on %q: %w", dsName, err)
+	}
+	state := &txState{tx: tx, ds: dsName, db: db, dialect: dialectOf(db)}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				err = fmt.Errorf("%w (rollback: %v)", err, rbErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+	return fn(context.WithValue(ctx, txKey{}, state))
+}
+
+// withSavepoint runs fn inside the outer transaction, guarded by a SAVEPOINT
+// when the driver supports it.
+func withSavepoint(ctx context.Context, outer *txState, fn func(ctx context.Context) error) (err error) {
+	state := &txState{tx: outer.tx, ds: outer.ds, db: outer.db, dialect: outer.dialect, depth: outer.depth + 1}
+	ctx = context.WithValue(ctx, txKey{}, state)
+	if !savepointDialects[outer.dialect.Name()] {
+		return fn(ctx)
+	}
+	name := fmt.Sprintf("sp_%d", state.depth)
+	if _, err = outer.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("create savepoint %s: %w", name, err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = outer.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(p)
+		}
+		if err != nil {
+			if _, rbErr := outer.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+				err = fmt.Errorf("%w (rollback to savepoint: %v)", err, rbErr)
+			}
+			return
+		}
+		if _, relErr := outer.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); relErr != nil {
+			err = fmt.Errorf("release savepoint %s: %w", name, relErr)
+		}
+	}()
+	return fn(ctx)
+}
+
+// querierFrom returns the Querier an executor should run on together with its
+// dialect: the transaction bound to ctx by WithTx when ds is nil or runs on the
+// datasource of the transaction, otherwise ds. ds running on another datasource
+// than the transaction is an error.
+func querierFrom(ctx context.Context, ds Querier) (Querier, Dialect, error) {
+	if ctx != nil {
+		if state, ok := ctx.Value(txKey{}).(*txState); ok {
+			if !state.joins(ds) {
+				return nil, nil, fmt.Errorf("the querier is not datasource %q of the transaction in ctx; run it in a WithTx on its own datasource", state.ds)
+			}
+			return state.tx, state.dialect, nil
+		}
+	}
+	if isNilQuerier(ds) {
+		return nil, defaultDialect, fmt.Errorf("db is required")
+	}
+	return ds, dialectOf(ds), nil
+}
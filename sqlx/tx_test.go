@@ -0,0 +1,140 @@
+package sqlx
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"testing"
+
+	. "github.com/kcmvp/xql/sample/entity"
+	"github.com/kcmvp/xql/sample/gen/field/order"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTxDS registers an in-memory sqlite datasource with an orders table.
+func setupTxDS(t *testing.T, name string) *sql.DB {
+	t.Helper()
+	raw := newOrdersDB(t, name)
+	dsMu.Lock()
+	dsRegistry[name] = stdDB{DB: raw}
+	dsMu.Unlock()
+	t.Cleanup(func() { _ = CloseDataSource(name) })
+	return raw
+}
+
+func countOrders(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var n int
+	require.NoError(t, db.QueryRow("SELECT COUNT(1) FROM orders").Scan(&n))
+	return n
+}
+
+func TestWithTx(t *testing.T) {
+	raw := setupTxDS(t, "tx_test")
+	schema := Schema(order.AllExclude(order.ID))
+	insert := func(ctx context.Context, accountID int64) error {
+		vo := NewValueObject(map[string]any{"__schema": schema, "account_id": accountID})
+		// raw is the datasource of the transaction in ctx, so the insert joins it
+		_, err := Insert[Order](vo).Execute(ctx, raw)
+		return err
+	}
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	t.Run("Commit", func(t *testing.T) {
+		err := WithTx(ctx, "tx_test", func(ctx context.Context) error {
+			require.NoError(t, insert(ctx, 1))
+			return insert(ctx, 2)
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, countOrders(t, raw))
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		err := WithTx(ctx, "tx_test", func(ctx context.Context) error {
+			require.NoError(t, insert(ctx, 3))
+			return boom
+		})
+		require.ErrorIs(t, err, boom)
+		require.Equal(t, 2, countOrders(t, raw))
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		require.Panics(t, func() {
+			_ = WithTx(ctx, "tx_test", func(ctx context.Context) error {
+				require.NoError(t, insert(ctx, 4))
+				panic("boom")
+			})
+		})
+		require.Equal(t, 2, countOrders(t, raw))
+	})
+
+	t.Run("NestedSavepoint", func(t *testing.T) {
+		err := WithTx(ctx, "tx_test", func(ctx context.Context) error {
+			require.NoError(t, insert(ctx, 5))
+			inner := WithTx(ctx, "tx_test", func(ctx context.Context) error {
+				require.NoError(t, insert(ctx, 6))
+				return boom
+			})
+			require.ErrorIs(t, inner, boom)
+			return WithTx(ctx, "tx_test", func(ctx context.Context) error {
+				return insert(ctx, 7)
+			})
+		})
+		require.NoError(t, err)
+		require.Equal(t, 4, countOrders(t, raw))
+		res, err := Query[Order](Schema(order.All()))(Eq(order.AccountID, 6)).Execute(ctx, raw)
+		require.NoError(t, err)
+		require.Empty(t, res.MustLeft())
+	})
+
+	t.Run("OtherDataSource", func(t *testing.T) {
+		other := setupTxDS(t, "tx_other_test")
+		vo := NewValueObject(map[string]any{"__schema": schema, "account_id": int64(8)})
+		err := WithTx(ctx, "tx_test", func(ctx context.Context) error {
+			// a nil querier joins the transaction
+			if _, err := Insert[Order](vo).Execute(ctx, nil); err != nil {
+				return err
+			}
+			_, err := Insert[Order](vo).Execute(ctx, other)
+			return err
+		})
+		require.ErrorContains(t, err, `not datasource "tx_test" of the transaction`)
+		require.Equal(t, 4, countOrders(t, raw))
+		require.Zero(t, countOrders(t, other))
+	})
+
+	t.Run("UnknownDataSource", func(t *testing.T) {
+		err := WithTx(ctx, "missing", func(ctx context.Context) error { return nil })
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not registered")
+	})
+}
+
+func TestWithTx_SQLLogger(t *testing.T) {
+	raw := newOrdersDB(t, "tx_logger_test")
+	var buf bytes.Buffer
+	logged := WithSQLLogger(stdDB{DB: raw}, log.New(&buf, "", 0))
+	dsMu.Lock()
+	dsRegistry["tx_logger_test"] = logged
+	dsMu.Unlock()
+	t.Cleanup(func() { _ = CloseDataSource("tx_logger_test") })
+	schema := Schema(order.AllExclude(order.ID))
+	vo := NewValueObject(map[string]any{"__schema": schema, "account_id": int64(1)})
+	boom := errors.New("boom")
+
+	// the raw *sql.DB, the datasource and another wrapper of it all join the transaction
+	err := WithTx(context.Background(), "tx_logger_test", func(ctx context.Context) error {
+		for _, q := range []Querier{raw, logged, stdDB{DB: raw}} {
+			if _, err := Insert[Order](vo).Execute(ctx, q); err != nil {
+				return err
+			}
+		}
+		return boom
+	})
+	require.ErrorIs(t, err, boom)
+	// the inserts were rolled back with the transaction
+	require.Zero(t, countOrders(t, raw))
+}
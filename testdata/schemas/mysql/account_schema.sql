@@ -12,4 +12,4 @@ CREATE TABLE IF NOT EXISTS accounts (
     created_by TEXT,
     updated_by TEXT
 );
-CREATE INDEX IF NOT EXISTS idx_accounts_email ON accounts (email);
+CREATE INDEX idx_accounts_email ON accounts (email);
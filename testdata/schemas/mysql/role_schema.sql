@@ -10,5 +10,5 @@ CREATE TABLE IF NOT EXISTS roles (
     created_by TEXT,
     updated_by TEXT
 );
-CREATE INDEX IF NOT EXISTS idx_roles_key ON roles (key);
+CREATE INDEX idx_roles_key ON roles (key);
 
@@ -1,15 +1,19 @@
 package validator
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"net/mail"
 	"net/url"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/kcmvp/xql/decimal"
 	"github.com/samber/mo"
 	"github.com/tidwall/match"
 
@@ -22,13 +26,64 @@ type Number interface {
 	uint | uint8 | uint16 | uint32 | uint64 | int | int8 | int16 | int32 | int64 | float32 | float64
 }
 
-// FieldType is a constraint for the actual Go types we want to validate.
+// FieldType is a constraint for the actual Go types we want to validate:
+// numbers, strings, booleans and times, decimal.Decimal, uuid.UUID,
+// time.Duration, []byte (base64 in JSON) and Enum types.
 type FieldType interface {
-	Number | string | time.Time | bool
+	Number | string | time.Time | bool | decimal.Decimal | uuid.UUID | time.Duration | []byte | Enum
+}
+
+// Enum matches Go enum types defined over a string or an integer, e.g.
+//
+//	type Status string
+//	const (
+//		Active   Status = "active"
+//		Inactive Status = "inactive"
+//	)
+//
+// Restrict the accepted values with OneOf(Active, Inactive).
+type Enum interface {
+	~string | ~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Ordered is a constraint for the types supported by the comparison validators.
+type Ordered interface {
+	Number | time.Time | time.Duration | decimal.Decimal
 }
 
 type Validator[T FieldType] func(v T) error
-type ValidateFunc[T FieldType] func() (string, Validator[T])
+
+// ValidateFunc is a validator factory. It returns the Rule describing the
+// validator together with the validator itself.
+type ValidateFunc[T FieldType] func() (Rule, Validator[T])
+
+// Rule describes a validator so it can be introspected, e.g. to export a
+// schema. Name is also the error code of the validator; Params are the
+// arguments of the validator, e.g. {"min": 3} for MinLength(3).
+//
+// A custom validator names itself with a Rule literal:
+//
+//	func Even() validator.ValidateFunc[int] {
+//		return func() (validator.Rule, validator.Validator[int]) {
+//			return validator.Rule{Name: "even"}, func(v int) error { ... }
+//		}
+//	}
+type Rule struct {
+	Name   string
+	Params map[string]any
+}
+
+// Named adapts a validator factory written against the former ValidateFunc
+// signature, func() (string, Validator[T]), which returned the name only. The
+// validator is described with a Rule of that name and no parameters.
+//
+//	view.Field[int]("count", validator.Named(legacyEven))
+func Named[T FieldType](f func() (string, Validator[T])) ValidateFunc[T] {
+	return func() (Rule, Validator[T]) {
+		name, v := f()
+		return Rule{Name: name}, v
+	}
+}
 
 const (
 	LowerCaseChar charSet = iota
@@ -48,6 +103,7 @@ var (
 	ErrIntegerOverflow = errors.New("integer overflow")
 	ErrTypeMismatch    = errors.New("type mismatch")
 	ErrRequired        = errors.New("is required but not found")
+	ErrNull            = errors.New("is null but not nullable")
 
 	ErrLengthMin     = errors.New("length must be at least")
 	ErrLengthMax     = errors.New("length must be at most")
@@ -59,6 +115,7 @@ var (
 	ErrCharSetAll    = errors.New("not contains chars from")
 	ErrCharSetNo     = errors.New("must not contain any characters from")
 	ErrNotMatch      = errors.New("not match pattern")
+	ErrNotMatchRegex = errors.New("not match regular expression")
 	ErrNotValidEmail = errors.New("not valid email address")
 	ErrNotValidURL   = errors.New("not valid url")
 	ErrNotOneOf      = errors.New("value must be one of")
@@ -71,6 +128,48 @@ var (
 	ErrMustBeFalse   = errors.New("must be false")
 )
 
+// RuleError is returned by the built-in validators when a value is rejected.
+// It wraps the sentinel error (ErrLengthMin, ErrMustGt, ...), so errors.Is
+// keeps working, and carries the parameters of the rule, e.g. {"min": 3} for
+// MinLength(3), for structured error reporting.
+type RuleError struct {
+	Params map[string]any
+	err    error
+}
+
+func (e *RuleError) Error() string { return e.err.Error() }
+
+func (e *RuleError) Unwrap() error { return e.err }
+
+// ruleError wraps err in a RuleError; kv are alternating parameter names and values.
+func ruleError(err error, kv ...any) error {
+	return &RuleError{Params: paramsOf(kv), err: err}
+}
+
+// newRule describes a built-in validator; kv are alternating parameter names and values.
+func newRule(name string, kv ...any) Rule {
+	return Rule{Name: name, Params: paramsOf(kv)}
+}
+
+func paramsOf(kv []any) map[string]any {
+	if len(kv) == 0 {
+		return nil
+	}
+	params := make(map[string]any, len(kv)/2)
+	for i := 0; i+1 < len(kv); i += 2 {
+		params[kv[i].(string)] = kv[i+1]
+	}
+	return params
+}
+
+// charSetNames returns the descriptive names of the character sets.
+func charSetNames(charSets []charSet) []string {
+	return lo.Map(charSets, func(set charSet, _ int) string {
+		_, name := set.value()
+		return name
+	})
+}
+
 // value is a private helper to get the character set and its descriptive name.
 func (set charSet) value() (chars string, name string) {
 	switch set {
@@ -91,27 +190,27 @@ func (set charSet) value() (chars string, name string) {
 
 // MinLength validates that a string's length is at least the specified minimum.
 func MinLength(min int) ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "min_length", func(str string) error {
-			return lo.Ternary(len(str) < min, fmt.Errorf("%w %d ", ErrLengthMin, min), nil)
+	return func() (Rule, Validator[string]) {
+		return newRule("min_length", "min", min), func(str string) error {
+			return lo.Ternary(len(str) < min, ruleError(fmt.Errorf("%w %d ", ErrLengthMin, min), "min", min), nil)
 		}
 	}
 }
 
 // MaxLength validates that a string's length is at most the specified maximum.
 func MaxLength(max int) ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "max_length", func(str string) error {
-			return lo.Ternary(len(str) > max, fmt.Errorf("%w %d ", ErrLengthMax, max), nil)
+	return func() (Rule, Validator[string]) {
+		return newRule("max_length", "max", max), func(str string) error {
+			return lo.Ternary(len(str) > max, ruleError(fmt.Errorf("%w %d ", ErrLengthMax, max), "max", max), nil)
 		}
 	}
 }
 
 // ExactLength validates that a string's length is exactly the specified length.
 func ExactLength(length int) ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "exact_length", func(str string) error {
-			return lo.Ternary(len(str) != length, fmt.Errorf("%w %d characters", ErrLengthExact, length), nil)
+	return func() (Rule, Validator[string]) {
+		return newRule("exact_length", "length", length), func(str string) error {
+			return lo.Ternary(len(str) != length, ruleError(fmt.Errorf("%w %d characters", ErrLengthExact, length), "length", length), nil)
 		}
 	}
 
@@ -119,18 +218,18 @@ func ExactLength(length int) ValidateFunc[string] {
 
 // LengthBetween validates that a string's length is within a given range (inclusive).
 func LengthBetween(min, max int) ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "length_between", func(str string) error {
+	return func() (Rule, Validator[string]) {
+		return newRule("length_between", "min", min, "max", max), func(str string) error {
 			length := len(str)
-			return lo.Ternary(length < min || length > max, fmt.Errorf("%w %d and %d characters", ErrLengthBetween, min, max), nil)
+			return lo.Ternary(length < min || length > max, ruleError(fmt.Errorf("%w %d and %d characters", ErrLengthBetween, min, max), "min", min, "max", max), nil)
 		}
 	}
 }
 
 // CharSetOnly validates that a string only contains characters from the specified character sets.
 func CharSetOnly(charSets ...charSet) ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "only_contains", func(str string) error {
+	return func() (Rule, Validator[string]) {
+		return newRule("only_contains", "charsets", charSetNames(charSets)), func(str string) error {
 			var allChars strings.Builder
 			var names []string
 			for _, set := range charSets {
@@ -140,7 +239,7 @@ func CharSetOnly(charSets ...charSet) ValidateFunc[string] {
 			}
 			for _, r := range str {
 				if !strings.ContainsRune(allChars.String(), r) {
-					return fmt.Errorf("%w: %s", ErrCharSetOnly, strings.Join(names, ", "))
+					return ruleError(fmt.Errorf("%w: %s", ErrCharSetOnly, strings.Join(names, ", ")), "charsets", names)
 				}
 			}
 			return nil
@@ -150,8 +249,8 @@ func CharSetOnly(charSets ...charSet) ValidateFunc[string] {
 
 // CharSetAny validates that a string contains at least one character from any of the specified character sets.
 func CharSetAny(charSets ...charSet) ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "contains_any", func(str string) error {
+	return func() (Rule, Validator[string]) {
+		return newRule("contains_any", "charsets", charSetNames(charSets)), func(str string) error {
 			var allChars strings.Builder
 			var names []string
 			for _, set := range charSets {
@@ -159,19 +258,19 @@ func CharSetAny(charSets ...charSet) ValidateFunc[string] {
 				allChars.WriteString(chars)
 				names = append(names, name)
 			}
-			return lo.Ternary(!strings.ContainsAny(allChars.String(), str), fmt.Errorf("%w: %s", ErrCharSetAny, strings.Join(names, ", ")), nil)
+			return lo.Ternary(!strings.ContainsAny(allChars.String(), str), ruleError(fmt.Errorf("%w: %s", ErrCharSetAny, strings.Join(names, ", ")), "charsets", names), nil)
 		}
 	}
 }
 
 // CharSetAll validates that a string contains at least one character from each of the specified character sets.
 func CharSetAll(charSets ...charSet) ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "contains_all", func(str string) error {
+	return func() (Rule, Validator[string]) {
+		return newRule("contains_all", "charsets", charSetNames(charSets)), func(str string) error {
 			for _, set := range charSets {
 				chars, name := set.value()
 				if !strings.ContainsAny(chars, str) {
-					return fmt.Errorf("%w: %s", ErrCharSetAll, name)
+					return ruleError(fmt.Errorf("%w: %s", ErrCharSetAll, name), "charset", name)
 				}
 			}
 			return nil
@@ -182,12 +281,12 @@ func CharSetAll(charSets ...charSet) ValidateFunc[string] {
 
 // CharSetNo validates that a string does not contain any characters from the specified character sets.
 func CharSetNo(charSets ...charSet) ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "not_contains", func(str string) error {
+	return func() (Rule, Validator[string]) {
+		return newRule("not_contains", "charsets", charSetNames(charSets)), func(str string) error {
 			for _, set := range charSets {
 				chars, name := set.value()
 				if strings.ContainsAny(str, chars) {
-					return fmt.Errorf("%s: %s", ErrCharSetNo, name)
+					return ruleError(fmt.Errorf("%w: %s", ErrCharSetNo, name), "charset", name)
 				}
 			}
 			return nil
@@ -203,17 +302,29 @@ func CharSetNo(charSets ...charSet) ValidateFunc[string] {
 // Example: Match("foo*") will match "foobar", "foo", etc.
 func Match(pattern string) ValidateFunc[string] {
 	lo.Assertf(match.IsPattern(pattern), "invalid pattern `%s`: `?` stands for one character, `*` stands for any number of characters", pattern)
-	return func() (string, Validator[string]) {
-		return "match", func(str string) error {
-			return lo.Ternary(!match.Match(str, pattern), fmt.Errorf("%w %s", ErrNotMatch, pattern), nil)
+	return func() (Rule, Validator[string]) {
+		return newRule("match", "pattern", pattern), func(str string) error {
+			return lo.Ternary(!match.Match(str, pattern), ruleError(fmt.Errorf("%w %s", ErrNotMatch, pattern), "pattern", pattern), nil)
+		}
+	}
+}
+
+// Pattern validates that a string matches a regular expression, like the
+// `pattern` keyword of JSON Schema. The expression is not implicitly anchored.
+// It panics if expr is not a valid regular expression.
+func Pattern(expr string) ValidateFunc[string] {
+	re := regexp.MustCompile(expr)
+	return func() (Rule, Validator[string]) {
+		return newRule("pattern", "pattern", expr), func(str string) error {
+			return lo.Ternary(!re.MatchString(str), ruleError(fmt.Errorf("%w %s", ErrNotMatchRegex, expr), "pattern", expr), nil)
 		}
 	}
 }
 
 // Email validates that a string is a valid email address.
 func Email() ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "email", func(str string) error {
+	return func() (Rule, Validator[string]) {
+		return newRule("email"), func(str string) error {
 			return lo.Ternary(mo.TupleToResult[*mail.Address](mail.ParseAddress(str)).IsError(), fmt.Errorf("%w:%s", ErrNotValidEmail, str), nil)
 		}
 	}
@@ -221,8 +332,8 @@ func Email() ValidateFunc[string] {
 
 // URL validates that a string is a valid URL.
 func URL() ValidateFunc[string] {
-	return func() (string, Validator[string]) {
-		return "url", func(str string) error {
+	return func() (Rule, Validator[string]) {
+		return newRule("url"), func(str string) error {
 			rs := mo.TupleToResult[*url.URL](url.Parse(str))
 			errRs := rs.IsError() || rs.MustGet().Scheme == "" || rs.MustGet().Host == ""
 			return lo.Ternary(errRs, fmt.Errorf("%w: %s", ErrNotValidURL, str), nil)
@@ -233,56 +344,60 @@ func URL() ValidateFunc[string] {
 // --- Generic and Comparison types.Validators ---
 
 // OneOf validates that a value is one of the allowed values.
-// This works for any comparable type in FieldType (string, bool, all numbers).
-func OneOf[T FieldType](allowed ...T) ValidateFunc[T] {
-	return func() (string, Validator[T]) {
-		return "one_of", func(val T) error {
-			return lo.Ternary(!lo.Contains(allowed, val), fmt.Errorf("%w:%v", ErrNotOneOf, allowed), nil)
+// This works for any comparable type in FieldType (string, bool, all numbers,
+// enums, decimals, uuids and durations); decimals are compared by value.
+func OneOf[T interface {
+	FieldType
+	comparable
+}](allowed ...T) ValidateFunc[T] {
+	return func() (Rule, Validator[T]) {
+		return newRule("one_of", "allowed", allowed), func(val T) error {
+			return lo.Ternary(!lo.ContainsBy(allowed, func(a T) bool { return equal(a, val) }), ruleError(fmt.Errorf("%w:%v", ErrNotOneOf, allowed), "allowed", allowed), nil)
 		}
 	}
 }
 
 // Gt validates that a value is greater than the specified minimum.
-func Gt[T Number | time.Time](min T) ValidateFunc[T] {
-	return func() (string, Validator[T]) {
-		return "gt", func(val T) error {
-			return lo.Ternary(!isGreaterThan(val, min), fmt.Errorf("%w %v", ErrMustGt, min), nil)
+func Gt[T Ordered](min T) ValidateFunc[T] {
+	return func() (Rule, Validator[T]) {
+		return newRule("gt", "min", min), func(val T) error {
+			return lo.Ternary(!isGreaterThan(val, min), ruleError(fmt.Errorf("%w %v", ErrMustGt, min), "min", min), nil)
 		}
 	}
 }
 
 // Gte validates that a value is greater than or equal to the specified minimum.
-func Gte[T Number | time.Time](min T) ValidateFunc[T] {
-	return func() (string, Validator[T]) {
-		return "gte", func(val T) error {
-			return lo.Ternary(isLessThan(val, min), fmt.Errorf("%w %v", ErrMustGte, min), nil)
+func Gte[T Ordered](min T) ValidateFunc[T] {
+	return func() (Rule, Validator[T]) {
+		return newRule("gte", "min", min), func(val T) error {
+			return lo.Ternary(isLessThan(val, min), ruleError(fmt.Errorf("%w %v", ErrMustGte, min), "min", min), nil)
 		}
 	}
 }
 
 // Lt validates that a value is less than the specified maximum.
-func Lt[T Number | time.Time](max T) ValidateFunc[T] {
-	return func() (string, Validator[T]) {
-		return "lt", func(val T) error {
-			return lo.Ternary(!isLessThan(val, max), fmt.Errorf("%w %v", ErrMustLt, max), nil)
+func Lt[T Ordered](max T) ValidateFunc[T] {
+	return func() (Rule, Validator[T]) {
+		return newRule("lt", "max", max), func(val T) error {
+			return lo.Ternary(!isLessThan(val, max), ruleError(fmt.Errorf("%w %v", ErrMustLt, max), "max", max), nil)
 		}
 	}
 }
 
 // Lte validates that a value is less than or equal to the specified maximum.
-func Lte[T Number | time.Time](max T) ValidateFunc[T] {
-	return func() (string, Validator[T]) {
-		return "lte", func(val T) error {
-			return lo.Ternary(isGreaterThan(val, max), fmt.Errorf("%w %v", ErrMustLte, max), nil)
+func Lte[T Ordered](max T) ValidateFunc[T] {
+	return func() (Rule, Validator[T]) {
+		return newRule("lte", "max", max), func(val T) error {
+			return lo.Ternary(isGreaterThan(val, max), ruleError(fmt.Errorf("%w %v", ErrMustLte, max), "max", max), nil)
 		}
 	}
 }
 
 // Between validates that a value is within a given range (inclusive of min and max).
-func Between[T Number | time.Time](min, max T) ValidateFunc[T] {
-	return func() (string, Validator[T]) {
-		return "between", func(val T) error {
-			return lo.Ternary(isLessThan(val, min) || isGreaterThan(val, max), fmt.Errorf("%w %v and %v", ErrMustBetween, min, max), nil)
+func Between[T Ordered](min, max T) ValidateFunc[T] {
+	return func() (Rule, Validator[T]) {
+		return newRule("between", "min", min, "max", max), func(val T) error {
+			return lo.Ternary(isLessThan(val, min) || isGreaterThan(val, max), ruleError(fmt.Errorf("%w %v and %v", ErrMustBetween, min, max), "min", min, "max", max), nil)
 		}
 	}
 }
@@ -291,8 +406,8 @@ func Between[T Number | time.Time](min, max T) ValidateFunc[T] {
 
 // BeTrue validates that a boolean value is true.
 func BeTrue() ValidateFunc[bool] {
-	return func() (string, Validator[bool]) {
-		return "be_true", func(b bool) error {
+	return func() (Rule, Validator[bool]) {
+		return newRule("be_true"), func(b bool) error {
 			return lo.Ternary(!b, ErrMustBeTrue, nil)
 		}
 	}
@@ -300,20 +415,32 @@ func BeTrue() ValidateFunc[bool] {
 
 // BeFalse validates that a boolean value is false.
 func BeFalse() ValidateFunc[bool] {
-	return func() (string, Validator[bool]) {
-		return "be_false", func(b bool) error {
+	return func() (Rule, Validator[bool]) {
+		return newRule("be_false"), func(b bool) error {
 			return lo.Ternary(b, ErrMustBeFalse, nil)
 		}
 	}
 }
 
+// equal compares two values; decimals are equal when their values are.
+func equal[T comparable](a, b T) bool {
+	if d, ok := any(a).(decimal.Decimal); ok {
+		return d.Equal(any(b).(decimal.Decimal))
+	}
+	return a == b
+}
+
 // isGreaterThan is a helper function that compares two values of type Number or time.Time
 // and returns true if 'a' is strictly greater than 'b'.
 // It handles different numeric types and time.Time by type assertion.
-func isGreaterThan[T Number | time.Time](a, b T) bool {
+func isGreaterThan[T Ordered](a, b T) bool {
 	switch v := any(a).(type) {
 	case time.Time:
 		return v.After(any(b).(time.Time))
+	case time.Duration:
+		return v > any(b).(time.Duration)
+	case decimal.Decimal:
+		return v.Cmp(any(b).(decimal.Decimal)) > 0
 	case int:
 		return v > any(b).(int)
 	case int8:
@@ -346,10 +473,14 @@ func isGreaterThan[T Number | time.Time](a, b T) bool {
 // and returns true if 'a' is strictly less than 'b'.
 // It handles different numeric types and time.Time by type assertion.
 
-func isLessThan[T Number | time.Time](a, b T) bool {
+func isLessThan[T Ordered](a, b T) bool {
 	switch v := any(a).(type) {
 	case time.Time:
 		return v.Before(any(b).(time.Time))
+	case time.Duration:
+		return v < any(b).(time.Duration)
+	case decimal.Decimal:
+		return v.Cmp(any(b).(decimal.Decimal)) < 0
 	case int:
 		return v < any(b).(int)
 	case int8:
@@ -387,9 +518,28 @@ func ParseStringTo[T FieldType](s string) (T, error) {
 	var zero T
 	targetType := reflect.TypeOf(zero)
 
+	// types with their own text format come before the kinds they are built on
+	var v any
+	var err error
+	switch any(zero).(type) {
+	case time.Duration:
+		v, err = time.ParseDuration(s)
+	case decimal.Decimal:
+		v, err = decimal.Parse(s)
+	case uuid.UUID:
+		v, err = uuid.Parse(s)
+	case []byte:
+		v, err = base64.StdEncoding.DecodeString(s)
+	}
+	if err != nil {
+		return zero, fmt.Errorf("could not parse '%s' as %T: %w", s, zero, err)
+	} else if v != nil {
+		return v.(T), nil
+	}
+
 	switch targetType.Kind() {
 	case reflect.String:
-		return any(s).(T), nil
+		return reflect.ValueOf(s).Convert(targetType).Interface().(T), nil
 	case reflect.Bool:
 		b, err := strconv.ParseBool(s)
 		if err != nil {
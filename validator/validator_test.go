@@ -1,7 +1,14 @@
 package validator
 
 import (
+	"errors"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kcmvp/xql/decimal"
+	"github.com/samber/lo"
 )
 
 // Full set of tests migrated from meta/constraint_test.go
@@ -119,6 +126,68 @@ func TestGtBasic(t *testing.T) {
 	}
 }
 
+type status string
+
+type level int
+
+func TestFieldKinds(t *testing.T) {
+	_, oneOf := OneOf(decimal.MustParse("1.5"), decimal.MustParse("2"))()
+	if err := oneOf(decimal.MustParse("1.50")); err != nil {
+		t.Errorf("OneOf decimal error = %v", err)
+	}
+	if err := oneOf(decimal.MustParse("1.25")); !errors.Is(err, ErrNotOneOf) {
+		t.Errorf("OneOf decimal error = %v, want %v", err, ErrNotOneOf)
+	}
+	_, enum := OneOf[status]("active", "inactive")()
+	if err := enum("deleted"); !errors.Is(err, ErrNotOneOf) {
+		t.Errorf("OneOf enum error = %v, want %v", err, ErrNotOneOf)
+	}
+	_, gt := Gt(time.Second)()
+	if err := gt(time.Millisecond); !errors.Is(err, ErrMustGt) {
+		t.Errorf("Gt duration error = %v, want %v", err, ErrMustGt)
+	}
+	_, between := Between(decimal.MustParse("0.01"), decimal.MustParse("100"))()
+	if err := between(decimal.MustParse("99.99")); err != nil {
+		t.Errorf("Between decimal error = %v", err)
+	}
+	if err := between(decimal.MustParse("0.001")); !errors.Is(err, ErrMustBetween) {
+		t.Errorf("Between decimal error = %v, want %v", err, ErrMustBetween)
+	}
+}
+
+func TestParseStringTo_FieldKinds(t *testing.T) {
+	check := func(got any, err error, want any) {
+		t.Helper()
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseStringTo() = %v, %v, want %v", got, err, want)
+		}
+	}
+	d, err := ParseStringTo[time.Duration]("1h30m")
+	check(d, err, 90*time.Minute)
+	id, err := ParseStringTo[uuid.UUID]("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	check(id, err, uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+	b, err := ParseStringTo[[]byte]("aGVsbG8=")
+	check(b, err, []byte("hello"))
+	s, err := ParseStringTo[status]("active")
+	check(s, err, status("active"))
+	l, err := ParseStringTo[level]("3")
+	check(l, err, level(3))
+	dec, err := ParseStringTo[decimal.Decimal]("12.50")
+	check(dec.String(), err, "12.50")
+
+	for name, parse := range map[string]func() error{
+		"duration": func() error { _, err := ParseStringTo[time.Duration]("soon"); return err },
+		"uuid":     func() error { _, err := ParseStringTo[uuid.UUID]("123"); return err },
+		"bytes":    func() error { _, err := ParseStringTo[[]byte]("%%%"); return err },
+		"decimal":  func() error { _, err := ParseStringTo[decimal.Decimal]("1,5"); return err },
+		"level":    func() error { _, err := ParseStringTo[level]("high"); return err },
+	} {
+		if err := parse(); err == nil {
+			t.Errorf("ParseStringTo[%s]() expected an error", name)
+		}
+	}
+}
+
 func TestBetweenBasic(t *testing.T) {
 	_, v := Between[int](1, 3)()
 	if err := v(2); err != nil {
@@ -171,3 +240,67 @@ func TestSmokeValidatorsCompile(t *testing.T) {
 	_, b1 := BeFalse()()
 	_ = b1(false)
 }
+
+func TestPattern(t *testing.T) {
+	rule, v := Pattern(`^[a-z]+-\d+$`)()
+	if want := (Rule{Name: "pattern", Params: map[string]any{"pattern": `^[a-z]+-\d+$`}}); !reflect.DeepEqual(rule, want) {
+		t.Errorf("Pattern() rule = %v, want %v", rule, want)
+	}
+	if err := v("order-42"); err != nil {
+		t.Errorf("Pattern() error = %v, want nil", err)
+	}
+	if err := v("Order-42"); !errors.Is(err, ErrNotMatchRegex) {
+		t.Errorf("Pattern() error = %v, want %v", err, ErrNotMatchRegex)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Pattern() with an invalid expression should panic")
+		}
+	}()
+	Pattern("[")
+}
+
+func TestRules(t *testing.T) {
+	rule := func(vf func() (Rule, Validator[string])) Rule {
+		r, _ := vf()
+		return r
+	}
+	tests := []struct {
+		name string
+		got  Rule
+		want Rule
+	}{
+		{"min_length", rule(MinLength(2)), Rule{Name: "min_length", Params: map[string]any{"min": 2}}},
+		{"length_between", rule(LengthBetween(2, 4)), Rule{Name: "length_between", Params: map[string]any{"min": 2, "max": 4}}},
+		{"only_contains", rule(CharSetOnly(LowerCaseChar, NumberChar)), Rule{Name: "only_contains", Params: map[string]any{"charsets": []string{"lower case characters", "numbers"}}}},
+		{"match", rule(Match("a*")), Rule{Name: "match", Params: map[string]any{"pattern": "a*"}}},
+		{"email", rule(Email()), Rule{Name: "email"}},
+		{"one_of", rule(OneOf("x", "y")), Rule{Name: "one_of", Params: map[string]any{"allowed": []string{"x", "y"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("rule = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+	r, _ := Between(1.5, 3.0)()
+	if !reflect.DeepEqual(r, Rule{Name: "between", Params: map[string]any{"min": 1.5, "max": 3.0}}) {
+		t.Errorf("rule = %v", r)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	even := func() (string, Validator[int]) {
+		return "even", func(v int) error {
+			return lo.Ternary(v%2 != 0, errors.New("must be even"), nil)
+		}
+	}
+	r, v := Named(even)()
+	if !reflect.DeepEqual(r, Rule{Name: "even"}) {
+		t.Errorf("rule = %v", r)
+	}
+	if v(2) != nil || v(3) == nil {
+		t.Errorf("Named() should keep the validator")
+	}
+}
@@ -2,8 +2,11 @@ package vom
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"sync"
 
 	"github.com/kcmvp/xql/internal"
@@ -34,44 +37,58 @@ func SetGlobalEnricher(enrich EnrichFunc) {
 	})
 }
 
-func urlParams(ctx echo.Context) map[string]string {
-	params := lo.Associate(ctx.ParamNames(), func(name string) (string, string) {
-		return name, ctx.Param(name)
+// urlParams returns the path parameters and the query parameters of the request.
+// They are validated as separate sources, so a conflicting name is reported as
+// a duplicate field instead of overwriting one another.
+func urlParams(ctx echo.Context) []url.Values {
+	path := lo.Associate(ctx.ParamNames(), func(name string) (string, []string) {
+		return name, []string{ctx.Param(name)}
 	})
-	// Add query parameters
-	for name, values := range ctx.QueryParams() {
-		lo.Assertf(len(values) == 1, "query parameter '%s' has multiple values, which is not supported", name)
-		_, ok := params[name]
-		lo.Assertf(!ok, "path parameter and query parameter have conflicting names: '%s'", name)
-		params[name] = values[0]
-	}
-	return params
+	return []url.Values{path, ctx.QueryParams()}
+}
 
+// validate validates the form body or the JSON body of the request.
+func validate(ctx echo.Context, schema *view.Schema) mo.Result[view.ValueObject] {
+	req := ctx.Request()
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+	switch mediaType {
+	case echo.MIMEApplicationForm:
+		if err := req.ParseForm(); err != nil {
+			return mo.Err[view.ValueObject](err)
+		}
+		// PostForm holds the body values only; Form also holds the query.
+		return schema.ValidateForm(req.PostForm, urlParams(ctx)...)
+	case echo.MIMEMultipartForm:
+		if _, err := ctx.MultipartForm(); err != nil {
+			return mo.Err[view.ValueObject](err)
+		}
+		return schema.ValidateForm(req.PostForm, urlParams(ctx)...)
+	}
+	// Read the entire request body. The body is read into memory here.
+	// For very large request bodies, a streaming approach might be preferable.
+	bts, err := io.ReadAll(req.Body)
+	if err != nil {
+		return mo.Err[view.ValueObject](fmt.Errorf("failed to read request body: %w", err))
+	}
+	return schema.ValidateValues(string(bts), urlParams(ctx)...)
 }
 
 // Bind returns an Echo middleware function that validates incoming JSON request bodies
-// against the provided dvo.Schema schema.
+// against the provided dvo.Schema schema. application/x-www-form-urlencoded and
+// multipart/form-data bodies are validated against the same schema; file parts of a
+// multipart body are left to the handler. A repeated query parameter fills an ArrayField.
 func Bind(schema *view.Schema) echo.MiddlewareFunc {
 	// The returned function is the actual middleware that will be executed for each request.
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		// This is the handler function that Echo will call.
 		return func(c echo.Context) error {
-			// Read the entire request body. The body is read into memory here.
-			// For very large request bodies, a streaming approach might be preferable.
-			btsResult := mo.TupleToResult(io.ReadAll(c.Request().Body))
-			if btsResult.IsError() {
-				// If reading the body fails, return a Bad Request response.
-				return c.JSON(http.StatusBadRequest, map[string]string{"error": "failed to read request body"})
-			}
-			body := string(btsResult.MustGet())
-			// validate the JSON body against the Schema schema.
-			result := schema.Validate(body, urlParams(c))
+			// Validate the form or JSON body together with the path and query parameters.
+			result := validate(c, schema)
 			if result.IsError() {
-				// If validation fails, return a 400 Bad Request with a structured error message
-				// containing the details of the validation failures.
-				return c.JSON(http.StatusBadRequest, map[string]string{
-					"error": result.Error().Error(),
-				})
+				// If validation fails, return a 400 Bad Request with an RFC 7807 problem
+				// document listing the rejected fields, localized per Accept-Language.
+				c.Response().Header().Set(echo.HeaderContentType, view.ProblemContentType)
+				return c.JSON(http.StatusBadRequest, view.NewProblem(result.Error(), c.Request().Header.Get("Accept-Language")))
 			}
 			data := result.MustGet()
 
@@ -0,0 +1,123 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kcmvp/xql/validator"
+)
+
+// Rule codes reported by FieldError.Code for failures that are not raised by
+// a field validator. Validator failures use the validator name instead, e.g.
+// "min_length", "email" or "gt".
+const (
+	CodeRequired         = "required"
+	CodeNull             = "null"
+	CodeTypeMismatch     = "type_mismatch"
+	CodeOverflow         = "overflow"
+	CodeUnknownField     = "unknown_field"
+	CodeDuplicateField   = "duplicate_field"
+	CodeInvalidParameter = "invalid_parameter"
+	CodeInvalid          = "invalid"
+)
+
+// FieldError describes a single rejected value.
+//   - Path is the JSON path of the value, e.g. "items[2].price";
+//   - Code is the failed rule: the validator name or one of the Code* constants;
+//   - Params are the rule parameters, e.g. {"min": 3} for min_length;
+//   - Value is the rejected value, when there is one.
+type FieldError struct {
+	Path    string         `json:"path"`
+	Code    string         `json:"code"`
+	Params  map[string]any `json:"params,omitempty"`
+	Value   any            `json:"value,omitempty"`
+	Message string         `json:"message"`
+	err     error
+}
+
+func (e *FieldError) Error() string { return e.Message }
+
+func (e *FieldError) Unwrap() error { return e.err }
+
+// ValidationErrors is the error returned by Schema.Validate when the input is
+// rejected, sorted by path. Retrieve it with errors.As:
+//
+//	var verrs view.ValidationErrors
+//	if errors.As(err, &verrs) {
+//		for _, fe := range verrs {
+//			log.Println(fe.Path, fe.Code, fe.Params)
+//		}
+//	}
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	if len(e) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("validation failed with the following errors:")
+	for _, fe := range e {
+		b.WriteString(fmt.Sprintf("- %s: %s", fe.Path, fe.Message))
+	}
+	return b.String()
+}
+
+// newFieldError builds the error of a rejected value. code is the name of the
+// failed validator; an empty code is derived from err.
+func newFieldError(code string, value any, message string, err error) *FieldError {
+	fe := &FieldError{Code: code, Value: value, Message: message, err: err}
+	var re *validator.RuleError
+	if errors.As(err, &re) {
+		fe.Params = re.Params
+	}
+	if fe.Code == "" {
+		fe.Code = codeOf(err)
+	}
+	return fe
+}
+
+// codeOf derives the rule code of an error that was not raised by a validator.
+func codeOf(err error) string {
+	switch {
+	case errors.Is(err, validator.ErrRequired):
+		return CodeRequired
+	case errors.Is(err, validator.ErrIntegerOverflow):
+		return CodeOverflow
+	default:
+		return CodeTypeMismatch
+	}
+}
+
+// ProblemContentType is the media type of RFC 7807 problem documents.
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem document. Errors is an extension member
+// listing the rejected fields of a validation failure.
+type Problem struct {
+	Type   string           `json:"type"`
+	Title  string           `json:"title"`
+	Status int              `json:"status"`
+	Detail string           `json:"detail,omitempty"`
+	Errors ValidationErrors `json:"errors,omitempty"`
+}
+
+// NewProblem builds the 400 Bad Request problem document for an error
+// returned by Schema.Validate. Middlewares render it with ProblemContentType.
+// The optional acceptLanguage, an Accept-Language header value, selects the
+// language of the field messages; see ValidationErrors.Localize.
+func NewProblem(err error, acceptLanguage ...string) Problem {
+	p := Problem{Type: "about:blank", Title: http.StatusText(http.StatusBadRequest), Status: http.StatusBadRequest}
+	var verrs ValidationErrors
+	if errors.As(err, &verrs) {
+		p.Detail = "request validation failed"
+		p.Errors = verrs
+		if len(acceptLanguage) > 0 {
+			p.Errors = verrs.Localize(acceptLanguage[0])
+		}
+	} else if err != nil {
+		p.Detail = err.Error()
+	}
+	return p
+}
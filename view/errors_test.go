@@ -0,0 +1,94 @@
+package view
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kcmvp/xql/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrors_As(t *testing.T) {
+	schema := WithFields(
+		Field[string]("name", validator.MinLength(3)),
+		Field[int]("age", validator.Between(1, 120)),
+		Field[string]("email"),
+		ObjectField("user", WithFields(Field[string]("nick", validator.MaxLength(4)))),
+		ArrayOfObjectField("items", WithFields(Field[int]("qty", validator.Gt(0)))),
+		ArrayField[int]("scores", validator.Lte(10)),
+	)
+	rs := schema.Validate(`{"name":"ab","age":"old","user":{"nick":"toolong"},"items":[{"qty":1},{"qty":0},7],"scores":[1,11]}`)
+	require.True(t, rs.IsError())
+
+	var verrs ValidationErrors
+	require.True(t, errors.As(rs.Error(), &verrs))
+	require.Equal(t, rs.Error().Error(), verrs.Error())
+
+	got := map[string]FieldError{}
+	for _, fe := range verrs {
+		got[fe.Path] = fe
+	}
+	tests := []struct {
+		path   string
+		code   string
+		params map[string]any
+		value  any
+	}{
+		{path: "name", code: "min_length", params: map[string]any{"min": 3}, value: "ab"},
+		{path: "age", code: CodeTypeMismatch, value: "old"},
+		{path: "email", code: CodeRequired},
+		{path: "user.nick", code: "max_length", params: map[string]any{"max": 4}, value: "toolong"},
+		{path: "items[1].qty", code: "gt", params: map[string]any{"min": 0}, value: 0},
+		{path: "items[2]", code: CodeTypeMismatch, value: float64(7)},
+		{path: "scores[1]", code: "lte", params: map[string]any{"max": 10}, value: 11},
+	}
+	require.Len(t, verrs, len(tests))
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			fe, ok := got[tc.path]
+			require.True(t, ok, "missing error for %s", tc.path)
+			require.Equal(t, tc.code, fe.Code)
+			require.Equal(t, tc.params, fe.Params)
+			require.Equal(t, tc.value, fe.Value)
+			require.NotEmpty(t, fe.Message)
+		})
+	}
+	// sentinel errors are still reachable through the field errors
+	require.ErrorIs(t, rs.Error(), validator.ErrRequired)
+}
+
+func TestValidationErrors_URL(t *testing.T) {
+	schema := WithFields(Field[string]("name"), ObjectField("user", WithFields(Field[string]("nick"))).Optional())
+	rs := schema.Validate(`{"name":"x"}`, map[string]string{"name": "y", "user": "z", "other": "1"})
+	require.True(t, rs.IsError())
+	var verrs ValidationErrors
+	require.True(t, errors.As(rs.Error(), &verrs))
+	codes := map[string]string{}
+	for _, fe := range verrs {
+		codes[fe.Path] = fe.Code
+	}
+	require.Equal(t, map[string]string{
+		"name":  CodeDuplicateField,
+		"user":  CodeInvalidParameter,
+		"other": CodeUnknownField,
+	}, codes)
+}
+
+func TestNewProblem(t *testing.T) {
+	rs := WithFields(Field[string]("name", validator.MinLength(3))).Validate(`{"name":"ab"}`)
+	p := NewProblem(rs.Error())
+	require.Equal(t, 400, p.Status)
+	require.Equal(t, "about:blank", p.Type)
+	require.Equal(t, "Bad Request", p.Title)
+	require.Len(t, p.Errors, 1)
+
+	data, err := json.Marshal(p)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"request validation failed",
+		"errors":[{"path":"name","code":"min_length","params":{"min":3},"value":"ab","message":"field 'name': length must be at least 3 "}]}`, string(data))
+
+	p = NewProblem(errors.New("invalid json"))
+	require.Equal(t, "invalid json", p.Detail)
+	require.Empty(t, p.Errors)
+}
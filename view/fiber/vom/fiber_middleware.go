@@ -1,12 +1,16 @@
 package vom
 
 import (
+	"mime"
+	"net/url"
 	"sync"
 
 	"github.com/gofiber/fiber/v3"
 	"github.com/kcmvp/xql/internal"
 	"github.com/kcmvp/xql/view"
 	"github.com/samber/lo"
+	"github.com/samber/mo"
+	"github.com/valyala/fasthttp"
 )
 
 // EnrichFunc is a function type that can be used to enrich the validated data
@@ -28,35 +32,55 @@ func SetGlobalEnricher(enrich EnrichFunc) {
 	})
 }
 
-func urlParams(ctx fiber.Ctx) map[string]string {
-	params := lo.Associate(ctx.Route().Params, func(name string) (string, string) {
-		return name, ctx.Params(name)
+// urlParams returns the path parameters and the query parameters of the request.
+// They are validated as separate sources, so a conflicting name is reported as
+// a duplicate field instead of overwriting one another.
+func urlParams(ctx fiber.Ctx) []url.Values {
+	path := lo.Associate(ctx.Route().Params, func(name string) (string, []string) {
+		return name, []string{ctx.Params(name)}
 	})
-	// Iterate over all query parameters and merge them into the params map.
-	// The logic here enforces two rules:
-	// 1. A query parameter key cannot be a duplicate.
-	// 2. A query parameter key cannot conflict with a path parameter key.
-	ctx.Request().URI().QueryArgs().All()(func(key, value []byte) bool {
-		k := string(key)
-		_, ok := params[k]
-		lo.Assertf(!ok, "path and query parameters have a conflicting name or query parameter is duplicated: '%s'", k)
-		params[k] = string(value)
+	return []url.Values{path, values(ctx.Request().URI().QueryArgs())}
+}
+
+// values collects the arguments of a query string or an url encoded form;
+// a repeated key keeps all its values.
+func values(args *fasthttp.Args) url.Values {
+	vs := url.Values{}
+	args.All()(func(key, value []byte) bool {
+		vs.Add(string(key), string(value))
 		return true
 	})
-	return params
+	return vs
+}
+
+// validate validates the form body or the JSON body of the request.
+func validate(c fiber.Ctx, schema *view.Schema) mo.Result[view.ValueObject] {
+	mediaType, _, _ := mime.ParseMediaType(c.Get(fiber.HeaderContentType))
+	switch mediaType {
+	case fiber.MIMEApplicationForm:
+		return schema.ValidateForm(values(c.Request().PostArgs()), urlParams(c)...)
+	case fiber.MIMEMultipartForm:
+		form, err := c.MultipartForm()
+		if err != nil {
+			return mo.Err[view.ValueObject](err)
+		}
+		return schema.ValidateForm(form.Value, urlParams(c)...)
+	}
+	return schema.ValidateValues(string(c.Body()), urlParams(c)...)
 }
 
 // Bind creates a new fiber middleware to bind and validate the schema.
-// It takes a provider function that returns a new Schema for each request.
+// JSON, application/x-www-form-urlencoded and multipart/form-data bodies are
+// supported; file parts of a multipart body are left to the handler. A repeated
+// query parameter fills an ArrayField.
 func Bind(schema *view.Schema) fiber.Handler {
 	return func(c fiber.Ctx) error {
-		// Get a fresh Schema instance for this request.
-		body := string(c.Body())
-		// The validate method is defined in the internal/core package.
-		result := schema.Validate(body, urlParams(c))
-		// The validate method is defined in the internal/core package.
+		// Validate the form or JSON body together with the path and query parameters.
+		result := validate(c, schema)
 		if result.IsError() {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": result.Error().Error()})
+			// Reply with an RFC 7807 problem document listing the rejected fields,
+			// localized per Accept-Language.
+			return c.Status(fiber.StatusBadRequest).JSON(view.NewProblem(result.Error(), c.Get(fiber.HeaderAcceptLanguage)), view.ProblemContentType)
 		}
 		data := result.MustGet()
 		if _enrich != nil {
@@ -1,8 +1,10 @@
 package vom
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -12,7 +14,6 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v3"
-	"github.com/gofiber/fiber/v3/middleware/adaptor"
 	"github.com/kcmvp/xql/validator"
 	"github.com/kcmvp/xql/view"
 	"github.com/stretchr/testify/assert"
@@ -78,6 +79,7 @@ func (suite *MiddlewareTestSuite) TestDynamicVOBinding() {
 		name           string
 		inputFile      string
 		expectedStatus int
+		expectedError  view.FieldError
 	}{
 		{
 			name:           "Valid Order",
@@ -88,11 +90,13 @@ func (suite *MiddlewareTestSuite) TestDynamicVOBinding() {
 			name:           "Invalid Amount (Negative)",
 			inputFile:      "testdata/invalid_amount.json",
 			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "Amount", Code: "gt"},
 		},
 		{
 			name:           "Missing Required Field (CustomerID)",
 			inputFile:      "testdata/missing_customer.json",
 			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "CustomerID", Code: view.CodeRequired},
 		},
 		{
 			name:           "optional (Priority)",
@@ -103,6 +107,7 @@ func (suite *MiddlewareTestSuite) TestDynamicVOBinding() {
 			name:           "optional (Priority) invalid type",
 			inputFile:      "testdata/invalid_order_optional.json",
 			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "Priority", Code: view.CodeTypeMismatch},
 		},
 	}
 
@@ -136,6 +141,16 @@ func (suite *MiddlewareTestSuite) TestDynamicVOBinding() {
 				require.NoError(suite.T(), err)
 				body, _ := io.ReadAll(res.Body)
 				assert.JSONEq(suite.T(), string(expectedPayloadBytes), string(body))
+			} else {
+				// Validation failures are reported as an RFC 7807 problem document.
+				assert.Equal(suite.T(), view.ProblemContentType, res.Header.Get("Content-Type"))
+				body, _ := io.ReadAll(res.Body)
+				var problem view.Problem
+				require.NoError(suite.T(), json.Unmarshal(body, &problem))
+				assert.Equal(suite.T(), http.StatusBadRequest, problem.Status)
+				require.Len(suite.T(), problem.Errors, 1)
+				assert.Equal(suite.T(), tc.expectedError.Path, problem.Errors[0].Path)
+				assert.Equal(suite.T(), tc.expectedError.Code, problem.Errors[0].Code)
 			}
 		})
 	}
@@ -206,36 +221,161 @@ func (suite *MiddlewareTestSuite) TestBindingWithParamsAndEnricher() {
 	}
 }
 
-// TestPanicOnDuplicateQueryParam tests the specific case where the middleware should panic.
-// It is a separate test function to ensure complete isolation from the test suite's shared app instance,
-// which can cause routing issues when using the net/http adaptor.
-func TestPanicOnDuplicateQueryParam(t *testing.T) {
-	// 1. Define the ViewObject needed for this test
+// TestParameterConflicts tests that repeated or conflicting url parameters are
+// rejected with a 400 problem document rather than a panic.
+func (suite *MiddlewareTestSuite) TestParameterConflicts() {
 	vo := view.WithFields(
 		view.Field[string]("ordId"),
 		view.Field[string]("source"),
 	)
+	suite.srv.Post("/conflicting_orders/:ordId", Bind(vo), orderHandler)
 
-	// 2. Create a new, isolated Fiber app and register the route
-	// We disable the default recovery middleware so that we can test for the panic itself.
-	// We provide a custom ErrorHandler that will re-panic, allowing assert.Panics to catch it.
-	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c fiber.Ctx, err error) error {
-			panic(err)
+	testCases := []struct {
+		name          string
+		url           string
+		expectedError view.FieldError
+	}{
+		{
+			name:          "Multiple values for a single value query parameter",
+			url:           "/conflicting_orders/order-fail-case?source=web&source=api",
+			expectedError: view.FieldError{Path: "source", Code: view.CodeDuplicateField},
 		},
-	})
-	app.Post("/enriched_orders/:ordId", Bind(vo), orderHandler)
+		{
+			name:          "Path and query parameters with a conflicting name",
+			url:           "/conflicting_orders/order-fail-case?source=web&ordId=another",
+			expectedError: view.FieldError{Path: "ordId", Code: view.CodeDuplicateField},
+		},
+	}
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			req := httptest.NewRequest(http.MethodPost, tc.url, nil)
+			req.Header.Set("Content-Type", "application/json")
+			res, err := suite.srv.Test(req)
+			require.NoError(suite.T(), err)
+			body, _ := io.ReadAll(res.Body)
+			require.Equalf(suite.T(), http.StatusBadRequest, res.StatusCode, "Response body: %s", string(body))
+			var problem view.Problem
+			require.NoError(suite.T(), json.Unmarshal(body, &problem))
+			require.Len(suite.T(), problem.Errors, 1)
+			assert.Equal(suite.T(), tc.expectedError.Path, problem.Errors[0].Path)
+			assert.Equal(suite.T(), tc.expectedError.Code, problem.Errors[0].Code)
+		})
+	}
+}
 
-	// 3. Create the request that should trigger the panic
-	url := "/enriched_orders/order-fail-case?source=web&source=api"
-	req := httptest.NewRequest(http.MethodPost, url, nil)
+func (suite *MiddlewareTestSuite) TestLocalizedProblem() {
+	suite.srv.Post("/localized_orders", Bind(orderVO), orderHandler)
+	payload, err := os.ReadFile("testdata/invalid_amount.json")
+	require.NoError(suite.T(), err)
+	req, _ := http.NewRequest(http.MethodPost, "/localized_orders", strings.NewReader(string(payload)))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	res, err := suite.srv.Test(req)
+	require.NoError(suite.T(), err)
 
-	// 4. Use the adaptor to make the call synchronous. Since recovery is disabled,
-	// the panic will propagate and can be caught by assert.Panics.
-	handler := adaptor.FiberApp(app)
-	rec := httptest.NewRecorder()
-	assert.Panics(t, func() {
-		handler.ServeHTTP(rec, req)
-	})
+	assert.Equal(suite.T(), http.StatusBadRequest, res.StatusCode)
+	body, _ := io.ReadAll(res.Body)
+	var problem view.Problem
+	require.NoError(suite.T(), json.Unmarshal(body, &problem))
+	require.Len(suite.T(), problem.Errors, 1)
+	assert.Equal(suite.T(), "必须大于 0", problem.Errors[0].Message)
+}
+
+// searchVO is bound from a query string or a form body.
+var searchVO = view.WithFields(
+	view.Field[string]("q"),
+	view.ArrayField[string]("tag").Optional(),
+	view.Field[int]("page").Default(1),
+)
+
+func (suite *MiddlewareTestSuite) TestFormAndRepeatedParameters() {
+	suite.srv.Get("/search", Bind(searchVO), orderHandler)
+	suite.srv.Post("/search", Bind(searchVO), orderHandler)
+
+	multipartBody := func() (string, string) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		require.NoError(suite.T(), w.WriteField("q", "gopher"))
+		require.NoError(suite.T(), w.WriteField("tag", "a"))
+		require.NoError(suite.T(), w.WriteField("tag", "b"))
+		require.NoError(suite.T(), w.Close())
+		return buf.String(), w.FormDataContentType()
+	}
+	multipartPayload, multipartType := multipartBody()
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedError  view.FieldError
+	}{
+		{
+			name:           "repeated query parameter",
+			method:         http.MethodGet,
+			url:            "/search?q=gopher&tag=a&tag=b",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"q":"gopher","tag":["a","b"],"page":1,"traceId":"test-trace-id"}`,
+		},
+		{
+			name:           "url encoded form",
+			method:         http.MethodPost,
+			url:            "/search",
+			contentType:    fiber.MIMEApplicationForm,
+			body:           "q=gopher&tag=a&tag=b&page=2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"q":"gopher","tag":["a","b"],"page":2,"traceId":"test-trace-id"}`,
+		},
+		{
+			name:           "multipart form",
+			method:         http.MethodPost,
+			url:            "/search",
+			contentType:    multipartType,
+			body:           multipartPayload,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"q":"gopher","tag":["a","b"],"page":1,"traceId":"test-trace-id"}`,
+		},
+		{
+			name:           "form and query conflict",
+			method:         http.MethodPost,
+			url:            "/search?q=go",
+			contentType:    fiber.MIMEApplicationForm,
+			body:           "q=gopher",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "q", Code: view.CodeDuplicateField},
+		},
+		{
+			name:           "invalid form value",
+			method:         http.MethodPost,
+			url:            "/search",
+			contentType:    fiber.MIMEApplicationForm,
+			body:           "q=gopher&page=x",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "page", Code: view.CodeTypeMismatch},
+		},
+	}
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			res, err := suite.srv.Test(req)
+			require.NoError(suite.T(), err)
+			body, _ := io.ReadAll(res.Body)
+			require.Equalf(suite.T(), tc.expectedStatus, res.StatusCode, "Response body: %s", string(body))
+			if tc.expectedStatus == http.StatusOK {
+				assert.JSONEq(suite.T(), tc.expectedBody, string(body))
+				return
+			}
+			var problem view.Problem
+			require.NoError(suite.T(), json.Unmarshal(body, &problem))
+			require.Len(suite.T(), problem.Errors, 1)
+			assert.Equal(suite.T(), tc.expectedError.Path, problem.Errors[0].Path)
+			assert.Equal(suite.T(), tc.expectedError.Code, problem.Errors[0].Code)
+		})
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"io"
 	"net/http"
+	"net/url"
 	"sync"
 
 	"github.com/gin-gonic/gin"
@@ -27,35 +28,52 @@ func SetGlobalEnricher(enrich EnrichFunc) {
 	})
 }
 
-func urlParams(ctx *gin.Context) map[string]string {
-	params := lo.Associate(ctx.Params, func(item gin.Param) (string, string) {
-		return item.Key, item.Value
+// urlParams returns the path parameters and the query parameters of the request.
+// They are validated as separate sources, so a conflicting name is reported as
+// a duplicate field instead of overwriting one another.
+func urlParams(ctx *gin.Context) []url.Values {
+	path := lo.Associate(ctx.Params, func(item gin.Param) (string, []string) {
+		return item.Key, []string{item.Value}
 	})
-	for name, values := range ctx.Request.URL.Query() {
-		lo.Assertf(len(values) == 1, "query parameter '%s' has multiple values, which is not supported", name)
-		_, ok := params[name]
-		lo.Assertf(!ok, "path parameter and query parameter have conflicting names: '%s'", name)
-		params[name] = values[0]
+	return []url.Values{path, ctx.Request.URL.Query()}
+}
+
+// validate validates the form body or the JSON body of the request.
+func validate(ctx *gin.Context, schema *view.Schema) mo.Result[view.ValueObject] {
+	switch ctx.ContentType() {
+	case gin.MIMEPOSTForm:
+		if err := ctx.Request.ParseForm(); err != nil {
+			return mo.Err[view.ValueObject](err)
+		}
+		return schema.ValidateForm(ctx.Request.PostForm, urlParams(ctx)...)
+	case gin.MIMEMultipartPOSTForm:
+		if _, err := ctx.MultipartForm(); err != nil {
+			return mo.Err[view.ValueObject](err)
+		}
+		return schema.ValidateForm(ctx.Request.PostForm, urlParams(ctx)...)
 	}
-	return params
+	bts, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return mo.Err[view.ValueObject](err)
+	}
+	return schema.ValidateValues(string(bts), urlParams(ctx)...)
 }
 
 // Bind creates a Gin middleware that validates the request body against a dvo.Schema.
-// If validation is successful, the validated data is stored in the request context.
-// If validation fails, it aborts the request with a 400 Bad Request status and an error message.
+// JSON, application/x-www-form-urlencoded and multipart/form-data bodies are
+// supported; file parts of a multipart body are left to the handler. A repeated
+// query parameter fills an ArrayField. If validation is successful, the validated data is stored in the request context.
+// If validation fails, it aborts the request with a 400 Bad Request status and an
+// RFC 7807 problem document (application/problem+json) listing the rejected fields,
+// with messages in the language of the Accept-Language header when a catalog has one.
 // It also allows for enriching the validated data using a previously set EnrichFunc function.
 func Bind(schema *view.Schema) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// Get a fresh ValueObject instance for this request.
-		bts := mo.TupleToResult[[]byte](io.ReadAll(ctx.Request.Body))
-		if bts.IsError() {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": bts.Error().Error()})
-			return
-		}
-		body := string(bts.MustGet())
-		result := schema.Validate(body, urlParams(ctx))
+		// Validate the form or JSON body together with the path and query parameters.
+		result := validate(ctx, schema)
 		if result.IsError() {
-			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": result.Error().Error()})
+			ctx.Header("Content-Type", view.ProblemContentType)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, view.NewProblem(result.Error(), ctx.GetHeader("Accept-Language")))
 			return
 		}
 		data := result.MustGet()
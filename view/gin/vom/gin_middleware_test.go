@@ -1,7 +1,9 @@
 package vom
 
 import (
+	"bytes"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -78,6 +80,7 @@ func (suite *MiddlewareTestSuite) TestDynamicVOBinding() {
 		name           string
 		inputFile      string
 		expectedStatus int
+		expectedError  view.FieldError
 	}{
 		{
 			name:           "Valid Order",
@@ -88,11 +91,13 @@ func (suite *MiddlewareTestSuite) TestDynamicVOBinding() {
 			name:           "Invalid Amount (Negative)",
 			inputFile:      "testdata/invalid_amount.json",
 			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "Amount", Code: "gt"},
 		},
 		{
 			name:           "Missing Required Field (CustomerID)",
 			inputFile:      "testdata/missing_customer.json",
 			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "CustomerID", Code: view.CodeRequired},
 		},
 		{
 			name:           "optional (Priority)",
@@ -103,6 +108,7 @@ func (suite *MiddlewareTestSuite) TestDynamicVOBinding() {
 			name:           "optional (Priority) invalid type",
 			inputFile:      "testdata/invalid_order_optional.json",
 			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "Priority", Code: view.CodeTypeMismatch},
 		},
 	}
 
@@ -133,6 +139,15 @@ func (suite *MiddlewareTestSuite) TestDynamicVOBinding() {
 				expectedPayloadBytes, err := json.Marshal(expectedMap)
 				require.NoError(suite.T(), err)
 				assert.JSONEq(suite.T(), string(expectedPayloadBytes), rec.Body.String())
+			} else {
+				// Validation failures are reported as an RFC 7807 problem document.
+				assert.Equal(suite.T(), view.ProblemContentType, rec.Header().Get("Content-Type"))
+				var problem view.Problem
+				require.NoError(suite.T(), json.Unmarshal(rec.Body.Bytes(), &problem))
+				assert.Equal(suite.T(), http.StatusBadRequest, problem.Status)
+				require.Len(suite.T(), problem.Errors, 1)
+				assert.Equal(suite.T(), tc.expectedError.Path, problem.Errors[0].Path)
+				assert.Equal(suite.T(), tc.expectedError.Code, problem.Errors[0].Code)
 			}
 		})
 	}
@@ -149,7 +164,7 @@ func (suite *MiddlewareTestSuite) TestBindingWithParamsAndEnricher() {
 		inputFile      string
 		expectedStatus int
 		expectedValues map[string]any // Expected values from URL params to merge into the final JSON
-		shouldPanic    bool
+		expectedError  view.FieldError
 	}{
 		{
 			name:           "Valid request with basic path and query parameters",
@@ -173,10 +188,18 @@ func (suite *MiddlewareTestSuite) TestBindingWithParamsAndEnricher() {
 			},
 		},
 		{
-			name:        "Request with multiple values for a query parameter should panic",
-			url:         "/enriched_orders/order-fail-case?source=web&source=api",
-			inputFile:   "testdata/valid_order_optional.json",
-			shouldPanic: true,
+			name:           "Multiple values for a single value query parameter",
+			url:            "/enriched_orders/order-fail-case?source=web&source=api",
+			inputFile:      "testdata/valid_order_optional.json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "source", Code: view.CodeDuplicateField},
+		},
+		{
+			name:           "Path and query parameters with a conflicting name",
+			url:            "/enriched_orders/order-fail-case?ordId=another",
+			inputFile:      "testdata/valid_order_optional.json",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "ordId", Code: view.CodeDuplicateField},
 		},
 	}
 
@@ -189,16 +212,16 @@ func (suite *MiddlewareTestSuite) TestBindingWithParamsAndEnricher() {
 			req.Header.Set("Content-Type", "application/json")
 			rec := httptest.NewRecorder()
 
-			if tc.shouldPanic {
-				// The default Gin recovery middleware will catch the panic and return a 500 error.
-				// So, we assert the status code instead of the panic itself.
-				suite.srv.ServeHTTP(rec, req)
-				assert.Equal(suite.T(), http.StatusInternalServerError, rec.Code)
-				return
-			}
-
 			suite.srv.ServeHTTP(rec, req)
 			require.Equalf(suite.T(), tc.expectedStatus, rec.Code, rec.Body.String())
+			if tc.expectedStatus == http.StatusBadRequest {
+				var problem view.Problem
+				require.NoError(suite.T(), json.Unmarshal(rec.Body.Bytes(), &problem))
+				require.Len(suite.T(), problem.Errors, 1)
+				assert.Equal(suite.T(), tc.expectedError.Path, problem.Errors[0].Path)
+				assert.Equal(suite.T(), tc.expectedError.Code, problem.Errors[0].Code)
+				return
+			}
 
 			var expectedMap map[string]any
 			err = json.Unmarshal(payloadBytes, &expectedMap)
@@ -216,3 +239,118 @@ func (suite *MiddlewareTestSuite) TestBindingWithParamsAndEnricher() {
 		})
 	}
 }
+
+func (suite *MiddlewareTestSuite) TestLocalizedProblem() {
+	suite.srv.POST("/localized_orders", Bind(orderVO), orderHandler)
+	payload, err := os.ReadFile("testdata/invalid_amount.json")
+	require.NoError(suite.T(), err)
+	req := httptest.NewRequest(http.MethodPost, "/localized_orders", strings.NewReader(string(payload)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+	rec := httptest.NewRecorder()
+	suite.srv.ServeHTTP(rec, req)
+
+	assert.Equal(suite.T(), http.StatusBadRequest, rec.Code)
+	var problem view.Problem
+	require.NoError(suite.T(), json.Unmarshal(rec.Body.Bytes(), &problem))
+	require.Len(suite.T(), problem.Errors, 1)
+	assert.Equal(suite.T(), "必须大于 0", problem.Errors[0].Message)
+}
+
+// searchVO is bound from a query string or a form body.
+var searchVO = view.WithFields(
+	view.Field[string]("q"),
+	view.ArrayField[string]("tag").Optional(),
+	view.Field[int]("page").Default(1),
+)
+
+func (suite *MiddlewareTestSuite) TestFormAndRepeatedParameters() {
+	suite.srv.GET("/search", Bind(searchVO), orderHandler)
+	suite.srv.POST("/search", Bind(searchVO), orderHandler)
+
+	multipartBody := func() (string, string) {
+		var buf bytes.Buffer
+		w := multipart.NewWriter(&buf)
+		require.NoError(suite.T(), w.WriteField("q", "gopher"))
+		require.NoError(suite.T(), w.WriteField("tag", "a"))
+		require.NoError(suite.T(), w.WriteField("tag", "b"))
+		require.NoError(suite.T(), w.Close())
+		return buf.String(), w.FormDataContentType()
+	}
+	multipartPayload, multipartType := multipartBody()
+
+	testCases := []struct {
+		name           string
+		method         string
+		url            string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedError  view.FieldError
+	}{
+		{
+			name:           "repeated query parameter",
+			method:         http.MethodGet,
+			url:            "/search?q=gopher&tag=a&tag=b",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"q":"gopher","tag":["a","b"],"page":1,"traceId":"test-trace-id"}`,
+		},
+		{
+			name:           "url encoded form",
+			method:         http.MethodPost,
+			url:            "/search",
+			contentType:    "application/x-www-form-urlencoded",
+			body:           "q=gopher&tag=a&tag=b&page=2",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"q":"gopher","tag":["a","b"],"page":2,"traceId":"test-trace-id"}`,
+		},
+		{
+			name:           "multipart form",
+			method:         http.MethodPost,
+			url:            "/search",
+			contentType:    multipartType,
+			body:           multipartPayload,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"q":"gopher","tag":["a","b"],"page":1,"traceId":"test-trace-id"}`,
+		},
+		{
+			name:           "form and query conflict",
+			method:         http.MethodPost,
+			url:            "/search?q=go",
+			contentType:    "application/x-www-form-urlencoded",
+			body:           "q=gopher",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "q", Code: view.CodeDuplicateField},
+		},
+		{
+			name:           "invalid form value",
+			method:         http.MethodPost,
+			url:            "/search",
+			contentType:    "application/x-www-form-urlencoded",
+			body:           "q=gopher&page=x",
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  view.FieldError{Path: "page", Code: view.CodeTypeMismatch},
+		},
+	}
+	for _, tc := range testCases {
+		suite.Run(tc.name, func() {
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			rec := httptest.NewRecorder()
+			suite.srv.ServeHTTP(rec, req)
+			require.Equalf(suite.T(), tc.expectedStatus, rec.Code, rec.Body.String())
+			if tc.expectedStatus == http.StatusOK {
+				assert.JSONEq(suite.T(), tc.expectedBody, rec.Body.String())
+				return
+			}
+			var problem view.Problem
+			require.NoError(suite.T(), json.Unmarshal(rec.Body.Bytes(), &problem))
+			require.Len(suite.T(), problem.Errors, 1)
+			assert.Equal(suite.T(), tc.expectedError.Path, problem.Errors[0].Path)
+			assert.Equal(suite.T(), tc.expectedError.Code, problem.Errors[0].Code)
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package view
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/kcmvp/xql/app"
+	"golang.org/x/text/language"
+)
+
+// Catalog maps rule codes, e.g. "min_length" or CodeRequired, to message
+// templates. A template refers to the rule parameters by name in braces:
+// "must be at least {min} characters long". {path} and {value} stand for the
+// path and the rejected value of the field.
+type Catalog map[string]string
+
+// Translator returns the message of fe in the language lang, a lower case
+// BCP 47 tag such as "en" or "zh-cn". It reports false when it has no message
+// for the pair, and the next preferred language is tried.
+type Translator func(lang string, fe FieldError) (string, bool)
+
+var (
+	catalogMu   sync.RWMutex
+	catalogOnce sync.Once
+	translator  Translator = catalogMessage
+	catalogs               = map[string]Catalog{
+		"en": enCatalog,
+		"zh": zhCatalog,
+	}
+	placeholder = regexp.MustCompile(`\{(\w+)\}`)
+)
+
+// enCatalog is the built-in English catalog.
+var enCatalog = Catalog{
+	CodeRequired:         "is required",
+	CodeNull:             "must not be null",
+	CodeTypeMismatch:     "has an invalid type",
+	CodeOverflow:         "is out of range",
+	CodeUnknownField:     "is not a known field",
+	CodeDuplicateField:   "is given more than once",
+	CodeInvalidParameter: "can not be given as a url parameter",
+	"min_length":         "must be at least {min} characters long",
+	"max_length":         "must be at most {max} characters long",
+	"exact_length":       "must be exactly {length} characters long",
+	"length_between":     "must be between {min} and {max} characters long",
+	"only_contains":      "can only contain {charsets}",
+	"contains_any":       "must contain at least one of {charsets}",
+	"contains_all":       "must contain {charset}",
+	"not_contains":       "must not contain {charset}",
+	"match":              "must match the pattern {pattern}",
+	"pattern":            "must match the regular expression {pattern}",
+	"email":              "must be a valid email address",
+	"url":                "must be a valid url",
+	"one_of":             "must be one of {allowed}",
+	"gt":                 "must be greater than {min}",
+	"gte":                "must be greater than or equal to {min}",
+	"lt":                 "must be less than {max}",
+	"lte":                "must be less than or equal to {max}",
+	"between":            "must be between {min} and {max}",
+	"be_true":            "must be true",
+	"be_false":           "must be false",
+	"required_if":        "is required when {field} is {value}",
+	"required_with":      "is required with {fields}",
+	"mutually_exclusive": "only one of {fields} may be given",
+	"at_least_one_of":    "one of {fields} is required",
+	"field_gt":           "must be greater than {field}",
+}
+
+// zhCatalog is the built-in Simplified Chinese catalog.
+var zhCatalog = Catalog{
+	CodeRequired:         "为必填项",
+	CodeNull:             "不能为 null",
+	CodeTypeMismatch:     "类型不正确",
+	CodeOverflow:         "超出取值范围",
+	CodeUnknownField:     "不是已知字段",
+	CodeDuplicateField:   "重复出现",
+	CodeInvalidParameter: "不能作为 url 参数",
+	"min_length":         "长度不能少于 {min} 个字符",
+	"max_length":         "长度不能超过 {max} 个字符",
+	"exact_length":       "长度必须为 {length} 个字符",
+	"length_between":     "长度必须在 {min} 到 {max} 个字符之间",
+	"only_contains":      "只能包含 {charsets}",
+	"contains_any":       "必须至少包含 {charsets} 中的一种",
+	"contains_all":       "必须包含 {charset}",
+	"not_contains":       "不能包含 {charset}",
+	"match":              "必须匹配模式 {pattern}",
+	"pattern":            "必须匹配正则表达式 {pattern}",
+	"email":              "必须是有效的邮箱地址",
+	"url":                "必须是有效的 url",
+	"one_of":             "必须是 {allowed} 之一",
+	"gt":                 "必须大于 {min}",
+	"gte":                "必须大于或等于 {min}",
+	"lt":                 "必须小于 {max}",
+	"lte":                "必须小于或等于 {max}",
+	"between":            "必须在 {min} 到 {max} 之间",
+	"be_true":            "必须为 true",
+	"be_false":           "必须为 false",
+	"required_if":        "当 {field} 为 {value} 时为必填项",
+	"required_with":      "与 {fields} 同时出现时为必填项",
+	"mutually_exclusive": "{fields} 只能填写一项",
+	"at_least_one_of":    "{fields} 至少需要填写一项",
+	"field_gt":           "必须大于 {field}",
+}
+
+// RegisterCatalog adds the messages of c to the catalog of lang, replacing
+// the messages of the same rule codes. It is typically called at startup to
+// add a locale or to reword the built-in messages.
+//
+// Catalogs can also be declared in the application configuration under the
+// `i18n` key; they are loaded on first use, before any registered catalog:
+//
+//	i18n:
+//	  fr:
+//	    required: "est obligatoire"
+//	    min_length: "doit contenir au moins {min} caractères"
+func RegisterCatalog(lang string, c Catalog) {
+	loadCatalogs()
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	mergeCatalog(strings.ToLower(lang), c)
+}
+
+// SetTranslator replaces the catalog lookup, e.g. to delegate to an existing
+// translation service. Passing nil restores the catalog lookup.
+func SetTranslator(t Translator) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	translator = t
+	if t == nil {
+		translator = catalogMessage
+	}
+}
+
+// Localize returns a copy of e whose messages are translated into the first
+// language of acceptLanguage, an Accept-Language header value such as
+// "fr-CH, fr;q=0.9, en;q=0.8", that has a message for the rule. A region tag
+// falls back to its base language. Messages without a translation are kept.
+func (e ValidationErrors) Localize(acceptLanguage string) ValidationErrors {
+	langs := preferredLanguages(acceptLanguage)
+	if len(langs) == 0 {
+		return e
+	}
+	loadCatalogs()
+	catalogMu.RLock()
+	t := translator
+	catalogMu.RUnlock()
+	out := make(ValidationErrors, len(e))
+	for i, fe := range e {
+		out[i] = fe
+		for _, lang := range langs {
+			if msg, ok := t(lang, fe); ok {
+				out[i].Message = msg
+				break
+			}
+		}
+	}
+	return out
+}
+
+// catalogMessage is the default Translator, backed by the registered catalogs.
+func catalogMessage(lang string, fe FieldError) (string, bool) {
+	catalogMu.RLock()
+	tmpl, ok := catalogs[lang][fe.Code]
+	catalogMu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	return placeholder.ReplaceAllStringFunc(tmpl, func(m string) string {
+		name := m[1 : len(m)-1]
+		switch name {
+		case "path":
+			return fe.Path
+		case "value":
+			return formatParam(fe.Value)
+		}
+		if v, ok := fe.Params[name]; ok {
+			return formatParam(v)
+		}
+		return m
+	}), true
+}
+
+// formatParam renders a rule parameter; lists are joined with commas.
+func formatParam(v any) string {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		items := make([]string, rv.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprint(v)
+}
+
+// preferredLanguages parses an Accept-Language header into lower case tags by
+// descending preference; each region tag is followed by its base language.
+func preferredLanguages(acceptLanguage string) []string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return nil
+	}
+	var langs []string
+	seen := map[string]struct{}{}
+	add := func(lang string) {
+		if _, ok := seen[lang]; !ok {
+			seen[lang] = struct{}{}
+			langs = append(langs, lang)
+		}
+	}
+	for _, tag := range tags {
+		add(strings.ToLower(tag.String()))
+		if base, conf := tag.Base(); conf != language.No {
+			add(base.String())
+		}
+	}
+	return langs
+}
+
+// loadCatalogs merges the catalogs declared under the `i18n` configuration key
+// once. A missing configuration is not an error.
+func loadCatalogs() {
+	catalogOnce.Do(func() {
+		res := app.Config()
+		if res.IsError() {
+			return
+		}
+		catalogMu.Lock()
+		defer catalogMu.Unlock()
+		for lang, raw := range res.MustGet().GetStringMap("i18n") {
+			messages, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			c := make(Catalog, len(messages))
+			for code, msg := range messages {
+				c[code] = fmt.Sprint(msg)
+			}
+			mergeCatalog(strings.ToLower(lang), c)
+		}
+	})
+}
+
+// mergeCatalog copies c into the catalog of lang. Callers hold catalogMu.
+func mergeCatalog(lang string, c Catalog) {
+	merged := Catalog{}
+	for code, msg := range catalogs[lang] {
+		merged[code] = msg
+	}
+	for code, msg := range c {
+		merged[code] = msg
+	}
+	catalogs[lang] = merged
+}
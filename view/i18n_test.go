@@ -0,0 +1,111 @@
+package view
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kcmvp/xql/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrors_Localize(t *testing.T) {
+	schema := WithFields(
+		Field[string]("name", validator.MinLength(3)),
+		Field[string]("email"),
+		Field[string]("role", validator.OneOf("admin", "user")),
+	)
+	rs := schema.Validate(`{"name":"ab","role":"root"}`)
+	var verrs ValidationErrors
+	require.True(t, errors.As(rs.Error(), &verrs))
+
+	messages := func(errs ValidationErrors) map[string]string {
+		m := map[string]string{}
+		for _, fe := range errs {
+			m[fe.Path] = fe.Message
+		}
+		return m
+	}
+	tests := []struct {
+		name   string
+		header string
+		want   map[string]string
+	}{
+		{
+			name:   "English",
+			header: "en-US,en;q=0.9",
+			want: map[string]string{
+				"email": "is required",
+				"name":  "must be at least 3 characters long",
+				"role":  "must be one of admin, user",
+			},
+		},
+		{
+			name:   "Chinese",
+			header: "zh-CN",
+			want: map[string]string{
+				"email": "为必填项",
+				"name":  "长度不能少于 3 个字符",
+				"role":  "必须是 admin, user 之一",
+			},
+		},
+		{
+			// fr comes from application_test.yaml and has no one_of message,
+			// so the next preferred language is used.
+			name:   "ConfigCatalog",
+			header: "fr-CH, fr;q=0.9, zh;q=0.5",
+			want: map[string]string{
+				"email": "est obligatoire",
+				"name":  "doit contenir au moins 3 caractères",
+				"role":  "必须是 admin, user 之一",
+			},
+		},
+		{
+			name:   "Unsupported",
+			header: "de",
+			want:   messages(verrs),
+		},
+		{
+			name:   "Empty",
+			header: "",
+			want:   messages(verrs),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.want, messages(verrs.Localize(tc.header)))
+		})
+	}
+	// Localize returns a copy
+	require.Equal(t, "field 'name': length must be at least 3 ", verrs[1].Message)
+}
+
+func TestRegisterCatalog(t *testing.T) {
+	RegisterCatalog("es", Catalog{CodeRequired: "{path} es obligatorio"})
+	RegisterCatalog("ES", Catalog{"min_length": "mínimo {min}"})
+	verrs := ValidationErrors{
+		{Path: "email", Code: CodeRequired},
+		{Path: "name", Code: "min_length", Params: map[string]any{"min": 3}},
+	}
+	got := verrs.Localize("es")
+	require.Equal(t, "email es obligatorio", got[0].Message)
+	require.Equal(t, "mínimo 3", got[1].Message)
+}
+
+func TestSetTranslator(t *testing.T) {
+	t.Cleanup(func() { SetTranslator(nil) })
+	SetTranslator(func(lang string, fe FieldError) (string, bool) {
+		return lang + ":" + fe.Code, lang == "it"
+	})
+	verrs := ValidationErrors{{Path: "email", Code: CodeRequired, Message: "email is required but not found"}}
+	require.Equal(t, "it:required", verrs.Localize("it-IT")[0].Message)
+	require.Equal(t, "email is required but not found", verrs.Localize("en")[0].Message)
+	SetTranslator(nil)
+	require.Equal(t, "is required", verrs.Localize("en")[0].Message)
+}
+
+func TestNewProblem_Localized(t *testing.T) {
+	rs := WithFields(Field[string]("name")).Validate(`{}`)
+	p := NewProblem(rs.Error(), "zh")
+	require.Len(t, p.Errors, 1)
+	require.Equal(t, "为必填项", p.Errors[0].Message)
+}
@@ -0,0 +1,676 @@
+package view
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kcmvp/xql/decimal"
+	"github.com/kcmvp/xql/validator"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchemaDialect is the JSON Schema draft produced by Schema.JSONSchema.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema exports the schema as a JSON Schema (draft 2020-12) document, so
+// clients and gateways can enforce the same contract:
+//   - fields become properties; required fields are listed in "required";
+//   - ObjectField and ArrayOfObjectField nest object schemas, MapField is an
+//     object with the value schema as "additionalProperties";
+//   - decimals, uuids, durations and byte slices are strings, with the format
+//     decimal or uuid, or the contentEncoding base64; enums take the type
+//     they are defined over;
+//   - "additionalProperties" is false unless AllowUnknownFields is set;
+//   - Nullable fields have the type list [<type>, "null"] and defaults are
+//     exported as "default";
+//   - validators are translated into keywords: min_length/max_length
+//     (minLength/maxLength), gt/gte/lt/lte/between (exclusiveMinimum,
+//     minimum, exclusiveMaximum, maximum), one_of (enum), email and url
+//     (format), match and pattern (pattern) and be_true/be_false (const).
+//
+// Validators without a JSON Schema equivalent, such as custom validators,
+// comparisons of times and object-level rules, are left out; the server still
+// enforces them.
+func (s *Schema) JSONSchema() ([]byte, error) {
+	doc := s.jsonSchema()
+	doc["$schema"] = JSONSchemaDialect
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+func (s *Schema) jsonSchema() map[string]any {
+	properties := make(map[string]any, len(s.fields))
+	required := make([]string, 0, len(s.fields))
+	for _, f := range s.fields {
+		properties[f.Name()] = f.jsonSchema()
+		if f.Required() {
+			required = append(required, f.Name())
+		}
+	}
+	doc := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": s.allowUnknownFields,
+	}
+	if len(required) > 0 {
+		doc["required"] = required
+	}
+	return doc
+}
+
+func (f *JSONField[T]) jsonSchema() map[string]any {
+	var item map[string]any
+	if f.embedded != nil {
+		item = f.embedded.jsonSchema()
+	} else {
+		item = jsonType[T]()
+		for _, r := range f.rules {
+			addKeywords(item, r)
+		}
+	}
+	if v, ok := f.defaultValue.Get(); ok {
+		item["default"] = v
+		if d, isDuration := any(v).(time.Duration); isDuration {
+			item["default"] = d.String()
+		}
+	}
+	if f.IsArray() {
+		item = map[string]any{"type": "array", "items": item}
+	} else if f.IsMap() {
+		item = map[string]any{"type": "object", "additionalProperties": item}
+	}
+	if f.nullable {
+		item["type"] = []string{item["type"].(string), "null"}
+	}
+	return item
+}
+
+// jsonType returns the JSON Schema type of T.
+func jsonType[T validator.FieldType]() map[string]any {
+	switch any(*new(T)).(type) {
+	case time.Time:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case decimal.Decimal:
+		return map[string]any{"type": "string", "format": "decimal"}
+	case uuid.UUID:
+		return map[string]any{"type": "string", "format": "uuid"}
+	case time.Duration:
+		return map[string]any{"type": "string"}
+	case []byte:
+		return map[string]any{"type": "string", "contentEncoding": "base64"}
+	}
+	// kinds rather than types, so enums get the type they are defined over
+	switch reflect.TypeFor[T]().Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{"type": "integer"}
+	}
+}
+
+// addKeywords translates a validator into JSON Schema keywords.
+func addKeywords(schema map[string]any, r validator.Rule) {
+	bound := func(keyword, param string) {
+		switch v := r.Params[param].(type) {
+		case nil, time.Time, time.Duration:
+		case decimal.Decimal:
+			schema[keyword] = json.Number(v.String())
+		default:
+			schema[keyword] = v
+		}
+	}
+	switch r.Name {
+	case "min_length":
+		schema["minLength"] = r.Params["min"]
+	case "max_length":
+		schema["maxLength"] = r.Params["max"]
+	case "exact_length":
+		schema["minLength"] = r.Params["length"]
+		schema["maxLength"] = r.Params["length"]
+	case "length_between":
+		schema["minLength"] = r.Params["min"]
+		schema["maxLength"] = r.Params["max"]
+	case "gt":
+		bound("exclusiveMinimum", "min")
+	case "gte":
+		bound("minimum", "min")
+	case "lt":
+		bound("exclusiveMaximum", "max")
+	case "lte":
+		bound("maximum", "max")
+	case "between":
+		bound("minimum", "min")
+		bound("maximum", "max")
+	case "one_of":
+		// durations are strings in JSON
+		if allowed, ok := r.Params["allowed"].([]time.Duration); ok {
+			schema["enum"] = lo.Map(allowed, func(d time.Duration, _ int) string { return d.String() })
+		} else {
+			schema["enum"] = r.Params["allowed"]
+		}
+	case "email":
+		schema["format"] = "email"
+	case "url":
+		schema["format"] = "uri"
+	case "match":
+		schema["pattern"] = wildcardPattern(r.Params["pattern"].(string))
+	case "pattern":
+		schema["pattern"] = r.Params["pattern"]
+	case "be_true":
+		schema["const"] = true
+	case "be_false":
+		schema["const"] = false
+	}
+}
+
+// wildcardPattern converts a validator.Match pattern into an anchored regular
+// expression: `*` matches any sequence and `?` a single character.
+func wildcardPattern(pattern string) string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// FromJSONSchema builds a Schema from a JSON Schema document, so contracts
+// owned elsewhere can be loaded at runtime instead of compiled in. The root
+// must be an object schema. Properties map to fields:
+//   - string, integer, number and boolean become Field[string], Field[int64],
+//     Field[float64] and Field[bool]; strings of format date-time or date,
+//     uuid and decimal, or of contentEncoding base64, become Field[time.Time],
+//     Field[uuid.UUID], Field[decimal.Decimal] and Field[[]byte];
+//   - object properties become ObjectField, or MapField when they only have
+//     an "additionalProperties" schema; arrays become ArrayField or
+//     ArrayOfObjectField depending on "items";
+//   - properties not listed in "required" are Optional, properties of type
+//     [<type>, "null"] are Nullable, and
+//     "additionalProperties" other than false allows unknown fields, as in
+//     JSON Schema.
+//
+// Validation keywords map to validators: minLength, maxLength, pattern,
+// minimum, maximum, exclusiveMinimum, exclusiveMaximum, enum, const (of
+// booleans) and format (email, uri, date-time, date). The default of an
+// optional property becomes the field Default. Annotations such as title or
+// description are ignored; any other keyword, e.g. $ref or oneOf, is
+// rejected rather than silently weakening the contract.
+func FromJSONSchema(r io.Reader) (*Schema, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	return objectOf("", doc)
+}
+
+// FromYAML is FromJSONSchema for a JSON Schema written in YAML.
+func FromYAML(r io.Reader) (*Schema, error) {
+	var doc any
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	// Round trip through JSON to get the value types of FromJSONSchema.
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("json schema: %w", err)
+	}
+	return FromJSONSchema(bytes.NewReader(data))
+}
+
+// annotations are keywords without effect on validation.
+var annotations = []string{"$schema", "$id", "$comment", "title", "description", "default", "examples", "deprecated", "readOnly", "writeOnly"}
+
+// keywordsOf lists the keywords supported for each JSON type.
+var keywordsOf = map[string][]string{
+	"object":  {"type", "properties", "required", "additionalProperties"},
+	"array":   {"type", "items"},
+	"string":  {"type", "minLength", "maxLength", "pattern", "format", "contentEncoding", "enum"},
+	"integer": {"type", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "enum"},
+	"number":  {"type", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum", "enum"},
+	"boolean": {"type", "const", "enum"},
+}
+
+// objectOf builds the Schema of an object schema node.
+func objectOf(path string, node map[string]any) (*Schema, error) {
+	if err := checkKeywords(path, node, "object"); err != nil {
+		return nil, err
+	}
+	properties, ok := node["properties"].(map[string]any)
+	if !ok && node["properties"] != nil {
+		return nil, schemaError(path, "properties must be an object")
+	}
+	required := map[string]bool{}
+	if list, ok := node["required"].([]any); ok {
+		for _, name := range list {
+			required[fmt.Sprint(name)] = true
+		}
+	}
+	// JSON objects have no order; sort the properties for a stable schema.
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	providers := make([]FieldProvider, 0, len(names))
+	for _, name := range names {
+		prop, ok := properties[name].(map[string]any)
+		if strings.ContainsAny(name, ".#") {
+			return nil, schemaError(join(path, name), "property names cannot contain '.' or '#'")
+		} else if !ok {
+			return nil, schemaError(join(path, name), "must be a schema object")
+		}
+		f, err := fieldOf(name, join(path, name), prop, required[name])
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, f)
+	}
+	for name := range required {
+		if _, ok := properties[name]; !ok {
+			return nil, schemaError(path, fmt.Sprintf("required property '%s' is not defined", name))
+		}
+	}
+	s := WithFields(providers...)
+	switch additional := node["additionalProperties"].(type) {
+	case nil:
+		s.allowUnknownFields = true
+	case bool:
+		s.allowUnknownFields = additional
+	default:
+		return nil, schemaError(path, "only boolean additionalProperties are supported")
+	}
+	return s, nil
+}
+
+// fieldOf builds the field of a property schema.
+func fieldOf(name, path string, node map[string]any, required bool) (FieldProvider, error) {
+	node, nullable := nullableOf(node)
+	p := presence{required: required, nullable: nullable}
+	typ, _ := node["type"].(string)
+	switch typ {
+	case "object":
+		// an object without properties whose values share a schema is a map
+		if values, ok := node["additionalProperties"].(map[string]any); ok && node["properties"] == nil && node["required"] == nil {
+			if err := checkKeywords(path, node, "object"); err != nil {
+				return nil, err
+			}
+			return primitiveOf(name, path+".*", values, mapOf, p)
+		}
+		nested, err := objectOf(path, node)
+		if err != nil {
+			return nil, err
+		}
+		return withPresence(ObjectField(name, nested), p), nil
+	case "array":
+		if err := checkKeywords(path, node, "array"); err != nil {
+			return nil, err
+		}
+		items, ok := node["items"].(map[string]any)
+		if !ok {
+			return nil, schemaError(path, "arrays need an items schema")
+		}
+		if items["type"] == "object" {
+			nested, err := objectOf(path+"[]", items)
+			if err != nil {
+				return nil, err
+			}
+			return withPresence(ArrayOfObjectField(name, nested), p), nil
+		}
+		return primitiveOf(name, path+"[]", items, arrayOf, p)
+	case "":
+		for k := range node {
+			if !slices.Contains(annotations, k) && !slices.ContainsFunc(lo.Values(keywordsOf), func(kws []string) bool { return slices.Contains(kws, k) }) {
+				return nil, schemaError(path, fmt.Sprintf("unsupported keyword '%s'", k))
+			}
+		}
+		return nil, schemaError(path, "a single type is required")
+	default:
+		return primitiveOf(name, path, node, single, p)
+	}
+}
+
+// shape tells whether a primitive property holds a single value, an array or a map of values.
+type shape int
+
+const (
+	single shape = iota
+	arrayOf
+	mapOf
+)
+
+func primitiveOf(name, path string, node map[string]any, sh shape, p presence) (FieldProvider, error) {
+	typ, _ := node["type"].(string)
+	if _, ok := keywordsOf[typ]; !ok || typ == "object" || typ == "array" {
+		return nil, schemaError(path, fmt.Sprintf("unsupported type %v", node["type"]))
+	}
+	if err := checkKeywords(path, node, typ); err != nil {
+		return nil, err
+	}
+	switch typ {
+	case "string":
+		if kind := stringKind(node); kind != "" {
+			for _, k := range []string{"minLength", "maxLength", "pattern", "enum"} {
+				if _, ok := node[k]; ok {
+					return nil, schemaError(path, fmt.Sprintf("'%s' does not apply to %s strings", k, kind))
+				}
+			}
+			switch kind {
+			case "base64":
+				return newField[[]byte](name, path, node, sh, p, nil)
+			case "uuid":
+				return newField[uuid.UUID](name, path, node, sh, p, nil)
+			case "decimal":
+				return newField[decimal.Decimal](name, path, node, sh, p, nil)
+			default:
+				return newField[time.Time](name, path, node, sh, p, nil)
+			}
+		}
+		vfs, err := stringValidators(path, node)
+		if err != nil {
+			return nil, err
+		}
+		return newField(name, path, node, sh, p, vfs)
+	case "integer":
+		vfs, err := numberValidators[int64](path, node)
+		if err != nil {
+			return nil, err
+		}
+		return newField(name, path, node, sh, p, vfs)
+	case "number":
+		vfs, err := numberValidators[float64](path, node)
+		if err != nil {
+			return nil, err
+		}
+		return newField(name, path, node, sh, p, vfs)
+	default:
+		vfs, err := boolValidators(path, node)
+		if err != nil {
+			return nil, err
+		}
+		return newField(name, path, node, sh, p, vfs)
+	}
+}
+
+// newField builds a primitive field. The "default" of an optional single
+// value property becomes the field default; on a required property it has no
+// effect and stays an annotation.
+func newField[T validator.FieldType](name, path string, node map[string]any, sh shape, p presence, vfs []validator.ValidateFunc[T]) (FieldProvider, error) {
+	f := withPresence(trait(name, sh == arrayOf, false, nil, vfs...), p)
+	f.mapped = sh == mapOf
+	v, ok := node["default"]
+	if !ok || sh != single || p.required || v == nil {
+		return f, nil
+	}
+	def, err := defaultOf[T](path, v)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = f.check(def); err != nil {
+		return nil, schemaError(path, fmt.Sprintf("default %v is invalid: %v", v, err))
+	}
+	return f.Default(def), nil
+}
+
+// stringKind tells the Go type of a string property with a format or a
+// content encoding: "date-time", "uuid", "decimal" or "base64"; it is empty for
+// plain strings.
+func stringKind(node map[string]any) string {
+	if node["contentEncoding"] == "base64" {
+		return "base64"
+	}
+	switch format := node["format"]; format {
+	case "date-time", "date":
+		return "date-time"
+	case "uuid", "decimal":
+		return format.(string)
+	}
+	return ""
+}
+
+// defaultOf converts the JSON value v into T.
+func defaultOf[T validator.FieldType](path string, v any) (T, error) {
+	var zero T
+	val, err := v, error(nil)
+	switch any(zero).(type) {
+	case int64:
+		val, err = numberOf[int64](path, v)
+	case float64:
+		val, err = numberOf[float64](path, v)
+	case string, bool:
+	default:
+		// times, decimals, uuids and byte slices are strings in JSON
+		if str, ok := v.(string); ok {
+			if val, err = validator.ParseStringTo[T](str); err != nil {
+				err = schemaError(path, fmt.Sprintf("default %v is invalid: %v", v, err))
+			}
+		}
+	}
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := val.(T)
+	if !ok {
+		return zero, schemaError(path, fmt.Sprintf("default %v is not a %T", v, zero))
+	}
+	return typed, nil
+}
+
+// presence tells whether a property must be given and whether it may be null.
+type presence struct {
+	required bool
+	nullable bool
+}
+
+func withPresence[T validator.FieldType](f *JSONField[T], p presence) *JSONField[T] {
+	if !p.required {
+		f.Optional()
+	}
+	if p.nullable {
+		f.Nullable()
+	}
+	return f
+}
+
+// nullableOf turns the type list [<type>, "null"] of a nullable property into
+// <type>. Other type lists are left for fieldOf to reject.
+func nullableOf(node map[string]any) (map[string]any, bool) {
+	types, ok := node["type"].([]any)
+	if !ok || len(types) != 2 || !slices.Contains(types, "null") {
+		return node, false
+	}
+	typ := lo.Ternary(types[0] == "null", types[1], types[0])
+	if _, isString := typ.(string); !isString || typ == "null" {
+		return node, false
+	}
+	single := maps.Clone(node)
+	single["type"] = typ
+	return single, true
+}
+
+func stringValidators(path string, node map[string]any) ([]validator.ValidateFunc[string], error) {
+	var vfs []validator.ValidateFunc[string]
+	if n, ok, err := intKeyword(path, node, "minLength"); err != nil {
+		return nil, err
+	} else if ok {
+		vfs = append(vfs, validator.MinLength(n))
+	}
+	if n, ok, err := intKeyword(path, node, "maxLength"); err != nil {
+		return nil, err
+	} else if ok {
+		vfs = append(vfs, validator.MaxLength(n))
+	}
+	if p, ok := node["pattern"]; ok {
+		expr, isString := p.(string)
+		if _, err := regexp.Compile(expr); !isString || err != nil {
+			return nil, schemaError(path, fmt.Sprintf("invalid pattern %v", p))
+		}
+		vfs = append(vfs, validator.Pattern(expr))
+	}
+	if encoding, ok := node["contentEncoding"]; ok {
+		return nil, schemaError(path, fmt.Sprintf("unsupported contentEncoding %v", encoding))
+	}
+	switch format := node["format"]; format {
+	case nil:
+	case "email":
+		vfs = append(vfs, validator.Email())
+	case "uri":
+		vfs = append(vfs, validator.URL())
+	default:
+		return nil, schemaError(path, fmt.Sprintf("unsupported format %v", format))
+	}
+	if values, ok := node["enum"].([]any); ok {
+		allowed := make([]string, len(values))
+		for i, v := range values {
+			s, isString := v.(string)
+			if !isString {
+				return nil, schemaError(path, fmt.Sprintf("enum value %v is not a string", v))
+			}
+			allowed[i] = s
+		}
+		vfs = append(vfs, validator.OneOf(allowed...))
+	}
+	return vfs, nil
+}
+
+func numberValidators[T int64 | float64](path string, node map[string]any) ([]validator.ValidateFunc[T], error) {
+	var vfs []validator.ValidateFunc[T]
+	bounds := []struct {
+		keyword string
+		vf      func(T) validator.ValidateFunc[T]
+	}{
+		{"minimum", validator.Gte[T]},
+		{"maximum", validator.Lte[T]},
+		{"exclusiveMinimum", validator.Gt[T]},
+		{"exclusiveMaximum", validator.Lt[T]},
+	}
+	for _, b := range bounds {
+		if v, ok := node[b.keyword]; ok {
+			n, err := numberOf[T](path, v)
+			if err != nil {
+				return nil, err
+			}
+			vfs = append(vfs, b.vf(n))
+		}
+	}
+	if values, ok := node["enum"].([]any); ok {
+		allowed := make([]T, len(values))
+		for i, v := range values {
+			n, err := numberOf[T](path, v)
+			if err != nil {
+				return nil, err
+			}
+			allowed[i] = n
+		}
+		vfs = append(vfs, validator.OneOf(allowed...))
+	}
+	return vfs, nil
+}
+
+func boolValidators(path string, node map[string]any) ([]validator.ValidateFunc[bool], error) {
+	var vfs []validator.ValidateFunc[bool]
+	switch c := node["const"]; c {
+	case nil:
+	case true:
+		vfs = append(vfs, validator.BeTrue())
+	case false:
+		vfs = append(vfs, validator.BeFalse())
+	default:
+		return nil, schemaError(path, fmt.Sprintf("const %v is not a boolean", c))
+	}
+	if values, ok := node["enum"].([]any); ok {
+		allowed := make([]bool, len(values))
+		for i, v := range values {
+			b, isBool := v.(bool)
+			if !isBool {
+				return nil, schemaError(path, fmt.Sprintf("enum value %v is not a boolean", v))
+			}
+			allowed[i] = b
+		}
+		vfs = append(vfs, validator.OneOf(allowed...))
+	}
+	return vfs, nil
+}
+
+// numberOf converts a JSON number into T, rejecting fractions for integers.
+func numberOf[T int64 | float64](path string, v any) (T, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, schemaError(path, fmt.Sprintf("%v is not a number", v))
+	}
+	var zero T
+	if _, isInt := any(zero).(int64); isInt {
+		i, err := n.Int64()
+		if err != nil {
+			return 0, schemaError(path, fmt.Sprintf("%v is not an integer", v))
+		}
+		return T(i), nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return 0, schemaError(path, fmt.Sprintf("%v is not a number", v))
+	}
+	return T(f), nil
+}
+
+func intKeyword(path string, node map[string]any, keyword string) (int, bool, error) {
+	v, ok := node[keyword]
+	if !ok {
+		return 0, false, nil
+	}
+	n, err := numberOf[int64](path, v)
+	if err != nil || n < 0 {
+		return 0, false, schemaError(path, fmt.Sprintf("%s must be a non-negative integer", keyword))
+	}
+	return int(n), true, nil
+}
+
+// checkKeywords rejects the keywords that are not supported for typ.
+func checkKeywords(path string, node map[string]any, typ string) error {
+	if t, ok := node["type"]; ok && t != typ {
+		return schemaError(path, fmt.Sprintf("expected type %s but got %v", typ, t))
+	}
+	if path == "" && node["type"] == nil {
+		return schemaError(path, "the root must be an object schema")
+	}
+	for k := range node {
+		if !slices.Contains(keywordsOf[typ], k) && !slices.Contains(annotations, k) {
+			return schemaError(path, fmt.Sprintf("unsupported keyword '%s'", k))
+		}
+	}
+	return nil
+}
+
+func schemaError(path, msg string) error {
+	if path == "" {
+		return fmt.Errorf("json schema: %s", msg)
+	}
+	return fmt.Errorf("json schema: property '%s': %s", path, msg)
+}
+
+func join(path, name string) string {
+	return lo.Ternary(path == "", name, path+"."+name)
+}
@@ -0,0 +1,293 @@
+package view
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kcmvp/xql/decimal"
+	"github.com/kcmvp/xql/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_JSONSchema(t *testing.T) {
+	item := WithFields(
+		Field[int]("qty", validator.Between(1, 99)),
+		Field[float64]("price", validator.Gt(0.0)),
+	)
+	schema := WithFields(
+		Field[string]("name", validator.MinLength(2), validator.MaxLength(20)),
+		Field[string]("code", validator.ExactLength(3), validator.Match("A?C*")),
+		Field[string]("email", validator.Email()).Optional(),
+		Field[string]("homepage", validator.URL()).Optional(),
+		Field[string]("status", validator.OneOf("active", "inactive")),
+		Field[int]("age", validator.Gte(18), validator.Lt(130)),
+		Field[bool]("terms", validator.BeTrue()),
+		Field[time.Time]("since", validator.Gt(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC))).Optional(),
+		Field[string]("username", validator.CharSetOnly(validator.LowerCaseChar)).Optional(),
+		ArrayField[string]("tags", validator.MaxLength(5)).Optional(),
+		ObjectField("address", WithFields(Field[string]("city")).AllowUnknownFields()),
+		ArrayOfObjectField("items", item),
+	)
+	data, err := schema.JSONSchema()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+	  "$schema": "https://json-schema.org/draft/2020-12/schema",
+	  "type": "object",
+	  "additionalProperties": false,
+	  "required": ["name", "code", "status", "age", "terms", "address", "items"],
+	  "properties": {
+	    "name": {"type": "string", "minLength": 2, "maxLength": 20},
+	    "code": {"type": "string", "minLength": 3, "maxLength": 3, "pattern": "^A.C.*$"},
+	    "email": {"type": "string", "format": "email"},
+	    "homepage": {"type": "string", "format": "uri"},
+	    "status": {"type": "string", "enum": ["active", "inactive"]},
+	    "age": {"type": "integer", "minimum": 18, "exclusiveMaximum": 130},
+	    "terms": {"type": "boolean", "const": true},
+	    "since": {"type": "string", "format": "date-time"},
+	    "username": {"type": "string"},
+	    "tags": {"type": "array", "items": {"type": "string", "maxLength": 5}},
+	    "address": {
+	      "type": "object",
+	      "additionalProperties": true,
+	      "required": ["city"],
+	      "properties": {"city": {"type": "string"}}
+	    },
+	    "items": {
+	      "type": "array",
+	      "items": {
+	        "type": "object",
+	        "additionalProperties": false,
+	        "required": ["qty", "price"],
+	        "properties": {
+	          "qty": {"type": "integer", "minimum": 1, "maximum": 99},
+	          "price": {"type": "number", "exclusiveMinimum": 0}
+	        }
+	      }
+	    }
+	  }
+	}`, string(data))
+}
+
+func TestWildcardPattern(t *testing.T) {
+	require.Equal(t, `^a\.b.*c.$`, wildcardPattern("a.b*c?"))
+}
+
+func TestFromJSONSchema(t *testing.T) {
+	schema, err := FromJSONSchema(strings.NewReader(`{
+	  "$schema": "https://json-schema.org/draft/2020-12/schema",
+	  "title": "Order",
+	  "type": "object",
+	  "additionalProperties": false,
+	  "required": ["id", "email", "qty", "items"],
+	  "properties": {
+	    "id": {"type": "string", "pattern": "^ord-\\d+$"},
+	    "email": {"type": "string", "format": "email", "maxLength": 50},
+	    "homepage": {"type": "string", "format": "uri"},
+	    "status": {"type": "string", "enum": ["new", "paid"]},
+	    "qty": {"type": "integer", "minimum": 1, "maximum": 10},
+	    "price": {"type": "number", "exclusiveMinimum": 0},
+	    "terms": {"type": "boolean", "const": true},
+	    "placedAt": {"type": "string", "format": "date-time"},
+	    "tags": {"type": "array", "items": {"type": "string", "minLength": 2}},
+	    "address": {"type": "object", "required": ["city"], "properties": {"city": {"type": "string", "minLength": 1}}},
+	    "items": {"type": "array", "items": {"type": "object", "properties": {"sku": {"type": "string"}}, "required": ["sku"]}}
+	  }
+	}`))
+	require.NoError(t, err)
+
+	rs := schema.Validate(`{"id":"ord-1","email":"a@b.c","qty":2,"price":1.5,"terms":true,"status":"paid",
+	  "placedAt":"2024-01-01T10:00:00Z","tags":["ab"],"address":{"city":"x","zip":"1"},"items":[{"sku":"s"}]}`)
+	require.False(t, rs.IsError(), "unexpected error: %v", rs.Error())
+	vo := rs.MustGet()
+	require.Equal(t, int64(2), vo.MstInt64("qty"))
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), vo.MstTime("placedAt").UTC())
+
+	tests := []struct {
+		name string
+		json string
+		path string
+		code string
+	}{
+		{"pattern", `{"id":"x","email":"a@b.c","qty":1,"items":[]}`, "id", "pattern"},
+		{"format", `{"id":"ord-1","email":"nope","qty":1,"items":[]}`, "email", "email"},
+		{"maximum", `{"id":"ord-1","email":"a@b.c","qty":11,"items":[]}`, "qty", "lte"},
+		{"enum", `{"id":"ord-1","email":"a@b.c","qty":1,"status":"x","items":[]}`, "status", "one_of"},
+		{"const", `{"id":"ord-1","email":"a@b.c","qty":1,"terms":false,"items":[]}`, "terms", "be_true"},
+		{"array_items", `{"id":"ord-1","email":"a@b.c","qty":1,"tags":["a"],"items":[]}`, "tags[0]", "min_length"},
+		{"nested_required", `{"id":"ord-1","email":"a@b.c","qty":1,"address":{},"items":[]}`, "address.city", CodeRequired},
+		{"array_of_objects", `{"id":"ord-1","email":"a@b.c","qty":1,"items":[{}]}`, "items[0].sku", CodeRequired},
+		{"additional_properties", `{"id":"ord-1","email":"a@b.c","qty":1,"items":[],"x":1}`, "x", CodeUnknownField},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := schema.Validate(tc.json)
+			require.True(t, rs.IsError())
+			var verrs ValidationErrors
+			require.True(t, errors.As(rs.Error(), &verrs))
+			require.Equal(t, tc.path, verrs[0].Path)
+			require.Equal(t, tc.code, verrs[0].Code)
+		})
+	}
+}
+
+func TestFromJSONSchema_RoundTrip(t *testing.T) {
+	// properties are imported in name order
+	schema := WithFields(
+		Field[int64]("age", validator.Gte(int64(18))),
+		Field[string]("email", validator.Email()).Optional(),
+		ArrayOfObjectField("items", WithFields(Field[float64]("price", validator.Gt(0.0)))),
+		Field[string]("name", validator.MinLength(2), validator.MaxLength(20)),
+	)
+	exported, err := schema.JSONSchema()
+	require.NoError(t, err)
+	imported, err := FromJSONSchema(bytes.NewReader(exported))
+	require.NoError(t, err)
+	reexported, err := imported.JSONSchema()
+	require.NoError(t, err)
+	require.JSONEq(t, string(exported), string(reexported))
+}
+
+func TestFromYAML(t *testing.T) {
+	schema, err := FromYAML(strings.NewReader(`
+type: object
+required: [name]
+properties:
+  name:
+    type: string
+    minLength: 2
+  age:
+    type: integer
+    minimum: 0
+`))
+	require.NoError(t, err)
+	require.False(t, schema.Validate(`{"name":"ab","age":3,"extra":true}`).IsError())
+	rs := schema.Validate(`{"name":"a","age":-1}`)
+	var verrs ValidationErrors
+	require.True(t, errors.As(rs.Error(), &verrs))
+	require.Equal(t, []string{"age", "name"}, []string{verrs[0].Path, verrs[1].Path})
+
+	_, err = FromYAML(strings.NewReader("type: [object"))
+	require.Error(t, err)
+}
+
+func TestFromJSONSchema_Errors(t *testing.T) {
+	tests := map[string]string{
+		`{"type":"object","properties":{"a":{"$ref":"#/$defs/a"}}}`:                    "property 'a': unsupported keyword '$ref'",
+		`{"type":"object","properties":{"a":{"type":"string","oneOf":[]}}}`:            "property 'a': unsupported keyword 'oneOf'",
+		`{"type":"object","properties":{"a":{"type":["string","integer"]}}}`:           "property 'a': a single type is required",
+		`{"type":"object","properties":{"a":{"minLength":1}}}`:                         "property 'a': a single type is required",
+		`{"type":"object","properties":{"a":{"type":"string","format":"ipv4"}}}`:       "property 'a': unsupported format ipv4",
+		`{"type":"object","properties":{"a":{"type":"string","pattern":"["}}}`:         "property 'a': invalid pattern [",
+		`{"type":"object","properties":{"a":{"type":"integer","minimum":1.5}}}`:        "property 'a': 1.5 is not an integer",
+		`{"type":"object","properties":{"a":{"type":"array"}}}`:                        "property 'a': arrays need an items schema",
+		`{"type":"object","properties":{"a":{"type":"object","properties":{"b":{}}}}}`: "property 'a.b': a single type is required",
+		`{"type":"object","properties":{"a.b":{"type":"string"}}}`:                     "property 'a.b': property names cannot contain '.' or '#'",
+		`{"type":"object","required":["b"],"properties":{"a":{"type":"string"}}}`:      "required property 'b' is not defined",
+		`{"type":"object","additionalProperties":{"type":"string"}}`:                   "only boolean additionalProperties are supported",
+		`{"type":"string"}`: "expected type object but got string",
+		`{"properties":{}}`: "the root must be an object schema",
+		`{"type":"object"`:  "unexpected EOF",
+	}
+	for doc, want := range tests {
+		_, err := FromJSONSchema(strings.NewReader(doc))
+		require.Error(t, err, doc)
+		require.Contains(t, err.Error(), want, doc)
+		require.True(t, strings.HasPrefix(err.Error(), "json schema: "), doc)
+	}
+}
+
+func TestJSONSchema_Nullable(t *testing.T) {
+	schema := WithFields(
+		Field[string]("nick", validator.MinLength(2)).Optional().Nullable(),
+		ArrayField[int]("scores").Nullable(),
+		ObjectField("address", WithFields(Field[string]("city"))).Nullable(),
+	)
+	data, err := schema.JSONSchema()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+	  "$schema": "https://json-schema.org/draft/2020-12/schema",
+	  "type": "object",
+	  "additionalProperties": false,
+	  "required": ["scores", "address"],
+	  "properties": {
+	    "nick": {"type": ["string", "null"], "minLength": 2},
+	    "scores": {"type": ["array", "null"], "items": {"type": "integer"}},
+	    "address": {
+	      "type": ["object", "null"],
+	      "additionalProperties": false,
+	      "required": ["city"],
+	      "properties": {"city": {"type": "string"}}
+	    }
+	  }
+	}`, string(data))
+
+	imported, err := FromJSONSchema(bytes.NewReader(data))
+	require.NoError(t, err)
+	rs := imported.Validate(`{"nick":null,"scores":null,"address":null}`)
+	require.False(t, rs.IsError(), "unexpected error: %v", rs.Error())
+	require.True(t, rs.MustGet().IsNull("address"))
+	require.True(t, imported.Validate(`{"nick":"a","scores":null,"address":null}`).IsError())
+}
+
+func TestJSONSchema_FieldKinds(t *testing.T) {
+	type level int
+	schema := WithFields(
+		Field[uuid.UUID]("id"),
+		Field[decimal.Decimal]("amount", validator.Between(decimal.MustParse("0.01"), decimal.MustParse("999.99"))),
+		Field[time.Duration]("ttl", validator.OneOf(time.Minute, time.Hour)).Default(time.Minute),
+		Field[[]byte]("payload").Optional(),
+		Field[orderStatus]("status", validator.OneOf[orderStatus]("new", "paid")),
+		Field[level]("level").Optional(),
+		MapField[int]("stock", validator.Gte(0)).Optional(),
+	)
+	data, err := schema.JSONSchema()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+	  "$schema": "https://json-schema.org/draft/2020-12/schema",
+	  "type": "object",
+	  "additionalProperties": false,
+	  "required": ["id", "amount", "status"],
+	  "properties": {
+	    "id": {"type": "string", "format": "uuid"},
+	    "amount": {"type": "string", "format": "decimal", "minimum": 0.01, "maximum": 999.99},
+	    "ttl": {"type": "string", "enum": ["1m0s", "1h0m0s"], "default": "1m0s"},
+	    "payload": {"type": "string", "contentEncoding": "base64"},
+	    "status": {"type": "string", "enum": ["new", "paid"]},
+	    "level": {"type": "integer"},
+	    "stock": {"type": "object", "additionalProperties": {"type": "integer", "minimum": 0}}
+	  }
+	}`, string(data))
+
+	imported, err := FromJSONSchema(strings.NewReader(`{
+	  "type": "object",
+	  "properties": {
+	    "id": {"type": "string", "format": "uuid", "default": "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+	    "amount": {"type": "string", "format": "decimal"},
+	    "payload": {"type": "string", "contentEncoding": "base64"},
+	    "labels": {"type": "object", "additionalProperties": {"type": "string", "maxLength": 3}}
+	  }
+	}`))
+	require.NoError(t, err)
+	vo := imported.Validate(`{"amount":"1.10","payload":"aGk=","labels":{"a":"b"}}`).MustGet()
+	require.Equal(t, uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"), vo.MstUUID("id"))
+	require.Equal(t, "1.10", vo.MstDecimal("amount").String())
+	require.Equal(t, []byte("hi"), vo.MstBytes("payload"))
+	require.Equal(t, map[string]string{"a": "b"}, vo.MstStringMap("labels"))
+	var verrs ValidationErrors
+	require.True(t, errors.As(imported.Validate(`{"labels":{"a":"long"}}`).Error(), &verrs))
+	require.Equal(t, "labels.a", verrs[0].Path)
+
+	for doc, want := range map[string]string{
+		`{"type":"object","properties":{"a":{"type":"string","format":"uuid","minLength":1}}}`:            "'minLength' does not apply to uuid strings",
+		`{"type":"object","properties":{"a":{"type":"string","contentEncoding":"base32"}}}`:               "unsupported contentEncoding base32",
+		`{"type":"object","properties":{"a":{"type":"string","format":"uuid","default":"x"}}}`:            "default x is invalid",
+		`{"type":"object","properties":{"a":{"type":"object","additionalProperties":{"type":"object"}}}}`: "unsupported type object",
+	} {
+		_, err := FromJSONSchema(strings.NewReader(doc))
+		require.ErrorContains(t, err, want, doc)
+	}
+}
@@ -0,0 +1,306 @@
+// Package openapi builds an OpenAPI 3.1 document from the routes guarded by
+// vom.Bind. Routes are recorded with Route at registration time, so the
+// document always matches the schemas the middlewares enforce.
+//
+// Usage example (gin; echo and fiber are alike):
+//
+//	router.POST("/orders/:id", vom.Bind(openapi.Route(http.MethodPost, "/orders/:id", orderVO,
+//		openapi.Summary("Update an order"),
+//		openapi.Query("source"))), handler)
+//	// register the document route after all the other routes
+//	router.GET("/openapi.json", gin.WrapH(openapi.Handler()))
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kcmvp/xql/view"
+	"github.com/samber/lo"
+)
+
+// Version is the OpenAPI version of the generated document.
+const Version = "3.1.0"
+
+// ExportEnv names the environment variable read by Handler: when it is set,
+// Handler writes the document to the file it names. `gob openapi export` uses
+// it to capture the document of a program without serving requests.
+const ExportEnv = "XQL_OPENAPI_EXPORT"
+
+// Info is the info object of the document.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Option customizes the operation recorded by Route.
+type Option func(*operation)
+
+// Summary sets the summary of the operation.
+func Summary(summary string) Option {
+	return func(op *operation) { op.summary = summary }
+}
+
+// Description sets the description of the operation.
+func Description(description string) Option {
+	return func(op *operation) { op.description = description }
+}
+
+// OperationID sets the operationId of the operation.
+func OperationID(id string) Option {
+	return func(op *operation) { op.id = id }
+}
+
+// Tags groups the operation under tags.
+func Tags(tags ...string) Option {
+	return func(op *operation) { op.tags = append(op.tags, tags...) }
+}
+
+// Query declares query parameters of the operation. A parameter takes the
+// type, constraints and required flag of the schema field of the same name;
+// undeclared names are optional strings.
+func Query(names ...string) Option {
+	return func(op *operation) { op.query = append(op.query, names...) }
+}
+
+type operation struct {
+	method      string
+	path        string
+	schema      *view.Schema
+	summary     string
+	description string
+	id          string
+	tags        []string
+	query       []string
+}
+
+var (
+	mu         sync.RWMutex
+	info       = Info{Title: "API", Version: "1.0.0"}
+	operations = map[string]*operation{}
+)
+
+// pathParam matches the path parameters of gin, echo and fiber routes:
+// ":id", ":id?" and "*filepath".
+var pathParam = regexp.MustCompile(`[:*](\w*)\??`)
+
+// SetInfo sets the info object of the document.
+func SetInfo(i Info) {
+	mu.Lock()
+	defer mu.Unlock()
+	info = i
+}
+
+// Route records the contract of a route and returns schema unchanged, so it
+// can wrap the argument of vom.Bind. path uses the router syntax (":id",
+// "*filepath"); its parameters become path parameters, and the schema fields
+// they and the Query parameters fill are left out of the request body.
+// It panics if the route is already recorded.
+func Route(method, path string, schema *view.Schema, opts ...Option) *view.Schema {
+	lo.Assertf(schema != nil, "openapi: schema of %s %s is nil", method, path)
+	op := &operation{method: strings.ToLower(method), path: path, schema: schema}
+	for _, opt := range opts {
+		opt(op)
+	}
+	key := op.method + " " + openAPIPath(path)
+	mu.Lock()
+	defer mu.Unlock()
+	_, exists := operations[key]
+	lo.Assertf(!exists, "openapi: route %s %s is already registered", method, path)
+	operations[key] = op
+	return schema
+}
+
+// Document returns the OpenAPI document of the recorded routes.
+func Document() ([]byte, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	paths := map[string]map[string]any{}
+	for _, op := range operations {
+		item, err := op.document()
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(op.method), op.path, err)
+		}
+		p := openAPIPath(op.path)
+		if paths[p] == nil {
+			paths[p] = map[string]any{}
+		}
+		paths[p][op.method] = item
+	}
+	return json.MarshalIndent(map[string]any{
+		"openapi": Version,
+		"info":    info,
+		"paths":   paths,
+		"components": map[string]any{
+			"schemas": map[string]any{
+				"Problem":    problemSchema,
+				"FieldError": fieldErrorSchema,
+			},
+		},
+	}, "", "  ")
+}
+
+// Handler serves the document as JSON. Register it after the other routes:
+// when ExportEnv is set, Handler also writes the document to that file.
+func Handler() http.Handler {
+	if out := os.Getenv(ExportEnv); out != "" {
+		if err := export(out); err != nil {
+			fmt.Fprintf(os.Stderr, "openapi: export %s: %v\n", out, err)
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := Document()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
+
+// export writes the document to out; the rename makes the file appear complete.
+func export(out string) error {
+	data, err := Document()
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(out), "."+filepath.Base(out)+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, out)
+}
+
+// openAPIPath converts router path parameters into OpenAPI templates: "/a/:id" becomes "/a/{id}".
+func openAPIPath(path string) string {
+	return pathParam.ReplaceAllStringFunc(path, func(m string) string {
+		return "{" + paramName(m) + "}"
+	})
+}
+
+func paramName(m string) string {
+	name := strings.TrimRight(m[1:], "?")
+	return lo.Ternary(name == "", "wildcard", name)
+}
+
+func (op *operation) document() (map[string]any, error) {
+	data, err := op.schema.JSONSchema()
+	if err != nil {
+		return nil, err
+	}
+	var body map[string]any
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	delete(body, "$schema")
+	properties, _ := body["properties"].(map[string]any)
+	required := lo.Map(asSlice(body["required"]), func(v any, _ int) string { return v.(string) })
+
+	// take a parameter out of the body schema
+	param := func(name, in string) map[string]any {
+		schema, ok := properties[name]
+		if !ok {
+			schema = map[string]any{"type": "string"}
+		}
+		delete(properties, name)
+		p := map[string]any{
+			"name":     name,
+			"in":       in,
+			"required": in == "path" || slices.Contains(required, name),
+			"schema":   schema,
+		}
+		required = lo.Without(required, name)
+		return p
+	}
+	var params []map[string]any
+	for _, m := range pathParam.FindAllString(op.path, -1) {
+		params = append(params, param(paramName(m), "path"))
+	}
+	for _, name := range op.query {
+		params = append(params, param(name, "query"))
+	}
+
+	item := map[string]any{
+		"responses": map[string]any{
+			"200": map[string]any{"description": "OK"},
+			"400": map[string]any{
+				"description": "The request failed validation.",
+				"content": map[string]any{
+					view.ProblemContentType: map[string]any{
+						"schema": map[string]any{"$ref": "#/components/schemas/Problem"},
+					},
+				},
+			},
+		},
+	}
+	if len(params) > 0 {
+		item["parameters"] = params
+	}
+	if len(properties) > 0 || body["additionalProperties"] == true {
+		sort.Strings(required)
+		if len(required) > 0 {
+			body["required"] = required
+		} else {
+			delete(body, "required")
+		}
+		item["requestBody"] = map[string]any{
+			"required": len(required) > 0,
+			"content": map[string]any{
+				"application/json": map[string]any{"schema": body},
+			},
+		}
+	}
+	for k, v := range map[string]string{"summary": op.summary, "description": op.description, "operationId": op.id} {
+		if v != "" {
+			item[k] = v
+		}
+	}
+	if len(op.tags) > 0 {
+		item["tags"] = op.tags
+	}
+	return item, nil
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+// problemSchema describes view.Problem, the body of 400 responses.
+var problemSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"type", "title", "status"},
+	"properties": map[string]any{
+		"type":   map[string]any{"type": "string"},
+		"title":  map[string]any{"type": "string"},
+		"status": map[string]any{"type": "integer"},
+		"detail": map[string]any{"type": "string"},
+		"errors": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"$ref": "#/components/schemas/FieldError"},
+		},
+	},
+}
+
+// fieldErrorSchema describes view.FieldError.
+var fieldErrorSchema = map[string]any{
+	"type":     "object",
+	"required": []string{"path", "code", "message"},
+	"properties": map[string]any{
+		"path":    map[string]any{"type": "string"},
+		"code":    map[string]any{"type": "string"},
+		"params":  map[string]any{"type": "object"},
+		"value":   map[string]any{},
+		"message": map[string]any{"type": "string"},
+	},
+}
@@ -0,0 +1,135 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/kcmvp/xql/validator"
+	"github.com/kcmvp/xql/view"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+var orderVO = view.WithFields(
+	view.Field[string]("id"),
+	view.Field[string]("source").Optional(),
+	view.Field[int]("limit", validator.Between(1, 100)),
+	view.Field[string]("customer", validator.MinLength(1)),
+	view.Field[float64]("amount", validator.Gt(0.0)).Optional(),
+)
+
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	operations = map[string]*operation{}
+	info = Info{Title: "API", Version: "1.0.0"}
+}
+
+func TestRoute_Document(t *testing.T) {
+	t.Cleanup(reset)
+	require.Same(t, orderVO, Route(http.MethodPut, "/orders/:id", orderVO,
+		Summary("Update an order"), OperationID("updateOrder"), Tags("orders"), Query("source", "limit", "trace")))
+	Route(http.MethodGet, "/files/*filepath", view.WithFields(view.Field[string]("filepath")))
+	SetInfo(Info{Title: "Orders", Version: "2.0.0"})
+
+	data, err := Document()
+	require.NoError(t, err)
+	doc := gjson.ParseBytes(data)
+	require.Equal(t, Version, doc.Get("openapi").String())
+	require.Equal(t, "Orders", doc.Get("info.title").String())
+
+	put := doc.Get(`paths./orders/{id}.put`)
+	require.True(t, put.Exists())
+	require.Equal(t, "Update an order", put.Get("summary").String())
+	require.Equal(t, "updateOrder", put.Get("operationId").String())
+	require.JSONEq(t, `["orders"]`, put.Get("tags").Raw)
+	require.JSONEq(t, `[
+	  {"name":"id","in":"path","required":true,"schema":{"type":"string"}},
+	  {"name":"source","in":"query","required":false,"schema":{"type":"string"}},
+	  {"name":"limit","in":"query","required":true,"schema":{"type":"integer","minimum":1,"maximum":100}},
+	  {"name":"trace","in":"query","required":false,"schema":{"type":"string"}}
+	]`, put.Get("parameters").Raw)
+	require.JSONEq(t, `{
+	  "required": true,
+	  "content": {"application/json": {"schema": {
+	    "type": "object",
+	    "additionalProperties": false,
+	    "required": ["customer"],
+	    "properties": {
+	      "customer": {"type": "string", "minLength": 1},
+	      "amount": {"type": "number", "exclusiveMinimum": 0}
+	    }
+	  }}}
+	}`, put.Get("requestBody").Raw)
+	require.Equal(t, "#/components/schemas/Problem", put.Get(`responses.400.content.application/problem\+json.schema.$ref`).String())
+	require.True(t, doc.Get("components.schemas.FieldError").Exists())
+
+	// all the schema fields are parameters: no request body
+	get := doc.Get(`paths./files/{filepath}.get`)
+	require.True(t, get.Exists())
+	require.False(t, get.Get("requestBody").Exists())
+	require.Equal(t, "path", get.Get("parameters.0.in").String())
+
+	// the recorded schemas are left untouched
+	require.False(t, orderVO.Validate(`{"id":"1","customer":"c","limit":1}`).IsError())
+}
+
+func TestRoute_Duplicate(t *testing.T) {
+	t.Cleanup(reset)
+	Route(http.MethodPost, "/orders/:id", orderVO)
+	require.Panics(t, func() { Route("post", "/orders/:id", orderVO) })
+	require.Panics(t, func() { Route(http.MethodGet, "/orders", nil) })
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	tests := map[string]string{
+		"/orders":            "/orders",
+		"/orders/:id":        "/orders/{id}",
+		"/orders/:id?/items": "/orders/{id}/items",
+		"/static/*filepath":  "/static/{filepath}",
+		"/fiber/*":           "/fiber/{wildcard}",
+	}
+	for in, want := range tests {
+		require.Equal(t, want, openAPIPath(in))
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Cleanup(reset)
+	Route(http.MethodPost, "/orders/:id", orderVO)
+	out := filepath.Join(t.TempDir(), "openapi.json")
+	t.Setenv(ExportEnv, out)
+
+	srv := httptest.NewServer(Handler())
+	t.Cleanup(srv.Close)
+	exported, err := os.ReadFile(out)
+	require.NoError(t, err)
+
+	res, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	require.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	require.True(t, gjson.GetBytes(exported, `paths./orders/{id}.post`).Exists())
+}
+
+func TestRoute_Concurrent(t *testing.T) {
+	t.Cleanup(reset)
+	var wg sync.WaitGroup
+	for _, p := range []string{"/a", "/b", "/c", "/d"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Route(http.MethodPost, p, orderVO)
+			_, _ = Document()
+		}()
+	}
+	wg.Wait()
+	data, err := Document()
+	require.NoError(t, err)
+	require.Len(t, gjson.GetBytes(data, "paths").Map(), 4)
+}
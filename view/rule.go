@@ -0,0 +1,222 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/kcmvp/xql/decimal"
+	"github.com/kcmvp/xql/validator"
+	"github.com/samber/lo"
+)
+
+// RuleFunc is an object-level validation rule registered with Schema.Rule. It
+// sees the whole validated object, so it can relate fields to each other.
+//
+// The error of a rule is attributed to JSON paths as follows:
+//   - a ValidationErrors or a *FieldError is reported at its Path; an empty
+//     Path stands for the rule name;
+//   - any other error is reported at the rule name, with the rule name as Code.
+type RuleFunc func(vo ValueObject) error
+
+type rule struct {
+	name string
+	fn   RuleFunc
+}
+
+// Rule registers an object-level rule. Rules run in registration order after
+// every field passed its own validation, so they only see well-typed values.
+// Rules of nested schemas report their paths under the embedding field, e.g.
+// "items[1].endDate". It panics if a rule with the same name exists.
+//
+// Usage example:
+//
+//	schema := view.WithFields(
+//		view.Field[time.Time]("startDate"),
+//		view.Field[time.Time]("endDate"),
+//		view.Field[string]("email").Optional(),
+//		view.Field[string]("phone").Optional(),
+//	).
+//		Rule("period", view.FieldGt("endDate", "startDate")).
+//		Rule("contact", view.AtLeastOneOf("email", "phone"))
+func (s *Schema) Rule(name string, fn RuleFunc) *Schema {
+	lo.Assertf(fn != nil, "dvo: rule '%s' is nil", name)
+	lo.Assertf(!lo.ContainsBy(s.rules, func(r rule) bool { return r.name == name }), "dvo: duplicate rule '%s' in Schema definition", name)
+	s.rules = append(s.rules, rule{name: name, fn: fn})
+	return s
+}
+
+// checkRules runs the object-level rules and adds their errors to errs. A path
+// keeps the first error reported for it.
+func (s *Schema) checkRules(vo ValueObject, errs *validationError) {
+	for _, r := range s.rules {
+		err := r.fn(vo)
+		if err == nil {
+			continue
+		}
+		var verrs ValidationErrors
+		var fe *FieldError
+		switch {
+		case errors.As(err, &verrs):
+			for _, item := range verrs {
+				addRuleError(errs, r.name, item)
+			}
+		case errors.As(err, &fe):
+			addRuleError(errs, r.name, *fe)
+		default:
+			addRuleError(errs, r.name, FieldError{Code: r.name, Message: err.Error(), err: err})
+		}
+	}
+}
+
+func addRuleError(errs *validationError, name string, fe FieldError) {
+	if fe.Path == "" {
+		fe.Path = name
+	}
+	if fe.Code == "" {
+		fe.Code = name
+	}
+	if _, exists := errs.errors[fe.Path]; !exists {
+		errs.add(fe.Path, &fe)
+	}
+}
+
+// present reports whether the object holds a value at path.
+func present(vo ValueObject, path string) bool {
+	v, ok := vo.Get(path).Get()
+	return ok && v != nil
+}
+
+// RequiredIf requires field when the value of other equals value.
+func RequiredIf(field, other string, value any) RuleFunc {
+	return func(vo ValueObject) error {
+		v, ok := vo.Get(other).Get()
+		if !ok || !reflect.DeepEqual(v, value) || present(vo, field) {
+			return nil
+		}
+		return &FieldError{
+			Path:    field,
+			Code:    "required_if",
+			Params:  map[string]any{"field": other, "value": value},
+			Message: fmt.Sprintf("field '%s' is required when '%s' is %v", field, other, value),
+		}
+	}
+}
+
+// RequiredWith requires field when any of others is present.
+func RequiredWith(field string, others ...string) RuleFunc {
+	return func(vo ValueObject) error {
+		if present(vo, field) || !lo.SomeBy(others, func(o string) bool { return present(vo, o) }) {
+			return nil
+		}
+		return &FieldError{
+			Path:    field,
+			Code:    "required_with",
+			Params:  map[string]any{"fields": others},
+			Message: fmt.Sprintf("field '%s' is required with %s", field, strings.Join(others, ", ")),
+		}
+	}
+}
+
+// MutuallyExclusive allows at most one of fields. Every given field is
+// reported when more than one is present.
+func MutuallyExclusive(fields ...string) RuleFunc {
+	return func(vo ValueObject) error {
+		given := lo.Filter(fields, func(f string, _ int) bool { return present(vo, f) })
+		if len(given) < 2 {
+			return nil
+		}
+		return fieldsError(given, "mutually_exclusive", fields, "only one of %s may be given")
+	}
+}
+
+// AtLeastOneOf requires one of fields at least. Every field is reported when
+// none is present. Combine it with MutuallyExclusive to require exactly one.
+func AtLeastOneOf(fields ...string) RuleFunc {
+	return func(vo ValueObject) error {
+		if lo.SomeBy(fields, func(f string) bool { return present(vo, f) }) {
+			return nil
+		}
+		return fieldsError(fields, "at_least_one_of", fields, "one of %s is required")
+	}
+}
+
+func fieldsError(paths []string, code string, fields []string, format string) ValidationErrors {
+	msg := fmt.Sprintf(format, strings.Join(fields, ", "))
+	return lo.Map(paths, func(p string, _ int) FieldError {
+		return FieldError{Path: p, Code: code, Params: map[string]any{"fields": fields}, Message: msg}
+	})
+}
+
+// FieldGt requires the value of field to be greater than the value of other.
+// Both fields must hold numbers, decimals, durations, strings or times; the
+// rule is skipped when either is absent, and reports a type_mismatch at field
+// when the values can't be compared, e.g. a string with an int.
+func FieldGt(field, other string) RuleFunc {
+	return func(vo ValueObject) error {
+		a, ok1 := vo.Get(field).Get()
+		b, ok2 := vo.Get(other).Get()
+		if !ok1 || !ok2 {
+			return nil
+		}
+		c, ok := compareValues(a, b)
+		if !ok {
+			fe := newFieldError(CodeTypeMismatch, a, fmt.Sprintf("field '%s' (%T) can't be compared with '%s' (%T)", field, a, other, b), validator.ErrTypeMismatch)
+			fe.Path, fe.Params = field, map[string]any{"field": other}
+			return fe
+		}
+		if c > 0 {
+			return nil
+		}
+		return &FieldError{
+			Path:    field,
+			Code:    "field_gt",
+			Params:  map[string]any{"field": other},
+			Value:   a,
+			Message: fmt.Sprintf("field '%s' must be greater than '%s'", field, other),
+		}
+	}
+}
+
+// compareValues compares two numbers, decimals, strings or times.
+func compareValues(a, b any) (int, bool) {
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ta.Compare(tb), ok
+	}
+	if da, ok := a.(decimal.Decimal); ok {
+		db, ok := b.(decimal.Decimal)
+		return da.Cmp(db), ok
+	}
+	if sa, ok := a.(string); ok {
+		sb, ok := b.(string)
+		return strings.Compare(sa, sb), ok
+	}
+	fa, ok1 := toFloat(a)
+	fb, ok2 := toFloat(b)
+	if !ok1 || !ok2 {
+		return 0, false
+	}
+	switch {
+	case fa < fb:
+		return -1, true
+	case fa > fb:
+		return 1, true
+	}
+	return 0, true
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.CanInt():
+		return float64(rv.Int()), true
+	case rv.CanUint():
+		return float64(rv.Uint()), true
+	case rv.CanFloat():
+		return rv.Float(), true
+	}
+	return 0, false
+}
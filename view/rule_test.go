@@ -0,0 +1,160 @@
+package view
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kcmvp/xql/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Rule(t *testing.T) {
+	schema := WithFields(
+		Field[string]("kind"),
+		Field[string]("company").Optional(),
+		Field[string]("password"),
+		Field[string]("confirmPassword").Optional(),
+		Field[string]("email").Optional(),
+		Field[string]("phone").Optional(),
+		Field[time.Time]("startDate").Optional(),
+		Field[time.Time]("endDate").Optional(),
+		Field[int]("min").Optional(),
+		Field[int]("max").Optional(),
+	).
+		Rule("company", RequiredIf("company", "kind", "business")).
+		Rule("confirm", RequiredWith("confirmPassword", "password")).
+		Rule("contact", AtLeastOneOf("email", "phone")).
+		Rule("contact_exclusive", MutuallyExclusive("email", "phone")).
+		Rule("period", FieldGt("endDate", "startDate")).
+		Rule("range", FieldGt("max", "min")).
+		Rule("password_match", func(vo ValueObject) error {
+			if vo.MstString("password") != vo.String("confirmPassword").OrEmpty() {
+				return &FieldError{Path: "confirmPassword", Message: "passwords do not match"}
+			}
+			return nil
+		})
+
+	tests := []struct {
+		name  string
+		json  string
+		codes map[string]string
+	}{
+		{
+			name: "valid",
+			json: `{"kind":"business","company":"acme","password":"x","confirmPassword":"x","email":"a@b.c","startDate":"2024-01-01","endDate":"2024-02-01","min":1,"max":2}`,
+		},
+		{
+			name:  "required_if",
+			json:  `{"kind":"business","password":"x","confirmPassword":"x","phone":"1"}`,
+			codes: map[string]string{"company": "required_if"},
+		},
+		{
+			name:  "required_if_other_value",
+			json:  `{"kind":"private","password":"x","confirmPassword":"x","phone":"1"}`,
+			codes: map[string]string{},
+		},
+		{
+			// password_match reports the same path later and is dropped
+			name:  "required_with",
+			json:  `{"kind":"private","password":"x","phone":"1"}`,
+			codes: map[string]string{"confirmPassword": "required_with"},
+		},
+		{
+			name:  "at_least_one_of",
+			json:  `{"kind":"private","password":"x","confirmPassword":"x"}`,
+			codes: map[string]string{"email": "at_least_one_of", "phone": "at_least_one_of"},
+		},
+		{
+			name:  "mutually_exclusive",
+			json:  `{"kind":"private","password":"x","confirmPassword":"x","email":"a@b.c","phone":"1"}`,
+			codes: map[string]string{"email": "mutually_exclusive", "phone": "mutually_exclusive"},
+		},
+		{
+			name:  "field_gt",
+			json:  `{"kind":"private","password":"x","confirmPassword":"x","phone":"1","startDate":"2024-02-01","endDate":"2024-02-01","min":3,"max":2}`,
+			codes: map[string]string{"endDate": "field_gt", "max": "field_gt"},
+		},
+		{
+			name:  "custom",
+			json:  `{"kind":"private","password":"x","confirmPassword":"y","phone":"1"}`,
+			codes: map[string]string{"confirmPassword": "password_match"},
+		},
+		{
+			// rules do not run while a field is invalid
+			name:  "field_error_first",
+			json:  `{"kind":"business","password":1}`,
+			codes: map[string]string{"password": CodeTypeMismatch},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := schema.Validate(tc.json)
+			if len(tc.codes) == 0 {
+				require.False(t, rs.IsError(), "unexpected error: %v", rs.Error())
+				return
+			}
+			require.True(t, rs.IsError())
+			var verrs ValidationErrors
+			require.True(t, errors.As(rs.Error(), &verrs))
+			codes := map[string]string{}
+			for _, fe := range verrs {
+				codes[fe.Path] = fe.Code
+			}
+			require.Equal(t, tc.codes, codes)
+		})
+	}
+}
+
+func TestSchema_Rule_Attribution(t *testing.T) {
+	item := WithFields(Field[int]("from"), Field[int]("to")).Rule("order", FieldGt("to", "from"))
+	schema := WithFields(
+		ArrayOfObjectField("items", item),
+		Field[string]("note").Optional(),
+	).Rule("note", func(vo ValueObject) error {
+		return errors.New("note is not allowed")
+	})
+
+	rs := schema.Validate(`{"items":[{"from":1,"to":2},{"from":5,"to":4}]}`)
+	var verrs ValidationErrors
+	require.True(t, errors.As(rs.Error(), &verrs))
+	require.Len(t, verrs, 1)
+	require.Equal(t, "items[1].to", verrs[0].Path)
+	require.Equal(t, "field_gt", verrs[0].Code)
+	require.Equal(t, map[string]any{"field": "from"}, verrs[0].Params)
+	require.Equal(t, 4, verrs[0].Value)
+
+	rs = schema.Validate(`{"items":[]}`)
+	require.True(t, errors.As(rs.Error(), &verrs))
+	require.Equal(t, ValidationErrors{{Path: "note", Code: "note", Message: "note is not allowed", err: verrs[0].err}}, verrs)
+}
+
+func TestFieldGt_NotComparable(t *testing.T) {
+	rs := WithFields(Field[string]("a"), Field[int]("b")).Rule("r", FieldGt("a", "b")).Validate(`{"a":"x","b":1}`)
+	var verrs ValidationErrors
+	require.True(t, errors.As(rs.Error(), &verrs))
+	require.Len(t, verrs, 1)
+	require.Equal(t, "a", verrs[0].Path)
+	require.Equal(t, CodeTypeMismatch, verrs[0].Code)
+	require.Equal(t, map[string]any{"field": "b"}, verrs[0].Params)
+	require.ErrorIs(t, rs.Error(), validator.ErrTypeMismatch)
+}
+
+func TestSchema_Rule_Panics(t *testing.T) {
+	require.Panics(t, func() {
+		WithFields(Field[string]("a")).Rule("r", AtLeastOneOf("a")).Rule("r", AtLeastOneOf("a"))
+	})
+	require.Panics(t, func() {
+		WithFields(Field[string]("a")).Rule("r", nil)
+	})
+	// Extend keeps the rules of both schemas and rejects duplicate names
+	base := WithFields(Field[string]("a").Optional()).Rule("r", AtLeastOneOf("a"))
+	extended := base.Extend(WithFields(Field[string]("b").Optional()).Rule("s", AtLeastOneOf("b")))
+	rs := extended.Validate(`{}`)
+	var verrs ValidationErrors
+	require.True(t, errors.As(rs.Error(), &verrs))
+	require.Len(t, verrs, 2)
+	require.Panics(t, func() {
+		base.Extend(WithFields(Field[string]("c")).Rule("r", AtLeastOneOf("c")))
+	})
+}
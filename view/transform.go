@@ -0,0 +1,89 @@
+package view
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/kcmvp/xql/validator"
+	"github.com/samber/lo"
+	"github.com/samber/mo"
+)
+
+// Transformer normalizes a field value before it is validated. Any func(T) T
+// can be used, e.g. strings.TrimSpace.
+type Transformer[T validator.FieldType] func(T) T
+
+// Trim removes the leading and trailing white space of a string.
+func Trim() Transformer[string] {
+	return strings.TrimSpace
+}
+
+// Lower maps a string to lower case.
+func Lower() Transformer[string] {
+	return strings.ToLower
+}
+
+// Upper maps a string to upper case.
+func Upper() Transformer[string] {
+	return strings.ToUpper
+}
+
+// Round rounds a number to n decimal places, half away from zero.
+func Round(n int) Transformer[float64] {
+	scale := math.Pow10(n)
+	return func(f float64) float64 {
+		return math.Round(f*scale) / scale
+	}
+}
+
+// TruncateTime rounds a time down to a multiple of unit, e.g. time.Hour.
+func TruncateTime(unit time.Duration) Transformer[time.Time] {
+	return func(t time.Time) time.Time {
+		return t.Truncate(unit)
+	}
+}
+
+// Transform appends transformers to the field. They run in order on every
+// value of the field, before the validators, and the ValueObject holds the
+// transformed value.
+//
+// Usage example:
+//
+//	view.Field[string]("email", validator.Email()).Transform(view.Trim(), view.Lower())
+func (f *JSONField[T]) Transform(ts ...Transformer[T]) *JSONField[T] {
+	for _, t := range ts {
+		lo.Assertf(t != nil, "dvo: nil transformer for field '%s'", f.name)
+	}
+	f.transformers = append(f.transformers, ts...)
+	return f
+}
+
+// Default makes the field optional and supplies v when the field is absent.
+// An explicit null of a Nullable field is kept as null. It panics if v fails
+// the validators of the field, or if the field is an array, a map or an object.
+func (f *JSONField[T]) Default(v T) *JSONField[T] {
+	lo.Assertf(!f.array && !f.object && !f.mapped, "dvo: field '%s': only single value fields can have a default", f.name)
+	if _, err := f.check(v); err != nil {
+		panic(fmt.Sprintf("dvo: default value of field '%s' is invalid: %v", f.name, err))
+	}
+	f.required = false
+	f.defaultValue = mo.Some(v)
+	return f
+}
+
+// normalize runs the transformers on val.
+func (f *JSONField[T]) normalize(val T) T {
+	for _, t := range f.transformers {
+		val = t(val)
+	}
+	return val
+}
+
+func (f *JSONField[T]) defaulted() mo.Option[any] {
+	if v, ok := f.defaultValue.Get(); ok {
+		return mo.Some[any](v)
+	}
+	return mo.None[any]()
+}
@@ -0,0 +1,123 @@
+package view
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kcmvp/xql/validator"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformers(t *testing.T) {
+	at := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	require.Equal(t, "a b", Trim()("  a b \n"))
+	require.Equal(t, "abc", Lower()("AbC"))
+	require.Equal(t, "ABC", Upper()("AbC"))
+	require.Equal(t, 1.24, Round(2)(1.235))
+	require.Equal(t, -1.2, Round(1)(-1.249))
+	require.Equal(t, 120.0, Round(-1)(123.4))
+	require.Equal(t, time.Date(2024, 5, 6, 7, 0, 0, 0, time.UTC), TruncateTime(time.Hour)(at))
+}
+
+func TestJSONField_Transform(t *testing.T) {
+	schema := WithFields(
+		Field[string]("email", validator.Email()).Transform(Trim(), Lower()),
+		Field[string]("nick", validator.MinLength(3)).Transform(strings.TrimSpace).Optional(),
+		Field[float64]("price").Transform(Round(2)),
+		Field[time.Time]("at").Transform(TruncateTime(time.Minute)).Optional(),
+		ArrayField[string]("tags").Transform(Upper()).Optional(),
+		Field[int]("page").Transform(func(p int) int { return max(p, 1) }).Optional(),
+	)
+
+	t.Run("the value object holds transformed values", func(t *testing.T) {
+		rs := schema.Validate(`{"email":"  Jo@Example.COM ","price":9.999,"at":"2024-05-06T07:08:09Z","tags":["a","b"]}`, map[string]string{"page": "-3"})
+		require.False(t, rs.IsError(), "unexpected error: %v", rs.Error())
+		vo := rs.MustGet()
+		require.Equal(t, "jo@example.com", vo.MstString("email"))
+		require.Equal(t, 10.0, vo.MstFloat64("price"))
+		require.Equal(t, time.Date(2024, 5, 6, 7, 8, 0, 0, time.UTC), vo.MstTime("at").UTC())
+		require.Equal(t, []string{"A", "B"}, vo.MstStringArray("tags"))
+		require.Equal(t, 1, vo.MstInt("page"))
+	})
+
+	t.Run("transformers run before validators", func(t *testing.T) {
+		rs := schema.Validate(`{"email":"jo@example.com","price":1,"nick":"  ab  "}`)
+		var verrs ValidationErrors
+		require.True(t, errors.As(rs.Error(), &verrs))
+		require.Equal(t, "nick", verrs[0].Path)
+		require.Equal(t, "min_length", verrs[0].Code)
+		require.Equal(t, "ab", verrs[0].Value)
+	})
+
+	require.Panics(t, func() { Field[string]("a").Transform(nil) })
+}
+
+func TestJSONField_Default(t *testing.T) {
+	schema := WithFields(
+		Field[int]("page", validator.Gte(1)).Default(1),
+		Field[int]("size", validator.Between(1, 100)).Default(20),
+		Field[string]("sort").Transform(Lower()).Default("id"),
+		Field[string]("nick").Nullable().Default("anonymous"),
+	)
+
+	vo := schema.Validate(`{}`).MustGet()
+	require.Equal(t, 1, vo.MstInt("page"))
+	require.Equal(t, 20, vo.MstInt("size"))
+	require.Equal(t, "id", vo.MstString("sort"))
+	require.Equal(t, "anonymous", vo.MstString("nick"))
+
+	// given values win over defaults, and an explicit null stays null
+	vo = schema.Validate(`{"size":50,"sort":"NAME","nick":null}`, map[string]string{"page": "3"}).MustGet()
+	require.Equal(t, 3, vo.MstInt("page"))
+	require.Equal(t, 50, vo.MstInt("size"))
+	require.Equal(t, "name", vo.MstString("sort"))
+	require.True(t, vo.IsNull("nick"))
+
+	// given values are still validated
+	require.True(t, schema.Validate(`{"size":500}`).IsError())
+
+	require.PanicsWithValue(t, "dvo: default value of field 'size' is invalid: must be between 1 and 100", func() {
+		Field[int]("size", validator.Between(1, 100)).Default(0)
+	})
+	require.Panics(t, func() { ArrayField[int]("ids").Default(1) })
+	require.Panics(t, func() { ObjectField("a", WithFields(Field[string]("b"))).Default("") })
+}
+
+func TestJSONSchema_Default(t *testing.T) {
+	schema := WithFields(
+		Field[int64]("page", validator.Gte(int64(1))).Default(1),
+		Field[string]("sort", validator.OneOf("id", "name")).Default("id"),
+		Field[bool]("desc").Default(false),
+	)
+	data, err := schema.JSONSchema()
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+	  "$schema": "https://json-schema.org/draft/2020-12/schema",
+	  "type": "object",
+	  "additionalProperties": false,
+	  "properties": {
+	    "page": {"type": "integer", "minimum": 1, "default": 1},
+	    "sort": {"type": "string", "enum": ["id", "name"], "default": "id"},
+	    "desc": {"type": "boolean", "default": false}
+	  }
+	}`, string(data))
+
+	imported, err := FromJSONSchema(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	vo := imported.Validate(`{}`).MustGet()
+	require.Equal(t, int64(1), vo.MstInt64("page"))
+	require.Equal(t, "id", vo.MstString("sort"))
+	require.False(t, vo.MstBool("desc"))
+
+	// a default on a required property is only an annotation
+	imported, err = FromJSONSchema(strings.NewReader(`{"type":"object","required":["a"],"properties":{"a":{"type":"integer","default":1}}}`))
+	require.NoError(t, err)
+	require.True(t, imported.Validate(`{}`).IsError())
+
+	_, err = FromJSONSchema(strings.NewReader(`{"type":"object","properties":{"a":{"type":"integer","minimum":2,"default":1}}}`))
+	require.ErrorContains(t, err, "json schema: property 'a': default 1 is invalid")
+	_, err = FromJSONSchema(strings.NewReader(`{"type":"object","properties":{"a":{"type":"string","default":1}}}`))
+	require.ErrorContains(t, err, "json schema: property 'a': default 1 is not a string")
+}
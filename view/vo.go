@@ -4,13 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/kcmvp/xql"
+	"github.com/kcmvp/xql/decimal"
 	"github.com/kcmvp/xql/internal"
 	"github.com/kcmvp/xql/validator"
 	"github.com/samber/lo"
@@ -32,19 +35,47 @@ func (e *validationError) Error() string {
 	if e == nil || len(e.errors) == 0 {
 		return ""
 	}
-	// Sort keys for deterministic error messages, which is good for testing.
+	return e.list().Error()
+}
+
+// As lets errors.As extract the exported ValidationErrors.
+func (e *validationError) As(target any) bool {
+	t, ok := target.(*ValidationErrors)
+	if !ok || e == nil || len(e.errors) == 0 {
+		return false
+	}
+	*t = e.list()
+	return true
+}
+
+// Unwrap exposes the field errors to errors.Is, e.g. validator.ErrRequired.
+func (e *validationError) Unwrap() []error {
+	if e == nil {
+		return nil
+	}
+	return lo.Values(e.errors)
+}
+
+// list converts the collected errors into ValidationErrors. Keys are sorted
+// for deterministic output, which is good for testing.
+func (e *validationError) list() ValidationErrors {
 	keys := make([]string, 0, len(e.errors))
 	for k := range e.errors {
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
-
-	var b strings.Builder
-	b.WriteString("validation failed with the following errors:")
+	out := make(ValidationErrors, 0, len(keys))
 	for _, k := range keys {
-		b.WriteString(fmt.Sprintf("- %s: %s", k, e.errors[k]))
+		var fe *FieldError
+		if errors.As(e.errors[k], &fe) {
+			item := *fe
+			item.Path = k
+			out = append(out, item)
+			continue
+		}
+		out = append(out, FieldError{Path: k, Code: CodeInvalid, Message: e.errors[k].Error(), err: e.errors[k]})
 	}
-	return b.String()
+	return out
 }
 
 // add adds a new error to the map.
@@ -57,6 +88,13 @@ func (e *validationError) add(fieldName string, err error) {
 	}
 }
 
+// merge adds the errors of a nested validation, prefixing their paths.
+func (e *validationError) merge(prefix string, nested *validationError) {
+	for k, err := range nested.errors {
+		e.add(prefix+k, err)
+	}
+}
+
 // err returns the validationError as a single error if it contains any errors.
 func (e *validationError) err() error {
 	if e == nil || len(e.errors) == 0 {
@@ -74,19 +112,29 @@ type ViewField interface {
 	Name() string
 	IsArray() bool
 	IsObject() bool
+	IsMap() bool
 	Required() bool
+	IsNullable() bool
+	defaulted() mo.Option[any]
 	validate(node gjson.Result) mo.Result[any]
-	validateRaw(v string) mo.Result[any]
+	validateRaw(vs []string) mo.Result[any]
 	embeddedObject() mo.Option[*Schema]
+	jsonSchema() map[string]any
 }
 
 type JSONField[T validator.FieldType] struct {
 	name       string
 	required   bool
+	nullable   bool
 	array      bool
+	mapped     bool
 	object     bool
 	embedded   *Schema
 	validators []validator.Validator[T]
+	rules      []validator.Rule // validator descriptions, parallel to validators
+	// transformers normalize values before the validators run
+	transformers []Transformer[T]
+	defaultValue mo.Option[T]
 }
 
 func (f *JSONField[T]) AsSchemaField() ViewField {
@@ -100,6 +148,10 @@ func (f *JSONField[T]) Required() bool {
 	return f.required
 }
 
+func (f *JSONField[T]) IsNullable() bool {
+	return f.nullable
+}
+
 func (f *JSONField[T]) IsArray() bool {
 	return f.array
 }
@@ -108,6 +160,10 @@ func (f *JSONField[T]) IsObject() bool {
 	return f.object
 }
 
+func (f *JSONField[T]) IsMap() bool {
+	return f.mapped
+}
+
 func (f *JSONField[T]) embeddedObject() mo.Option[*Schema] {
 	return lo.Ternary(f.embedded == nil, mo.None[*Schema](), mo.Some(f.embedded))
 }
@@ -124,23 +180,72 @@ func (f *JSONField[T]) Optional() *JSONField[T] {
 	return f
 }
 
-func (f *JSONField[T]) validateRaw(v string) mo.Result[any] {
+// Nullable accepts an explicit JSON null for the field. The null is kept in
+// the ValueObject, so ValueObject.IsNull tells it apart from an absent field;
+// this is what PATCH payloads need to clear a value. Nullable does not make
+// the field optional: combine it with Optional for "absent, null or value".
+func (f *JSONField[T]) Nullable() *JSONField[T] {
+	f.nullable = true
+	return f
+}
+
+// check runs the validators on val and returns the name and the error of the
+// first validator that rejects it.
+func (f *JSONField[T]) check(val T) (string, error) {
+	for i, vfn := range f.validators {
+		if err := vfn(val); err != nil {
+			return f.rules[i].Name, err
+		}
+	}
+	return "", nil
+}
+
+// fieldError wraps err with the field name for context.
+func (f *JSONField[T]) fieldError(code string, value any, err error) error {
+	return newFieldError(code, value, fmt.Sprintf("field '%s': %s", f.Name(), err), err)
+}
+
+// validateRaw checks the string values of a url parameter or a form field. A
+// repeated parameter fills an array of primitives, e.g. ?tag=a&tag=b; the other
+// fields accept a single value.
+func (f *JSONField[T]) validateRaw(vs []string) mo.Result[any] {
+	if f.IsObject() || f.IsMap() {
+		return mo.Err[any](newFieldError(CodeInvalidParameter, vs, fmt.Sprintf("parameter '%s' is mapped to a embedded object", f.Name()), nil))
+	}
+	if f.IsArray() {
+		errs := &validationError{}
+		values := make([]T, 0, len(vs))
+		for i, v := range vs {
+			path := fmt.Sprintf("%s[%d]", f.Name(), i)
+			typedVal := typedString[T](v)
+			if typedVal.IsError() {
+				errs.add(path, newFieldError("", v, typedVal.Error().Error(), typedVal.Error()))
+				continue
+			}
+			val := f.normalize(typedVal.MustGet())
+			if rule, err := f.check(val); err != nil {
+				errs.add(path, newFieldError(rule, val, err.Error(), err))
+				continue
+			}
+			values = append(values, val)
+		}
+		return lo.Ternary(errs.err() != nil, mo.Err[any](errs.err()), mo.Ok[any](values))
+	}
+	if len(vs) != 1 {
+		return mo.Err[any](newFieldError(CodeDuplicateField, vs, fmt.Sprintf("parameter '%s' expects a single value but got %d", f.Name(), len(vs)), nil))
+	}
 	// typedString[T] returns mo.Result[T]
 	// validateRaw needs to return mo.Result[any]
-	typedValResult := typedString[T](v)
+	typedValResult := typedString[T](vs[0])
 	if typedValResult.IsError() {
 		// Wrap the error to provide more context about the field.
-		err := fmt.Errorf("field '%s': %w", f.Name(), typedValResult.Error())
-		return mo.Err[any](err)
+		return mo.Err[any](f.fieldError("", vs[0], typedValResult.Error()))
 	}
 
-	val := typedValResult.MustGet()
+	val := f.normalize(typedValResult.MustGet())
 	// Run validators on the successfully parsed value.
-	for _, vfn := range f.validators {
-		if err := vfn(val); err != nil {
-			err = fmt.Errorf("field '%s': %w", f.Name(), err)
-			return mo.Err[any](err)
-		}
+	if rule, err := f.check(val); err != nil {
+		return mo.Err[any](f.fieldError(rule, val, err))
 	}
 
 	return mo.Ok[any](val)
@@ -154,40 +259,68 @@ func (f *JSONField[T]) validate(node gjson.Result) mo.Result[any] {
 		// Recursively validate. The result will be a mo.Result[ValueObject].
 		nestedResult := f.embeddedObject().MustGet().Validate(node.Raw)
 		if nestedResult.IsError() {
-			// Wrap the error to provide context.
+			// Report the nested errors under their full path, e.g. "user.email".
+			var nested *validationError
+			if errors.As(nestedResult.Error(), &nested) {
+				errs := &validationError{}
+				errs.merge(f.Name()+".", nested)
+				return mo.Err[any](errs)
+			}
 			return mo.Err[any](fmt.Errorf("field '%s' validation failed, %w", f.Name(), nestedResult.Error()))
 		}
 		// Return the embedded ValueObject itself.
 		return mo.Ok[any](nestedResult.MustGet())
 	}
 
+	// Case: Map of primitives
+	if f.IsMap() {
+		if !node.IsObject() {
+			return mo.Err[any](newFieldError(CodeTypeMismatch, node.Value(), fmt.Sprintf("dvo: field '%s' expected a JSON object but got %s", f.Name(), node.Type), validator.ErrTypeMismatch))
+		}
+		errs := &validationError{}
+		values := map[string]T{}
+		node.ForEach(func(key, element gjson.Result) bool {
+			path := f.Name() + "." + key.String()
+			typedVal := typedJson[T](element)
+			if typedVal.IsError() {
+				errs.add(path, newFieldError("", element.Value(), typedVal.Error().Error(), typedVal.Error()))
+				return true // continue to collect all errors
+			}
+			val := f.normalize(typedVal.MustGet())
+			if rule, err := f.check(val); err != nil {
+				errs.add(path, newFieldError(rule, val, err.Error(), err))
+				return true
+			}
+			values[key.String()] = val
+			return true
+		})
+		return lo.Ternary(errs.err() != nil, mo.Err[any](errs.err()), mo.Ok[any](values))
+	}
+
 	// Case: Array
 	if f.IsArray() {
 		if !node.IsArray() {
-			return mo.Err[any](fmt.Errorf("dvo: field '%s' expected a JSON array but got Clause", f.Name()))
+			return mo.Err[any](newFieldError(CodeTypeMismatch, node.Value(), fmt.Sprintf("dvo: field '%s' expected a JSON array but got Clause", f.Name()), validator.ErrTypeMismatch))
 		}
 		errs := &validationError{}
 		// Subcase: Array of Objects
 		if f.embeddedObject().IsPresent() {
 			var values []ValueObject
 			node.ForEach(func(index, element gjson.Result) bool {
+				path := fmt.Sprintf("%s[%d]", f.Name(), index.Int())
 				if !element.IsObject() {
-					errs.add(fmt.Sprintf("%s[%d]", f.Name(), index.Int()), fmt.Errorf("expected a JSON object but got Clause"))
+					errs.add(path, newFieldError(CodeTypeMismatch, element.Value(), "expected a JSON object but got Clause", validator.ErrTypeMismatch))
 					return true // continue
 				}
 				result := f.embedded.Validate(element.Raw)
 				if result.IsError() {
-					// To avoid embedded error messages, if the embedded validation returns a
-					// validationError with a single underlying error, we extract it.
-					// This makes the final error message cleaner.
-					errToAdd := result.Error()
+					// Report the element errors under their full path, e.g. "items[2].price".
 					var nested *validationError
-					if errors.As(errToAdd, &nested) && len(nested.errors) == 1 {
-						for _, v := range nested.errors {
-							errToAdd = v
-						}
+					if errors.As(result.Error(), &nested) {
+						errs.merge(path+".", nested)
+					} else {
+						errs.add(path, result.Error())
 					}
-					errs.add(fmt.Sprintf("%s[%d]", f.Name(), index.Int()), errToAdd)
 				} else if errs.err() == nil {
 					values = append(values, result.MustGet())
 				}
@@ -200,18 +333,17 @@ func (f *JSONField[T]) validate(node gjson.Result) mo.Result[any] {
 		var values []T
 		node.ForEach(func(index, element gjson.Result) bool {
 			// We need to validate each element of the array.
+			path := fmt.Sprintf("%s[%d]", f.Name(), index.Int())
 			typedVal := typedJson[T](element)
 			if typedVal.IsError() {
-				errs.add(fmt.Sprintf("%s[%d]", f.Name(), index.Int()), typedVal.Error())
+				errs.add(path, newFieldError("", element.Value(), typedVal.Error().Error(), typedVal.Error()))
 				return true // continue to collect all errors
 			}
 
-			val := typedVal.MustGet()
+			val := f.normalize(typedVal.MustGet())
 			// Run validators on each element
-			for _, v := range f.validators {
-				if err := v(val); err != nil {
-					errs.add(fmt.Sprintf("%s[%d]", f.Name(), index.Int()), err)
-				}
+			if rule, err := f.check(val); err != nil {
+				errs.add(path, newFieldError(rule, val, err.Error(), err))
 			}
 
 			// Only append if there were no errors for this specific element
@@ -225,15 +357,11 @@ func (f *JSONField[T]) validate(node gjson.Result) mo.Result[any] {
 	// --- Fallback for simple, non-array, non-object fields ---
 	typedVal := typedJson[T](node)
 	if typedVal.IsError() {
-		err := fmt.Errorf("field '%s': %w", f.Name(), typedVal.Error())
-		return mo.Err[any](err)
+		return mo.Err[any](f.fieldError("", node.Value(), typedVal.Error()))
 	}
-	val := typedVal.MustGet()
-	for _, v := range f.validators {
-		if err := v(val); err != nil {
-			err = fmt.Errorf("field '%s': %w", f.Name(), err)
-			return mo.Err[any](err)
-		}
+	val := f.normalize(typedVal.MustGet())
+	if rule, err := f.check(val); err != nil {
+		return mo.Err[any](f.fieldError(rule, val, err))
 	}
 	return mo.Ok[any](val)
 }
@@ -246,10 +374,24 @@ func typedJson[T validator.FieldType](res gjson.Result) mo.Result[T] {
 	var zero T
 	targetType := reflect.TypeOf(zero)
 
+	// Types with a text format are parsed like url parameters. Decimals also
+	// accept JSON numbers, read from the raw text so no digit is lost.
+	switch any(zero).(type) {
+	case decimal.Decimal, time.Duration, uuid.UUID, []byte:
+		if _, isDecimal := any(zero).(decimal.Decimal); isDecimal && res.Type == gjson.Number {
+			return typedString[T](res.Raw)
+		}
+		if res.Type == gjson.String {
+			return typedString[T](res.String())
+		}
+		return mo.Err[T](fmt.Errorf("%w: expected %T but got raw type %s", validator.ErrTypeMismatch, zero, res.Type))
+	}
+
 	switch targetType.Kind() {
 	case reflect.String:
 		if res.Type == gjson.String {
-			return mo.Ok(any(res.String()).(T))
+			// Convert supports string enums, e.g. type Status string.
+			return mo.Ok(reflect.ValueOf(res.String()).Convert(targetType).Interface().(T))
 		}
 	case reflect.Bool:
 		if res.Type == gjson.True || res.Type == gjson.False {
@@ -382,6 +524,17 @@ func ArrayOfObjectField(name string, nested *Schema) *JSONField[string] {
 	return trait[string](name, true, true, nested)
 }
 
+// MapField creates a field for a JSON object with arbitrary keys whose values
+// are all of type T, e.g. {"env": "prod", "team": "core"}. The validators
+// apply to every value, and errors are reported at "name.key". The
+// ValueObject holds a map[string]T.
+// The name of the map field should not contain '#' and `.`.
+func MapField[T validator.FieldType](name string, vfs ...validator.ValidateFunc[T]) *JSONField[T] {
+	f := trait[T](name, false, false, nil, vfs...)
+	f.mapped = true
+	return f
+}
+
 // ArrayField creates a FieldFunc for an array field.
 // It is intended to be used for array fields that contain primitive types.
 // The name of the array field should not contain '#' and `.`.
@@ -404,13 +557,15 @@ func trait[T validator.FieldType](name string, isArray, isObject bool, nested *S
 	}
 	names := make(map[string]struct{})
 	var nf []validator.Validator[T]
+	var rules []validator.Rule
 	for _, v := range vfs {
-		n, f := v()
-		if _, exists := names[n]; exists {
-			panic(fmt.Sprintf("dvo: duplicate validator '%s' for field '%s'", n, name))
+		r, f := v()
+		if _, exists := names[r.Name]; exists {
+			panic(fmt.Sprintf("dvo: duplicate validator '%s' for field '%s'", r.Name, name))
 		}
-		names[n] = struct{}{}
+		names[r.Name] = struct{}{}
 		nf = append(nf, f)
+		rules = append(rules, r)
 	}
 	return &JSONField[T]{
 		name:       name,
@@ -418,6 +573,7 @@ func trait[T validator.FieldType](name string, isArray, isObject bool, nested *S
 		object:     isObject,
 		embedded:   nested,
 		validators: nf,
+		rules:      rules,
 		required:   true,
 	}
 }
@@ -425,6 +581,7 @@ func trait[T validator.FieldType](name string, isArray, isObject bool, nested *S
 // Schema is a blueprint for validating a raw object.
 type Schema struct {
 	fields             []ViewField
+	rules              []rule
 	allowUnknownFields bool
 }
 
@@ -473,12 +630,17 @@ func (s *Schema) Extend(another *Schema) *Schema {
 		names[f.Name()] = struct{}{}
 	}
 
-	// 4. Return a new Schema with the combined fields.
+	// 4. Return a new Schema with the combined fields and rules.
 	// If either of the original objects allowed unknown fields, the new one should too.
-	return &Schema{
+	extended := &Schema{
 		fields:             newFields,
+		rules:              append([]rule(nil), s.rules...),
 		allowUnknownFields: s.allowUnknownFields || another.allowUnknownFields,
 	}
+	for _, r := range another.rules {
+		extended.Rule(r.name, r.fn)
+	}
+	return extended
 }
 
 // ValueObject is a sealed interface for a type-safe map holding validated Schema.
@@ -537,9 +699,85 @@ type ValueObject interface {
 	// MstBoolArray returns a slice of bools for the given name.
 	// It panics if the key is not found or the value is not a []bool.
 	MstBoolArray(name string) []bool
+	// Decimal returns an Option containing the decimal.Decimal for the given name.
+	// It panics if the field exists but is not a decimal.Decimal.
+	Decimal(name string) mo.Option[decimal.Decimal]
+	// MstDecimal returns the decimal.Decimal for the given name.
+	// It panics if the key is not found or the value is not a decimal.Decimal.
+	MstDecimal(name string) decimal.Decimal
+	// UUID returns an Option containing the uuid.UUID for the given name.
+	// It panics if the field exists but is not a uuid.UUID.
+	UUID(name string) mo.Option[uuid.UUID]
+	// MstUUID returns the uuid.UUID for the given name.
+	// It panics if the key is not found or the value is not a uuid.UUID.
+	MstUUID(name string) uuid.UUID
+	// Duration returns an Option containing the time.Duration for the given name.
+	// It panics if the field exists but is not a time.Duration.
+	Duration(name string) mo.Option[time.Duration]
+	// MstDuration returns the time.Duration for the given name.
+	// It panics if the key is not found or the value is not a time.Duration.
+	MstDuration(name string) time.Duration
+	// Bytes returns an Option containing the decoded bytes for the given name.
+	// It panics if the field exists but is not a []byte.
+	Bytes(name string) mo.Option[[]byte]
+	// MstBytes returns the decoded bytes for the given name.
+	// It panics if the key is not found or the value is not a []byte.
+	MstBytes(name string) []byte
+	// StringMap returns an Option containing a map of strings for the given name.
+	// It panics if the field exists but is not a map[string]string.
+	StringMap(name string) mo.Option[map[string]string]
+	// MstStringMap returns a map of strings for the given name.
+	// It panics if the key is not found or the value is not a map[string]string.
+	MstStringMap(name string) map[string]string
+	// IntMap returns an Option containing a map of ints for the given name.
+	// It panics if the field exists but is not a map[string]int.
+	IntMap(name string) mo.Option[map[string]int]
+	// MstIntMap returns a map of ints for the given name.
+	// It panics if the key is not found or the value is not a map[string]int.
+	MstIntMap(name string) map[string]int
+	// Int64Map returns an Option containing a map of int64s for the given name.
+	// It panics if the field exists but is not a map[string]int64.
+	Int64Map(name string) mo.Option[map[string]int64]
+	// MstInt64Map returns a map of int64s for the given name.
+	// It panics if the key is not found or the value is not a map[string]int64.
+	MstInt64Map(name string) map[string]int64
+	// Float64Map returns an Option containing a map of float64s for the given name.
+	// It panics if the field exists but is not a map[string]float64.
+	Float64Map(name string) mo.Option[map[string]float64]
+	// MstFloat64Map returns a map of float64s for the given name.
+	// It panics if the key is not found or the value is not a map[string]float64.
+	MstFloat64Map(name string) map[string]float64
+	// BoolMap returns an Option containing a map of bools for the given name.
+	// It panics if the field exists but is not a map[string]bool.
+	BoolMap(name string) mo.Option[map[string]bool]
+	// MstBoolMap returns a map of bools for the given name.
+	// It panics if the key is not found or the value is not a map[string]bool.
+	MstBoolMap(name string) map[string]bool
 	seal()
 }
 
+// Get returns an Option containing the value of type T for the given name. It
+// serves the types without a dedicated getter, such as enums:
+//
+//	status := view.Get[Status](vo, "status")
+//
+// It panics if the field exists but is not a T.
+func Get[T any](vo ValueObject, name string) mo.Option[T] {
+	opt := vo.Get(name)
+	if opt.IsAbsent() {
+		return mo.None[T]()
+	}
+	v, ok := opt.MustGet().(T)
+	lo.Assertf(ok, "dvo: field '%s' has wrong type: expected %T, got %T", name, *new(T), opt.MustGet())
+	return mo.Some(v)
+}
+
+// MstGet returns the value of type T for the given name.
+// It panics if the key is not found or the value is not a T.
+func MstGet[T any](vo ValueObject, name string) T {
+	return Get[T](vo, name).MustGet()
+}
+
 // valueObject is the private, concrete implementation of the ValueObject interface.
 // It is defined as a plain map so tests can use map literals and indexing directly.
 // We forward method calls to internal.Data converters when necessary.
@@ -587,8 +825,89 @@ func (vo valueObject) BoolArray(name string) mo.Option[[]bool] {
 func (vo valueObject) MstBoolArray(name string) []bool {
 	return vo.BoolArray(name).MustGet()
 }
+func (vo valueObject) Decimal(name string) mo.Option[decimal.Decimal] {
+	return internal.Get[decimal.Decimal](vo.Data, name)
+}
+func (vo valueObject) MstDecimal(name string) decimal.Decimal {
+	return vo.Decimal(name).MustGet()
+}
+func (vo valueObject) UUID(name string) mo.Option[uuid.UUID] {
+	return internal.Get[uuid.UUID](vo.Data, name)
+}
+func (vo valueObject) MstUUID(name string) uuid.UUID {
+	return vo.UUID(name).MustGet()
+}
+func (vo valueObject) Duration(name string) mo.Option[time.Duration] {
+	return internal.Get[time.Duration](vo.Data, name)
+}
+func (vo valueObject) MstDuration(name string) time.Duration {
+	return vo.Duration(name).MustGet()
+}
+func (vo valueObject) Bytes(name string) mo.Option[[]byte] {
+	return internal.Get[[]byte](vo.Data, name)
+}
+func (vo valueObject) MstBytes(name string) []byte {
+	return vo.Bytes(name).MustGet()
+}
+func (vo valueObject) StringMap(name string) mo.Option[map[string]string] {
+	return internal.Get[map[string]string](vo.Data, name)
+}
+func (vo valueObject) MstStringMap(name string) map[string]string {
+	return vo.StringMap(name).MustGet()
+}
+func (vo valueObject) IntMap(name string) mo.Option[map[string]int] {
+	return internal.Get[map[string]int](vo.Data, name)
+}
+func (vo valueObject) MstIntMap(name string) map[string]int {
+	return vo.IntMap(name).MustGet()
+}
+func (vo valueObject) Int64Map(name string) mo.Option[map[string]int64] {
+	return internal.Get[map[string]int64](vo.Data, name)
+}
+func (vo valueObject) MstInt64Map(name string) map[string]int64 {
+	return vo.Int64Map(name).MustGet()
+}
+func (vo valueObject) Float64Map(name string) mo.Option[map[string]float64] {
+	return internal.Get[map[string]float64](vo.Data, name)
+}
+func (vo valueObject) MstFloat64Map(name string) map[string]float64 {
+	return vo.Float64Map(name).MustGet()
+}
+func (vo valueObject) BoolMap(name string) mo.Option[map[string]bool] {
+	return internal.Get[map[string]bool](vo.Data, name)
+}
+func (vo valueObject) MstBoolMap(name string) map[string]bool {
+	return vo.BoolMap(name).MustGet()
+}
 
+// Validate validates a JSON body together with single-valued url parameters,
+// e.g. the path parameters of a route. A parameter given by several maps, or
+// by both a map and the JSON body, is rejected as a duplicate.
 func (s *Schema) Validate(json string, urlParams ...map[string]string) mo.Result[ValueObject] {
+	params := lo.Map(urlParams, func(pair map[string]string, _ int) url.Values {
+		return lo.MapValues(pair, func(v string, _ string) []string { return []string{v} })
+	})
+	return s.validate(json, nil, params)
+}
+
+// ValidateValues is like Validate for multi-valued url parameters such as the
+// query string of a request. A repeated parameter, e.g. ?tag=a&tag=b, fills an
+// array of primitives and is rejected for any other field.
+func (s *Schema) ValidateValues(json string, urlParams ...url.Values) mo.Result[ValueObject] {
+	return s.validate(json, nil, urlParams)
+}
+
+// ValidateForm validates an application/x-www-form-urlencoded or
+// multipart/form-data body, given as its parsed values, together with the url
+// parameters. Form fields are parsed like url parameters, so objects and maps
+// can not be given by a form.
+func (s *Schema) ValidateForm(form url.Values, urlParams ...url.Values) mo.Result[ValueObject] {
+	return s.validate("", lo.Ternary(form == nil, url.Values{}, form), urlParams)
+}
+
+// validate validates a JSON body, or a form body when form is not nil, with
+// the url parameters.
+func (s *Schema) validate(json string, form url.Values, urlParams []url.Values) mo.Result[ValueObject] {
 	if len(json) > 0 && !gjson.Valid(json) {
 		return mo.Err[ValueObject](fmt.Errorf("invalid json %s", json))
 	}
@@ -596,34 +915,45 @@ func (s *Schema) Validate(json string, urlParams ...map[string]string) mo.Result
 	errs := &validationError{}
 	// Check for unknown fields first if not allowed.
 	voFields := lo.SliceToMap(s.fields, func(field ViewField) (string, bool) {
-		return field.Name(), field.IsArray() || field.IsObject()
+		return field.Name(), field.IsObject() || field.IsMap()
 	})
-	urlPair := map[string]string{}
+	urlPair := url.Values{}
 	for _, pair := range urlParams {
 		for k, v := range pair {
 			// self conflict check
 			if _, ok := urlPair[k]; ok {
-				errs.add(k, fmt.Errorf("duplicated url parameter '%s'", k))
+				errs.add(k, newFieldError(CodeDuplicateField, v, fmt.Sprintf("duplicated url parameter '%s'", k), nil))
 			}
-			if !s.allowUnknownFields {
-				if nested, ok := voFields[k]; !ok {
-					errs.add(k, fmt.Errorf("unknown url parameter '%s'", k))
-				} else if nested {
-					errs.add(k, fmt.Errorf("url parameter '%s' is mapped to a embedded object", k))
-				}
+			if nested, ok := voFields[k]; !ok && !s.allowUnknownFields {
+				errs.add(k, newFieldError(CodeUnknownField, v, fmt.Sprintf("unknown url parameter '%s'", k), nil))
+			} else if nested {
+				errs.add(k, newFieldError(CodeInvalidParameter, v, fmt.Sprintf("url parameter '%s' is mapped to a embedded object", k), nil))
 			}
 			urlPair[k] = v
 		}
 	}
 
+	if form != nil {
+		for k, v := range form {
+			if _, ok := urlPair[k]; ok {
+				errs.add(k, newFieldError(CodeDuplicateField, v, fmt.Sprintf("duplicate parameter in url and form '%s'", k), nil))
+			}
+			if nested, ok := voFields[k]; !ok && !s.allowUnknownFields {
+				errs.add(k, newFieldError(CodeUnknownField, v, fmt.Sprintf("unknown form field '%s'", k), nil))
+			} else if nested {
+				errs.add(k, newFieldError(CodeInvalidParameter, v, fmt.Sprintf("form field '%s' is mapped to a embedded object", k), nil))
+			}
+		}
+	}
+
 	lo.ForEach(gjson.Get(json, "@keys").Array(), func(field gjson.Result, index int) {
 		jsonKey := field.String()
 		if _, ok := urlPair[jsonKey]; ok {
-			errs.add(jsonKey, fmt.Errorf("duplicate parameter in url and json '%s'", jsonKey))
+			errs.add(jsonKey, newFieldError(CodeDuplicateField, nil, fmt.Sprintf("duplicate parameter in url and json '%s'", jsonKey), nil))
 		}
 		if !s.allowUnknownFields {
 			if _, ok := voFields[jsonKey]; !ok {
-				errs.add(jsonKey, fmt.Errorf("unknown json field '%s'", jsonKey))
+				errs.add(jsonKey, newFieldError(CodeUnknownField, nil, fmt.Sprintf("unknown json field '%s'", jsonKey), nil))
 			}
 		}
 	})
@@ -637,15 +967,29 @@ func (s *Schema) Validate(json string, urlParams ...map[string]string) mo.Result
 		var rs mo.Result[any]
 		node := gjson.Get(json, field.Name())
 		if !node.Exists() {
-			// need to check in urlPair
-			urlValue, ok := urlPair[field.Name()]
+			// need to check in the form and urlPair
+			raw, ok := form[field.Name()]
 			if !ok {
-				if field.Required() {
-					errs.add(field.Name(), fmt.Errorf("%s %w", field.Name(), validator.ErrRequired))
+				raw, ok = urlPair[field.Name()]
+			}
+			if !ok {
+				if v, ok := field.defaulted().Get(); ok {
+					object[field.Name()] = v
+				} else if field.Required() {
+					err := fmt.Errorf("%s %w", field.Name(), validator.ErrRequired)
+					errs.add(field.Name(), newFieldError(CodeRequired, nil, err.Error(), err))
 				}
 				continue
 			}
-			rs = field.validateRaw(urlValue)
+			rs = field.validateRaw(raw)
+		} else if node.Type == gjson.Null {
+			if !field.IsNullable() {
+				err := fmt.Errorf("%s %w", field.Name(), validator.ErrNull)
+				errs.add(field.Name(), newFieldError(CodeNull, nil, err.Error(), err))
+				continue
+			}
+			object[field.Name()] = nil
+			continue
 		} else {
 			rs = field.validate(node)
 		}
@@ -684,15 +1028,21 @@ func (s *Schema) Validate(json string, urlParams ...map[string]string) mo.Result
 		}
 	}
 
-	// Add unknown URL parameters to the final object if allowed.
+	// Add unknown URL parameters and form fields to the final object if allowed.
+	// A repeated one is kept as a []string.
 	if s.allowUnknownFields {
-		for k, v := range urlPair {
-			if _, exists := object[k]; !exists {
-				object[k] = v
+		for _, values := range []url.Values{urlPair, form} {
+			for k, v := range values {
+				if _, exists := object[k]; !exists {
+					object[k] = lo.Ternary[any](len(v) == 1, lo.FirstOrEmpty(v), v)
+				}
 			}
 		}
 	}
-	return lo.Ternary(errs.err() != nil, mo.Err[ValueObject](errs.err()), mo.Ok[ValueObject](valueObject{
-		Data: object,
-	}))
+	vo := valueObject{Data: object}
+	// Object-level rules only see objects whose fields are all valid.
+	if errs.err() == nil {
+		s.checkRules(vo, errs)
+	}
+	return lo.Ternary(errs.err() != nil, mo.Err[ValueObject](errs.err()), mo.Ok[ValueObject](vo))
 }
@@ -1,15 +1,19 @@
 package view
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/kcmvp/xql/decimal"
 	"github.com/kcmvp/xql/validator"
 	"github.com/samber/mo"
 	"github.com/stretchr/testify/require"
@@ -273,7 +277,7 @@ func TestJSONField_validateRaw(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			rs := tc.field.validateRaw(tc.input)
+			rs := tc.field.validateRaw([]string{tc.input})
 			if tc.wantErr != nil {
 				require.True(t, rs.IsError(), "expected an error but got none")
 				require.ErrorIs(t, rs.Error(), tc.wantErr, "did not get expected error type")
@@ -1550,7 +1554,7 @@ func TestNestedValidation(t *testing.T) {
 			name:        "invalid array of objects",
 			jsonFile:    "nested_invalid_item.json",
 			isValid:     false,
-			errContains: "items[1]: field 'id': must be greater than 0",
+			errContains: "items[1].id: field 'id': must be greater than 0",
 		},
 		{
 			name:        "invalid array type",
@@ -1774,6 +1778,92 @@ func TestSchema_Validate(t *testing.T) {
 	}
 }
 
+func TestSchema_ValidateValues(t *testing.T) {
+	schema := WithFields(
+		Field[int]("id"),
+		ArrayField[string]("tag", validator.MaxLength(3)).Optional(),
+		ArrayField[int]("page").Optional(),
+		Field[string]("q").Optional(),
+		ObjectField("user", WithFields(Field[string]("email"))).Optional(),
+	)
+	path := url.Values{"id": {"7"}}
+
+	vo := schema.ValidateValues("", path, url.Values{"tag": {"a", "b"}, "page": {"1"}}).MustGet()
+	require.Equal(t, 7, vo.MstInt("id"))
+	require.Equal(t, []string{"a", "b"}, vo.MstStringArray("tag"))
+	require.Equal(t, []int{1}, vo.MstIntArray("page"))
+
+	tests := []struct {
+		name  string
+		query url.Values
+		path  string
+		code  string
+	}{
+		{name: "repeated single value", query: url.Values{"q": {"a", "b"}}, path: "q", code: CodeDuplicateField},
+		{name: "path and query conflict", query: url.Values{"id": {"8"}}, path: "id", code: CodeDuplicateField},
+		{name: "invalid element", query: url.Values{"page": {"1", "x"}}, path: "page[1]", code: CodeTypeMismatch},
+		{name: "element validator", query: url.Values{"tag": {"a", "long"}}, path: "tag[1]", code: "max_length"},
+		{name: "embedded object", query: url.Values{"user": {"x"}}, path: "user", code: CodeInvalidParameter},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var verrs ValidationErrors
+			require.True(t, errors.As(schema.ValidateValues("", path, tc.query).Error(), &verrs))
+			require.Equal(t, tc.path, verrs[0].Path)
+			require.Equal(t, tc.code, verrs[0].Code)
+		})
+	}
+
+	// unknown repeated parameters are kept as []string
+	vo = WithFields(Field[string]("name")).AllowUnknownFields().
+		ValidateValues(`{"name":"gopher"}`, url.Values{"x": {"1", "2"}, "y": {"3"}}).MustGet()
+	require.Equal(t, []string{"1", "2"}, vo.MstStringArray("x"))
+	require.Equal(t, "3", vo.MstString("y"))
+}
+
+func TestSchema_ValidateForm(t *testing.T) {
+	schema := WithFields(
+		Field[int]("id"),
+		Field[string]("name", validator.MinLength(2)).Transform(Trim()),
+		ArrayField[string]("tag").Optional(),
+		Field[bool]("active").Default(true),
+		ObjectField("user", WithFields(Field[string]("email"))).Optional(),
+	)
+	form := url.Values{"name": {" gopher "}, "tag": {"a", "b"}}
+	vo := schema.ValidateForm(form, url.Values{"id": {"1"}}).MustGet()
+	require.Equal(t, 1, vo.MstInt("id"))
+	require.Equal(t, "gopher", vo.MstString("name"))
+	require.Equal(t, []string{"a", "b"}, vo.MstStringArray("tag"))
+	require.True(t, vo.MstBool("active"))
+
+	tests := []struct {
+		name   string
+		form   url.Values
+		params url.Values
+		path   string
+		code   string
+		msg    string
+	}{
+		{name: "form and url conflict", form: url.Values{"id": {"1"}, "name": {"go"}}, params: url.Values{"id": {"1"}}, path: "id", code: CodeDuplicateField, msg: "duplicate parameter in url and form 'id'"},
+		{name: "unknown form field", form: url.Values{"name": {"go"}, "x": {"1"}}, params: url.Values{"id": {"1"}}, path: "x", code: CodeUnknownField, msg: "unknown form field 'x'"},
+		{name: "embedded object", form: url.Values{"name": {"go"}, "user": {"x"}}, params: url.Values{"id": {"1"}}, path: "user", code: CodeInvalidParameter, msg: "form field 'user' is mapped to a embedded object"},
+		{name: "missing field", form: url.Values{"id": {"1"}}, path: "name", code: CodeRequired},
+		{name: "empty form", form: nil, params: url.Values{"id": {"1"}}, path: "name", code: CodeRequired},
+		{name: "validator", form: url.Values{"id": {"1"}, "name": {" g "}}, path: "name", code: "min_length"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var verrs ValidationErrors
+			require.True(t, errors.As(schema.ValidateForm(tc.form, tc.params).Error(), &verrs))
+			require.Equal(t, tc.path, verrs[0].Path)
+			require.Equal(t, tc.code, verrs[0].Code)
+			if tc.msg != "" {
+				require.Equal(t, tc.msg, verrs[0].Message)
+			}
+		})
+	}
+}
+
 func TestSchema_Extend(t *testing.T) {
 	baseSchema := WithFields(
 		Field[string]("id"),
@@ -1832,3 +1922,153 @@ func TestSchema_Extend(t *testing.T) {
 		require.False(t, ac.allowUnknownFields)
 	})
 }
+
+func TestSchema_Nullable(t *testing.T) {
+	schema := WithFields(
+		Field[string]("id"),
+		Field[string]("nick", validator.MinLength(2)).Optional().Nullable(),
+		Field[int]("age").Nullable(),
+		ObjectField("address", WithFields(Field[string]("city"))).Optional().Nullable(),
+		ArrayField[string]("tags").Optional().Nullable(),
+		Field[string]("email").Optional(),
+	)
+
+	t.Run("absent null and value", func(t *testing.T) {
+		rs := schema.Validate(`{"id":"1","nick":null,"age":null,"address":null,"tags":null}`)
+		require.False(t, rs.IsError(), "unexpected error: %v", rs.Error())
+		vo := rs.MustGet()
+		for _, name := range []string{"nick", "age", "address", "tags"} {
+			require.True(t, vo.IsNull(name), name)
+			require.True(t, vo.Get(name).IsAbsent(), name)
+		}
+		require.True(t, vo.String("nick").IsAbsent())
+		require.False(t, vo.IsNull("email"))
+		require.False(t, vo.IsNull("address.city"))
+		require.Equal(t, []string{"address", "age", "id", "nick", "tags"}, vo.Fields())
+
+		data, err := json.Marshal(vo)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":"1","nick":null,"age":null,"address":null,"tags":null}`, string(data))
+
+		vo = schema.Validate(`{"id":"1","nick":"jo","age":3,"address":{"city":"x"}}`).MustGet()
+		require.False(t, vo.IsNull("nick"))
+		require.Equal(t, "jo", vo.MstString("nick"))
+		require.False(t, vo.IsNull("tags"))
+	})
+
+	t.Run("nullable does not mean optional", func(t *testing.T) {
+		rs := schema.Validate(`{"id":"1"}`)
+		var verrs ValidationErrors
+		require.True(t, errors.As(rs.Error(), &verrs))
+		require.Equal(t, "age", verrs[0].Path)
+		require.Equal(t, CodeRequired, verrs[0].Code)
+	})
+
+	t.Run("null on a non nullable field", func(t *testing.T) {
+		rs := schema.Validate(`{"id":null,"age":1,"email":null}`)
+		var verrs ValidationErrors
+		require.True(t, errors.As(rs.Error(), &verrs))
+		require.Len(t, verrs, 2)
+		require.Equal(t, []string{"email", "id"}, []string{verrs[0].Path, verrs[1].Path})
+		require.Equal(t, CodeNull, verrs[0].Code)
+		require.ErrorIs(t, rs.Error(), validator.ErrNull)
+	})
+
+	t.Run("nested null", func(t *testing.T) {
+		outer := WithFields(ObjectField("user", WithFields(Field[string]("nick").Nullable())))
+		vo := outer.Validate(`{"user":{"nick":null}}`).MustGet()
+		require.True(t, vo.IsNull("user.nick"))
+		require.True(t, vo.String("user.nick").IsAbsent())
+	})
+}
+
+type orderStatus string
+
+type priority int
+
+func TestSchema_FieldKinds(t *testing.T) {
+	id := "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	schema := WithFields(
+		Field[uuid.UUID]("id"),
+		Field[decimal.Decimal]("amount", validator.Gt(decimal.MustParse("0"))),
+		Field[decimal.Decimal]("fee").Optional(),
+		Field[time.Duration]("ttl", validator.Lte(time.Hour)).Optional(),
+		Field[[]byte]("payload").Optional(),
+		Field[orderStatus]("status", validator.OneOf[orderStatus]("new", "paid")),
+		Field[priority]("priority").Optional(),
+		MapField[string]("labels", validator.MaxLength(5)).Optional(),
+		MapField[int]("stock", validator.Gte(0)).Optional(),
+	)
+
+	t.Run("json", func(t *testing.T) {
+		rs := schema.Validate(`{"id":"` + id + `","amount":12345678901234567.89,"fee":"0.10","ttl":"1m30s",
+		  "payload":"aGVsbG8=","status":"paid","priority":2,"labels":{"env":"prod"},"stock":{"a":1,"b":0}}`)
+		require.False(t, rs.IsError(), "unexpected error: %v", rs.Error())
+		vo := rs.MustGet()
+		require.Equal(t, uuid.MustParse(id), vo.MstUUID("id"))
+		require.Equal(t, "12345678901234567.89", vo.MstDecimal("amount").String())
+		require.Equal(t, "0.10", vo.MstDecimal("fee").String())
+		require.Equal(t, 90*time.Second, vo.MstDuration("ttl"))
+		require.Equal(t, []byte("hello"), vo.MstBytes("payload"))
+		require.Equal(t, orderStatus("paid"), MstGet[orderStatus](vo, "status"))
+		require.Equal(t, priority(2), MstGet[priority](vo, "priority"))
+		require.Equal(t, map[string]string{"env": "prod"}, vo.MstStringMap("labels"))
+		require.Equal(t, map[string]int{"a": 1, "b": 0}, vo.MstIntMap("stock"))
+		require.Equal(t, "prod", vo.MstString("labels.env"))
+		require.True(t, vo.String("labels.team").IsAbsent())
+		require.Panics(t, func() { vo.Int64Map("stock") })
+		require.True(t, Get[orderStatus](vo, "missing").IsAbsent())
+		require.Panics(t, func() { Get[priority](vo, "status") })
+	})
+
+	t.Run("url parameters", func(t *testing.T) {
+		rs := schema.Validate(`{"status":"new"}`, map[string]string{"id": id, "amount": "0.01", "ttl": "30m", "priority": "1"})
+		require.False(t, rs.IsError(), "unexpected error: %v", rs.Error())
+		vo := rs.MustGet()
+		require.Equal(t, uuid.MustParse(id), vo.MstUUID("id"))
+		require.Equal(t, "0.01", vo.MstDecimal("amount").String())
+		require.Equal(t, priority(1), MstGet[priority](vo, "priority"))
+
+		rs = schema.Validate(`{"status":"new","id":"`+id+`","amount":1}`, map[string]string{"labels": "x"})
+		var verrs ValidationErrors
+		require.True(t, errors.As(rs.Error(), &verrs))
+		require.Equal(t, CodeInvalidParameter, verrs[0].Code)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		rs := schema.Validate(`{"id":"x","amount":0,"fee":true,"ttl":"2h","payload":"%%","status":"gone",
+		  "priority":1.5,"labels":{"env":"production","team":1},"stock":{"a":-1}}`)
+		var verrs ValidationErrors
+		require.True(t, errors.As(rs.Error(), &verrs))
+		codes := map[string]string{}
+		for _, fe := range verrs {
+			codes[fe.Path] = fe.Code
+		}
+		require.Equal(t, map[string]string{
+			"id":          CodeTypeMismatch,
+			"amount":      "gt",
+			"fee":         CodeTypeMismatch,
+			"ttl":         "lte",
+			"payload":     CodeTypeMismatch,
+			"status":      "one_of",
+			"priority":    CodeTypeMismatch,
+			"labels.env":  "max_length",
+			"labels.team": CodeTypeMismatch,
+			"stock.a":     "gte",
+		}, codes)
+
+		rs = schema.Validate(`{"id":"` + id + `","amount":1,"status":"new","labels":["a"]}`)
+		require.True(t, errors.As(rs.Error(), &verrs))
+		require.Equal(t, "labels", verrs[0].Path)
+		require.Equal(t, CodeTypeMismatch, verrs[0].Code)
+	})
+
+	t.Run("marshal", func(t *testing.T) {
+		vo := schema.Validate(`{"id":"` + id + `","amount":1.50,"status":"new","payload":"aGk=","labels":{"a":"b"}}`).MustGet()
+		data, err := json.Marshal(vo)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"id":"`+id+`","amount":"1.50","status":"new","payload":"aGk=","labels":{"a":"b"}}`, string(data))
+	})
+
+	require.Panics(t, func() { MapField[int]("m").Default(1) })
+}